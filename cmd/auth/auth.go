@@ -2,6 +2,8 @@ package auth
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"fmt"
 	"log"
 	"net/http"
@@ -11,14 +13,24 @@ import (
 
 	_ "github.com/adesupraptolaia/user_login/docs"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/adesupraptolaia/user_login/config"
 	"github.com/adesupraptolaia/user_login/db"
+	key_controller "github.com/adesupraptolaia/user_login/internal/controller/key"
+	oauth_controller "github.com/adesupraptolaia/user_login/internal/controller/oauth"
+	oidc_controller "github.com/adesupraptolaia/user_login/internal/controller/oidc"
+	role_controller "github.com/adesupraptolaia/user_login/internal/controller/role"
+	service_controller "github.com/adesupraptolaia/user_login/internal/controller/service"
 	user_controller "github.com/adesupraptolaia/user_login/internal/controller/user"
 	"github.com/adesupraptolaia/user_login/internal/entity"
+	auth_middleware "github.com/adesupraptolaia/user_login/internal/middleware/auth"
 	"github.com/adesupraptolaia/user_login/internal/repo"
 	"github.com/adesupraptolaia/user_login/internal/usecase"
-	"github.com/adesupraptolaia/user_login/internal/utils"
+	"github.com/adesupraptolaia/user_login/pkg/hasher"
+	"github.com/adesupraptolaia/user_login/pkg/jwt"
+	"github.com/adesupraptolaia/user_login/pkg/notifier"
+	"github.com/adesupraptolaia/user_login/pkg/realip"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	echoSwagger "github.com/swaggo/echo-swagger"
@@ -46,21 +58,155 @@ func Run() {
 		log.Panicf("error when init database, err: %s", err.Error())
 	}
 
-	seedData(db)
+	passwordHasher, err := hasher.New(hasher.Config{
+		Algorithm: cfg.PasswordHashing.Algorithm,
+		Bcrypt: hasher.BcryptParams{
+			Cost: cfg.PasswordHashing.Bcrypt.Cost,
+		},
+		Argon2id: hasher.Argon2idParams{
+			Memory:      cfg.PasswordHashing.Argon2id.Memory,
+			Iterations:  cfg.PasswordHashing.Argon2id.Iterations,
+			Parallelism: cfg.PasswordHashing.Argon2id.Parallelism,
+			SaltLength:  cfg.PasswordHashing.Argon2id.SaltLength,
+			KeyLength:   cfg.PasswordHashing.Argon2id.KeyLength,
+		},
+	})
+	if err != nil {
+		log.Panicf("error when create password hasher, err: %s", err.Error())
+	}
+
+	seedData(db, passwordHasher, cfg)
 
 	// define repo, usecase, and userHandler
-	repo := repo.NewUser(db)
-	usecase := usecase.NewUser(repo)
-	userHandler := user_controller.NewUserHandler(usecase)
+	userRepo := repo.NewUser(db)
+	refreshTokenRepo := repo.NewRefreshToken(db)
+	sessionRepo := repo.NewSession(db)
+	signingKeyRepo := repo.NewSigningKey(db)
+	loginAttemptRepo := repo.NewLoginAttempt(db)
+	mfaSecretRepo := repo.NewMfaSecret(db)
+	recoveryCodeRepo := repo.NewRecoveryCode(db)
+	roleRepo := repo.NewRole(db)
+	permissionRepo := repo.NewPermission(db)
+	revokedTokenRepo := repo.NewRevokedToken(db)
+	oauthClientRepo := repo.NewOAuthClient(db)
+	authorizationCodeRepo := repo.NewAuthorizationCode(db)
+	userAppRepo := repo.NewUserAppRepo()
+	passwordHistoryRepo := repo.NewPasswordHistory(db)
+	breachedPasswordRepo := repo.NewBreachedPassword(cfg.PasswordPolicy.BreachedPasswordsDir)
+	passwordResetRepo := repo.NewPasswordReset(db)
+	tokenUC := usecase.NewToken(refreshTokenRepo, sessionRepo, userRepo, roleRepo, revokedTokenRepo)
+	userUC := usecase.NewUser(userRepo, passwordHistoryRepo, breachedPasswordRepo, usecase.PasswordPolicy{
+		MinLength:        cfg.PasswordPolicy.MinLength,
+		MaxLength:        cfg.PasswordPolicy.MaxLength,
+		RequireUppercase: cfg.PasswordPolicy.RequireUppercase,
+		RequireLowercase: cfg.PasswordPolicy.RequireLowercase,
+		RequireDigit:     cfg.PasswordPolicy.RequireDigit,
+		RequireSymbol:    cfg.PasswordPolicy.RequireSymbol,
+		DisallowUsername: cfg.PasswordPolicy.DisallowUsername,
+		HistorySize:      cfg.PasswordPolicy.HistorySize,
+	}, passwordHasher, tokenUC, time.Duration(cfg.SoftDelete.GracePeriod)*time.Second)
+	signingKeyUC := usecase.NewSigningKey(signingKeyRepo)
+	loginAttemptUC := usecase.NewLoginAttempt(loginAttemptRepo, usecase.LoginThrottlePolicy{
+		MaxFailedPerUser:   cfg.LoginThrottle.MaxFailedAttemptsPerUser,
+		MaxFailedPerIP:     cfg.LoginThrottle.MaxFailedAttemptsPerIP,
+		Window:             time.Duration(cfg.LoginThrottle.FailedAttemptsWindow) * time.Second,
+		LockoutDuration:    time.Duration(cfg.LoginThrottle.LockoutDuration) * time.Second,
+		MaxLockoutDuration: time.Duration(cfg.LoginThrottle.MaxLockoutDuration) * time.Second,
+	})
+	mfaUC := usecase.NewMfa(mfaSecretRepo, recoveryCodeRepo, cfg.Mfa.Issuer)
+	roleUC := usecase.NewRole(roleRepo, permissionRepo, userRepo)
+	serviceClients := []entity.ServiceClient{}
+	for _, client := range cfg.Service.Clients {
+		clientSecret, err := config.ReadSecret(client.ClientSecret, client.ClientSecretFile)
+		if err != nil {
+			log.Panicf("error when read secret of service client %s, err: %s", client.ClientID, err.Error())
+		}
+		serviceClients = append(serviceClients, entity.ServiceClient{
+			ClientID:     client.ClientID,
+			ClientSecret: clientSecret,
+		})
+	}
+	serviceClientUC := usecase.NewServiceClient(serviceClients, roleRepo)
+	oauthUC := usecase.NewOAuth(oauthClientRepo, authorizationCodeRepo)
+	userInfoUC := usecase.NewUserInfo(userRepo, userAppRepo)
+	userNotifier, err := newNotifier(cfg)
+	if err != nil {
+		log.Panicf("error when create notifier, err: %s", err.Error())
+	}
+	// the response to a reset request must not depend on the delivery
+	passwordResetUC := usecase.NewPasswordReset(passwordResetRepo, userUC, notifier.NewAsync(userNotifier), time.Duration(cfg.PasswordReset.TokenTTL)*time.Second, cfg.PasswordReset.ResetURL)
+	emailVerificationUC := usecase.NewEmailVerification(userRepo, userNotifier, cfg.EmailVerification.VerifyURL, cfg.EmailVerification.RequiredForLogin)
+	invitationUC := usecase.NewInvitation(userRepo, userUC)
+	userHandler := user_controller.NewUserHandler(userUC, tokenUC, loginAttemptUC, mfaUC, passwordResetUC, emailVerificationUC, invitationUC)
+	keyHandler := key_controller.NewKeyHandler(signingKeyUC)
+	roleHandler := role_controller.NewRoleHandler(roleUC)
+	serviceHandler := service_controller.NewServiceHandler(serviceClientUC)
+	oauthHandler := oauth_controller.NewOAuthHandler(oauthUC, userUC, tokenUC, loginAttemptUC, mfaUC, emailVerificationUC)
+	oidcHandler := oidc_controller.NewOIDCHandler(userInfoUC)
+
+	if err := loadSigningKeys(cfg); err != nil {
+		log.Panicf("error when load jwt signing keys, err: %s", err.Error())
+	}
+
+	if err := signingKeyUC.LoadKeys(); err != nil {
+		log.Panicf("error when load signing keys, err: %s", err.Error())
+	}
+
+	// background jobs run until shutdown
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	// pick up keys rotated by other replicas
+	go reloadSigningKeys(jobsCtx, signingKeyUC, 10*time.Second)
+
+	// forget the counters of usernames and ips without recent failure
+	if cfg.LoginThrottle.CleanupInterval > 0 {
+		go purgeStaleLoginAttempts(jobsCtx, loginAttemptUC, time.Duration(cfg.LoginThrottle.CleanupInterval)*time.Second)
+	}
+
+	// purge the users deleted for longer than the grace period, user-app purges them too with
+	// their profile, only this purges the users left deleted when user-app failed to create them
+	if cfg.SoftDelete.PurgeInterval > 0 {
+		go purgeDeletedUsers(jobsCtx, userUC, time.Duration(cfg.SoftDelete.PurgeInterval)*time.Second)
+	}
+
+	// login throttling is per client ip, only trust X-Forwarded-For set by the configured proxies
+	ipExtractor, err := realip.Extractor(cfg.TrustedProxies)
+	if err != nil {
+		log.Panicf("error when init trusted proxies, err: %s", err.Error())
+	}
 
 	// Public Server
 	publicServer := echo.New()
+	publicServer.IPExtractor = ipExtractor
 	publicServer.Use(middleware.Logger())
 	publicServer.Use(middleware.Recover())
 
 	publicServer.GET("/", healthCheck)
+	publicServer.GET("/.well-known/jwks.json", keyHandler.JWKS)
+	publicServer.GET("/.well-known/openid-configuration", oidcHandler.Discovery)
 	publicServer.GET("/refresh", userHandler.RefreshToken)
 	publicServer.POST("/login", userHandler.Login)
+	publicServer.POST("/login/mfa", userHandler.LoginMfa)
+	publicServer.POST("/logout", userHandler.Logout)
+	publicServer.GET("/oauth/authorize", oauthHandler.Authorize)
+	publicServer.POST("/oauth/authorize", oauthHandler.AuthorizeLogin)
+	publicServer.POST("/oauth/token", oauthHandler.Token)
+	publicServer.POST("/password/forgot", userHandler.ForgotPassword)
+	publicServer.POST("/password/reset", userHandler.ResetPassword)
+	publicServer.GET("/email/verify", userHandler.VerifyEmail)
+	publicServer.POST("/email/verify", userHandler.VerifyEmail)
+	publicServer.POST("/email/verify/send", userHandler.SendVerification)
+	publicServer.POST("/invite/accept", userHandler.AcceptInvitation)
+
+	// routes below require an active access token issued for auth app
+	authenticated := auth_middleware.AuthenticateActive(jwt.AUTH_PUBLIC_AUDIENCE, tokenUC)
+	publicServer.POST("/logout/all", userHandler.LogoutAll, authenticated)
+	publicServer.POST("/password", userHandler.ChangePassword, authenticated)
+	publicServer.POST("/mfa/enroll", userHandler.EnrollMfa, authenticated)
+	publicServer.POST("/mfa/confirm", userHandler.ConfirmMfa, authenticated)
+	publicServer.GET("/userinfo", oidcHandler.UserInfo, authenticated)
+	publicServer.POST("/userinfo", oidcHandler.UserInfo, authenticated)
 
 	publicServer.GET("/swagger/*", echoSwagger.WrapHandler)
 
@@ -70,8 +216,40 @@ func Run() {
 	privateServer.Use(middleware.Recover())
 
 	privateServer.GET("/", healthCheck)
-	privateServer.POST("/user/create", userHandler.CreateUser)
-	privateServer.DELETE("/user/:ksuid", userHandler.DeleteUser)
+	privateServer.POST("/service/token", serviceHandler.CreateServiceToken)
+
+	// every route below requires an active access token issued for the private server, and a permission
+	admin := auth_middleware.AuthenticateActive(jwt.AUTH_PRIVATE_AUDIENCE, tokenUC)
+
+	// users are read, created and deleted by user app only, through its service token
+	service := auth_middleware.RequireRole(entity.SERVICE)
+	privateServer.POST("/users/lookup", userHandler.LookupUsers, admin, service, auth_middleware.RequirePermission(entity.PERM_USER_READ))
+	privateServer.POST("/user/create", userHandler.CreateUser, admin, service, auth_middleware.RequirePermission(entity.PERM_USER_CREATE))
+	privateServer.DELETE("/user/:ksuid", userHandler.DeleteUser, admin, service, auth_middleware.RequirePermission(entity.PERM_USER_DELETE))
+	privateServer.POST("/user/:ksuid/restore", userHandler.RestoreUser, admin, service, auth_middleware.RequirePermission(entity.PERM_USER_DELETE))
+	privateServer.DELETE("/user/:ksuid/purge", userHandler.PurgeUser, admin, service, auth_middleware.RequirePermission(entity.PERM_USER_DELETE))
+	privateServer.POST("/invite", userHandler.InviteUser, admin, service, auth_middleware.RequirePermission(entity.PERM_USER_CREATE))
+	privateServer.GET("/invites", userHandler.GetInvitations, admin, service, auth_middleware.RequirePermission(entity.PERM_USER_CREATE))
+	privateServer.DELETE("/invite/:ksuid", userHandler.RevokeInvitation, admin, service, auth_middleware.RequirePermission(entity.PERM_USER_DELETE))
+	privateServer.POST("/user/:ksuid/logout", userHandler.ForceLogout, admin, auth_middleware.RequirePermission(entity.PERM_USER_LOGOUT))
+	privateServer.POST("/user/:ksuid/unlock", userHandler.UnlockUser, admin, auth_middleware.RequirePermission(entity.PERM_USER_UNLOCK))
+	privateServer.POST("/keys/rotate", keyHandler.RotateKey, admin, auth_middleware.RequirePermission(entity.PERM_KEY_ROTATE))
+
+	manageRoles := auth_middleware.RequirePermission(entity.PERM_ROLE_MANAGE)
+	privateServer.PUT("/user/:ksuid/role", roleHandler.AssignRole, admin, manageRoles)
+	privateServer.GET("/roles", roleHandler.GetRoles, admin, manageRoles)
+	privateServer.POST("/roles", roleHandler.CreateRole, admin, manageRoles)
+	privateServer.PUT("/roles/:name/permissions", roleHandler.SetRolePermissions, admin, manageRoles)
+	privateServer.DELETE("/roles/:name", roleHandler.DeleteRole, admin, manageRoles)
+	privateServer.GET("/permissions", roleHandler.GetPermissions, admin, manageRoles)
+	privateServer.POST("/permissions", roleHandler.CreatePermission, admin, manageRoles)
+
+	manageOAuthClients := auth_middleware.RequirePermission(entity.PERM_OAUTH_CLIENT)
+	privateServer.GET("/oauth/clients", oauthHandler.GetClients, admin, manageOAuthClients)
+	privateServer.POST("/oauth/clients", oauthHandler.CreateClient, admin, manageOAuthClients)
+	privateServer.DELETE("/oauth/clients/:client_id", oauthHandler.DeleteClient, admin, manageOAuthClients)
+	privateServer.POST("/oauth/introspect", oauthHandler.Introspect, admin, auth_middleware.RequirePermission(entity.PERM_TOKEN_INTROSPECT))
+	privateServer.POST("/oauth/revoke", oauthHandler.Revoke, admin, auth_middleware.RequirePermission(entity.PERM_TOKEN_REVOKE))
 
 	privateServer.GET("/swagger/*", echoSwagger.WrapHandler)
 
@@ -92,6 +270,7 @@ func Run() {
 
 	<-quit
 	log.Println("Shutting down servers...")
+	stopJobs()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -106,27 +285,195 @@ func Run() {
 	log.Println("Servers shut down successfully.")
 }
 
+func newNotifier(cfg config.Cfg) (notifier.Notifier, error) {
+	switch cfg.Notifier.Type {
+	case "smtp":
+		return notifier.NewSMTP(notifier.SMTPConfig{
+			Host:     cfg.Notifier.SMTP.Host,
+			Port:     cfg.Notifier.SMTP.Port,
+			Username: cfg.Notifier.SMTP.Username,
+			Password: cfg.Notifier.SMTP.Password,
+			From:     cfg.Notifier.SMTP.From,
+		}), nil
+	case "log", "":
+		return notifier.NewLog(cfg.Notifier.LogFile)
+	default:
+		return nil, fmt.Errorf("unsupported notifier type %q", cfg.Notifier.Type)
+	}
+}
+
+// reloadSigningKeys load the rotated keys on every interval until ctx is done
+func reloadSigningKeys(ctx context.Context, signingKeyUC usecase.SigningKeyUC, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := signingKeyUC.LoadKeys(); err != nil {
+				log.Printf("error when reload signing keys, err: %s", err.Error())
+			}
+		}
+	}
+}
+
+// purgeStaleLoginAttempts delete the stale login attempts on every interval until ctx is done
+func purgeStaleLoginAttempts(ctx context.Context, loginAttemptUC usecase.LoginAttemptUC, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := loginAttemptUC.PurgeStaleAttempts()
+			if err != nil {
+				log.Printf("error when purge stale login attempts, err: %s", err.Error())
+			}
+			if purged > 0 {
+				log.Printf("purged %d stale login attempts", purged)
+			}
+		}
+	}
+}
+
+// purgeDeletedUsers purge the users deleted before the grace period on every interval until ctx is done
+func purgeDeletedUsers(ctx context.Context, userUC usecase.UserUC, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := userUC.PurgeDeletedUsers()
+			if err != nil {
+				log.Printf("error when purge deleted users, err: %s", err.Error())
+			}
+			if purged > 0 {
+				log.Printf("purged %d deleted users", purged)
+			}
+		}
+	}
+}
+
+// loadSigningKeys load the access token keys and the refresh token secret, the secret
+// is REFRESH_TOKEN_SECRET env or else the content of secret.refresh_token_file
+func loadSigningKeys(cfg config.Cfg) error {
+	keyFiles := []jwt.KeyFile{}
+	for _, key := range cfg.Jwt.SigningKeys {
+		keyFiles = append(keyFiles, jwt.KeyFile{Algorithm: key.Algorithm, PrivateKeyFile: key.PrivateKeyFile})
+	}
+
+	refreshTokenSecret, err := config.ReadSecret(cfg.Secret.RefreshToken, cfg.Secret.RefreshTokenFile)
+	if err != nil {
+		return fmt.Errorf("failed to read refresh token secret: %w", err)
+	}
+
+	return jwt.LoadSigningKeys(keyFiles, []byte(refreshTokenSecret))
+}
+
 func healthCheck(c echo.Context) error {
 	return c.String(http.StatusOK, "Healthy")
 }
 
-func seedData(db *gorm.DB) {
+func seedData(db *gorm.DB, passwordHasher hasher.Hasher, cfg config.Cfg) {
+	seedRoles(db)
+
 	adminKsuid := "2OokWa2yDw7yi7o9RpsAl58xuoW"
 	userKsuid := "2OokWdyzR17GBzVsF6auODTuSxz"
 
 	// create admin
-	db.Table("users").Create(entity.User{
+	seedUser(db, passwordHasher, entity.User{
 		Ksuid:    adminKsuid,
 		Username: "admin",
-		Password: utils.HashPassword("admin"),
 		Role:     entity.ADMIN,
-	})
+	}, cfg.Seed.AdminPassword)
 
 	// create user
-	db.Table("users").Create(entity.User{
+	seedUser(db, passwordHasher, entity.User{
 		Ksuid:    userKsuid,
 		Username: "user",
-		Password: utils.HashPassword("user"),
 		Role:     entity.USER,
-	})
+	}, cfg.Seed.UserPassword)
+}
+
+// seedUser create the user on the first start only, with password or else a random one
+// that is logged once, a later start never resets the password
+func seedUser(db *gorm.DB, passwordHasher hasher.Hasher, user entity.User, password string) {
+	var count int64
+	if err := db.Table("users").Where("ksuid = ?", user.Ksuid).Count(&count).Error; err != nil {
+		log.Panicf("error when check seed user %s, err: %s", user.Username, err.Error())
+	}
+	if count > 0 {
+		return
+	}
+
+	generated := password == ""
+	if generated {
+		random := make([]byte, 18)
+		if _, err := rand.Read(random); err != nil {
+			log.Panicf("error when generate seed password, err: %s", err.Error())
+		}
+		password = base64.RawURLEncoding.EncodeToString(random)
+	}
+
+	hash, err := passwordHasher.Hash(password)
+	if err != nil {
+		log.Panicf("error when hash seed password, err: %s", err.Error())
+	}
+	user.Password = hash
+
+	if err := db.Table("users").Create(&user).Error; err != nil {
+		log.Panicf("error when seed user %s, err: %s", user.Username, err.Error())
+	}
+
+	if generated {
+		log.Printf("seeded user %s with password %s, it is not shown again", user.Username, password)
+	}
+}
+
+// seedRoles create the built-in permissions and roles, and grant each built-in
+// permission to its built-in role once, so a permission added in a later release
+// reaches the roles of an existing database too, while a permission removed
+// through PUT /roles/:name/permissions stays removed
+func seedRoles(db *gorm.DB) {
+	permissions := []string{}
+	for name, description := range entity.PERMISSIONS {
+		db.Table("permissions").Clauses(clause.OnConflict{DoNothing: true}).Create(&entity.Permission{Name: name, Description: description})
+		permissions = append(permissions, name)
+	}
+
+	seedRole(db, entity.ADMIN, permissions)
+	seedRole(db, entity.USER, entity.USER_PERMISSIONS)
+	seedRole(db, entity.SERVICE, entity.SERVICE_PERMISSIONS)
+}
+
+// seedRole insert the role, and grant the permissions not recorded in seeded_role_permissions yet
+func seedRole(db *gorm.DB, role string, permissions []string) {
+	if err := db.Table("roles").Clauses(clause.OnConflict{DoNothing: true}).Create(&entity.Role{Name: role}).Error; err != nil {
+		log.Panicf("error when seed role %s, err: %s", role, err.Error())
+	}
+
+	for _, permission := range permissions {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			result := tx.Table("seeded_role_permissions").
+				Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&entity.RolePermission{Role: role, Permission: permission})
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+
+			return tx.Table("role_permissions").
+				Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&entity.RolePermission{Role: role, Permission: permission}).Error
+		})
+		if err != nil {
+			log.Panicf("error when grant permission %s to role %s, err: %s", permission, role, err.Error())
+		}
+	}
 }
@@ -14,11 +14,15 @@ import (
 	_ "github.com/adesupraptolaia/user_login/docs"
 	user_profile_controller "github.com/adesupraptolaia/user_login/internal/controller/user_profile"
 	"github.com/adesupraptolaia/user_login/internal/entity"
+	auth_middleware "github.com/adesupraptolaia/user_login/internal/middleware/auth"
 	"github.com/adesupraptolaia/user_login/internal/repo"
 	"github.com/adesupraptolaia/user_login/internal/usecase"
+	"github.com/adesupraptolaia/user_login/pkg/jwt"
+	"github.com/adesupraptolaia/user_login/pkg/realip"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	echoSwagger "github.com/swaggo/echo-swagger"
+	"golang.org/x/time/rate"
 	"gorm.io/gorm"
 )
 
@@ -45,25 +49,65 @@ func Run() {
 	}
 	seedData(db)
 
+	// verify access tokens only with the public keys published by auth app
+	jwt.UseJWKS(fmt.Sprintf("http://%s/.well-known/jwks.json", cfg.AuthServicePublicUrl))
+
 	userProfileRepo := repo.NewUserProfile(db)
-	authRepo := repo.NewAuthRepo()
-	usecase := usecase.NewUserProfile(userProfileRepo, authRepo)
+	clientSecret, err := config.ReadSecret(cfg.Service.ClientSecret, cfg.Service.ClientSecretFile)
+	if err != nil {
+		log.Panicf("error when read service client secret, err: %s", err.Error())
+	}
+	authRepo := repo.NewAuthRepo(cfg.Service.ClientID, clientSecret)
+	userProfileUC := usecase.NewUserProfile(userProfileRepo, authRepo, cfg.Profile.SelfEditableFields,
+		time.Duration(cfg.SoftDelete.GracePeriod)*time.Second)
+	registrationUC := usecase.NewRegistration(userProfileUC, usecase.RegistrationPolicy{
+		Enabled:             cfg.Registration.Enabled,
+		InviteCodes:         cfg.Registration.InviteCodes,
+		AllowedEmailDomains: cfg.Registration.AllowedEmailDomains,
+	})
+
+	publicHandler := user_profile_controller.NewUserProfileHandler(userProfileUC, registrationUC)
 
-	publicHandler := user_profile_controller.NewUserProfileHandler(usecase)
+	// registration is rate limited per client ip, only trust X-Forwarded-For set by the configured proxies
+	ipExtractor, err := realip.Extractor(cfg.TrustedProxies)
+	if err != nil {
+		log.Panicf("error when init trusted proxies, err: %s", err.Error())
+	}
 
 	c := echo.New()
+	c.IPExtractor = ipExtractor
 
 	c.Use(middleware.Logger())
 	c.Use(middleware.Recover())
 
 	c.GET("/", healthCheck)
-	c.GET("/user/:user_ksuid", publicHandler.GetUser)
-	c.POST("/user/create", publicHandler.CreateUser)
-	c.POST("/user/:user_ksuid/update", publicHandler.UpdateUser)
-	c.DELETE("/user/:user_ksuid", publicHandler.DeleteUser)
+
+	c.POST("/register", publicHandler.Register, registrationRateLimiter(cfg.Registration.MaxPerIPPerHour))
+
+	authenticated := auth_middleware.Authenticate(jwt.USER_APP_AUDIENCE)
+	c.GET("/user/:user_ksuid", publicHandler.GetUser, authenticated,
+		auth_middleware.RequireSelfOrPermission("user_ksuid", entity.PERM_PROFILE_READ_SELF, entity.PERM_PROFILE_READ_ANY))
+	c.GET("/users", publicHandler.GetUsers, authenticated, auth_middleware.RequirePermission(entity.PERM_PROFILE_READ_ANY))
+	c.POST("/user/create", publicHandler.CreateUser, authenticated, auth_middleware.RequirePermission(entity.PERM_PROFILE_CREATE))
+	c.POST("/user/:user_ksuid/update", publicHandler.UpdateUser, authenticated,
+		auth_middleware.RequireSelfOrPermission("user_ksuid", entity.PERM_PROFILE_UPDATE_SELF, entity.PERM_PROFILE_UPDATE))
+	c.PATCH("/user/:user_ksuid", publicHandler.PatchUser, authenticated,
+		auth_middleware.RequireSelfOrPermission("user_ksuid", entity.PERM_PROFILE_UPDATE_SELF, entity.PERM_PROFILE_UPDATE))
+	c.DELETE("/user/:user_ksuid", publicHandler.DeleteUser, authenticated, auth_middleware.RequirePermission(entity.PERM_PROFILE_DELETE))
+	c.POST("/user/:user_ksuid/restore", publicHandler.RestoreUser, authenticated, auth_middleware.RequirePermission(entity.PERM_PROFILE_RESTORE))
+	c.POST("/invite", publicHandler.InviteUser, authenticated, auth_middleware.RequirePermission(entity.PERM_PROFILE_CREATE))
+	c.GET("/invites", publicHandler.GetInvitations, authenticated, auth_middleware.RequirePermission(entity.PERM_PROFILE_CREATE))
+	c.DELETE("/invite/:user_ksuid", publicHandler.RevokeInvitation, authenticated, auth_middleware.RequirePermission(entity.PERM_PROFILE_DELETE))
 
 	c.GET("/swagger/*", echoSwagger.WrapHandler)
 
+	// purge users deleted for longer than the grace period, from auth app then user app, until shutdown
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	defer stopPurge()
+	if cfg.SoftDelete.PurgeInterval > 0 {
+		go purgeDeletedUsers(purgeCtx, userProfileUC, time.Duration(cfg.SoftDelete.PurgeInterval)*time.Second)
+	}
+
 	go func() {
 		if err := c.Start(fmt.Sprintf(":%d", cfg.UserServer.Port)); err != nil {
 			log.Fatalf("Failed to start server, err: %s", err.Error())
@@ -75,6 +119,7 @@ func Run() {
 
 	<-quit
 	log.Println("Shutting down servers...")
+	stopPurge()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -90,6 +135,49 @@ func healthCheck(c echo.Context) error {
 	return c.String(http.StatusOK, "Healthy")
 }
 
+// purgeDeletedUsers purge the deleted users on every interval until ctx is done
+func purgeDeletedUsers(ctx context.Context, userProfileUC usecase.UserProfileUC, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := userProfileUC.PurgeDeletedUserProfiles()
+			if err != nil {
+				log.Printf("error when purge deleted users, err: %s", err.Error())
+			}
+			if purged > 0 {
+				log.Printf("purged %d deleted users", purged)
+			}
+		}
+	}
+}
+
+// registrationRateLimiter allow maxPerHour requests per client ip, refilled evenly over the hour,
+// zero disables the limit
+func registrationRateLimiter(maxPerHour int) echo.MiddlewareFunc {
+	if maxPerHour <= 0 {
+		return func(next echo.HandlerFunc) echo.HandlerFunc { return next }
+	}
+
+	return middleware.RateLimiterWithConfig(middleware.RateLimiterConfig{
+		Store: middleware.NewRateLimiterMemoryStoreWithConfig(middleware.RateLimiterMemoryStoreConfig{
+			Rate:      rate.Limit(float64(maxPerHour) / time.Hour.Seconds()),
+			Burst:     maxPerHour,
+			ExpiresIn: time.Hour,
+		}),
+		ErrorHandler: func(ctx echo.Context, err error) error {
+			return ctx.JSON(http.StatusForbidden, user_profile_controller.ErrorResponse(err.Error()))
+		},
+		DenyHandler: func(ctx echo.Context, identifier string, err error) error {
+			return ctx.JSON(http.StatusTooManyRequests, user_profile_controller.ErrorResponse("too many registrations, try again later"))
+		},
+	})
+}
+
 func seedData(db *gorm.DB) {
 	adminKsuid := "2OokWa2yDw7yi7o9RpsAl58xuoW"
 	userKsuid := "2OokWdyzR17GBzVsF6auODTuSxz"
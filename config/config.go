@@ -1,8 +1,11 @@
 package config
 
 import (
+	"bytes"
+	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"gopkg.in/yaml.v2"
 )
@@ -24,11 +27,144 @@ type Cfg struct {
 		Password string `yaml:"password"`
 		DBName   string `yaml:"dbname"`
 	} `yaml:"database"`
+	// HMAC secret of refresh tokens, read by auth app only, from REFRESH_TOKEN_SECRET env
+	// or else from the file, e.g. mounted from a secret
 	Secret struct {
-		AccessToken  string `yaml:"access_token"`
-		RefreshToken string `yaml:"refresh_token"`
+		RefreshToken     string `yaml:"-"`
+		RefreshTokenFile string `yaml:"refresh_token_file"`
 	} `yaml:"secret"`
+	Jwt struct {
+		// the first key signs new access tokens, every key is published in the JWKS.
+		// Only auth app loads them, the files are kept out of the repository
+		SigningKeys []struct {
+			Algorithm      string `yaml:"algorithm"`
+			PrivateKeyFile string `yaml:"private_key_file"`
+		} `yaml:"signing_keys"`
+		JwksCacheTTL int    `yaml:"jwks_cache_ttl"` // in seconds
+		Issuer       string `yaml:"issuer"`
+		// a service only accepts tokens issued for its own audience
+		Audience struct {
+			AuthPublic  string `yaml:"auth_public"`
+			AuthPrivate string `yaml:"auth_private"`
+			UserApp     string `yaml:"user_app"`
+		} `yaml:"audience"`
+	} `yaml:"jwt"`
+	LoginThrottle struct {
+		MaxFailedAttemptsPerUser int `yaml:"max_failed_attempts_per_user"`
+		MaxFailedAttemptsPerIP   int `yaml:"max_failed_attempts_per_ip"`
+		FailedAttemptsWindow     int `yaml:"failed_attempts_window"` // in seconds
+		// doubled on every consecutive lockout, in seconds
+		LockoutDuration    int `yaml:"lockout_duration"`
+		MaxLockoutDuration int `yaml:"max_lockout_duration"`
+		// in seconds, zero disables the cleanup of stale counters
+		CleanupInterval int `yaml:"cleanup_interval"`
+	} `yaml:"login_throttle"`
+	Mfa struct {
+		// shown in the authenticator app next to the username
+		Issuer string `yaml:"issuer"`
+	} `yaml:"mfa"`
+	// new passwords are hashed with algorithm, the hashes of the other one are
+	// still verified and rehashed on login, a zero parameter takes its default
+	PasswordHashing struct {
+		Algorithm string `yaml:"algorithm"`
+		Bcrypt    struct {
+			Cost int `yaml:"cost"`
+		} `yaml:"bcrypt"`
+		Argon2id struct {
+			Memory      uint32 `yaml:"memory"` // in KiB
+			Iterations  uint32 `yaml:"iterations"`
+			Parallelism uint8  `yaml:"parallelism"`
+			SaltLength  uint32 `yaml:"salt_length"` // in bytes
+			KeyLength   uint32 `yaml:"key_length"`  // in bytes
+		} `yaml:"argon2id"`
+	} `yaml:"password_hashing"`
+	// checked on every new password, a zero value disables the rule
+	PasswordPolicy struct {
+		MinLength        int  `yaml:"min_length"`
+		MaxLength        int  `yaml:"max_length"` // in bytes
+		RequireUppercase bool `yaml:"require_uppercase"`
+		RequireLowercase bool `yaml:"require_lowercase"`
+		RequireDigit     bool `yaml:"require_digit"`
+		RequireSymbol    bool `yaml:"require_symbol"`
+		DisallowUsername bool `yaml:"disallow_username"`
+		// latest passwords, the current one included, that can not be reused
+		HistorySize int `yaml:"history_size"`
+		// k-anonymity range files of breached passwords named by the first 5 hex of the SHA-1,
+		// e.g. 5BAA6.txt, empty disables the check
+		BreachedPasswordsDir string `yaml:"breached_passwords_dir"`
+	} `yaml:"password_policy"`
+	PasswordReset struct {
+		TokenTTL int `yaml:"token_ttl"` // in seconds
+		// page of the client app to set the new password, the token is added as query parameter
+		ResetURL string `yaml:"reset_url"`
+	} `yaml:"password_reset"`
+	EmailVerification struct {
+		// users without a verified email address can't login
+		RequiredForLogin bool `yaml:"required_for_login"`
+		// page of the client app verifying the email, the token is added as query parameter
+		VerifyURL string `yaml:"verify_url"`
+	} `yaml:"email_verification"`
+	// delivery of password resets and email verifications to the users
+	Notifier struct {
+		// log or smtp
+		Type string `yaml:"type"`
+		// the log notifier prints to stdout when empty
+		LogFile string `yaml:"log_file"`
+		// without username the mail is sent without authentication, e.g. to a local fake SMTP server
+		SMTP struct {
+			Host     string `yaml:"host"`
+			Port     int    `yaml:"port"`
+			Username string `yaml:"username"`
+			Password string `yaml:"password"`
+			From     string `yaml:"from"`
+		} `yaml:"smtp"`
+	} `yaml:"notifier"`
+	// self registration on user app, without an admin token
+	Registration struct {
+		Enabled bool `yaml:"enabled"`
+		// when not empty, only a user with one of the codes can register
+		InviteCodes []string `yaml:"invite_codes"`
+		// when not empty, only a user with an email address of one of the domains can register
+		AllowedEmailDomains []string `yaml:"allowed_email_domains"`
+		// registrations allowed per client ip, zero disables the limit
+		MaxPerIPPerHour int `yaml:"max_per_ip_per_hour"`
+	} `yaml:"registration"`
+	Profile struct {
+		// fields of the profile a user with permission profile:update:self can change, by role,
+		// a role not listed can't change any
+		SelfEditableFields map[string][]string `yaml:"self_editable_fields"`
+	} `yaml:"profile"`
+	// deleted users are kept during the grace period to be restored, then purged from both services
+	SoftDelete struct {
+		GracePeriod   int `yaml:"grace_period"`   // in seconds
+		PurgeInterval int `yaml:"purge_interval"` // in seconds, zero disables the purge
+	} `yaml:"soft_delete"`
+	// passwords of the seeded admin and user, only set by env, a random one is logged when empty
+	Seed struct {
+		AdminPassword string `yaml:"-"`
+		UserPassword  string `yaml:"-"`
+	} `yaml:"-"`
+	Service struct {
+		// clients allowed to get a service token from the auth private server
+		// the secret of a client is SERVICE_CLIENT_SECRET_<CLIENT_ID> env, e.g. SERVICE_CLIENT_SECRET_USER_APP,
+		// or else the content of client_secret_file
+		Clients []struct {
+			ClientID         string `yaml:"client_id"`
+			ClientSecret     string `yaml:"-"`
+			ClientSecretFile string `yaml:"client_secret_file"`
+		} `yaml:"clients"`
+		// credentials of user app as a service client, the secret is SERVICE_CLIENT_SECRET env
+		// or else the content of client_secret_file
+		ClientID         string `yaml:"client_id"`
+		ClientSecret     string `yaml:"-"`
+		ClientSecretFile string `yaml:"client_secret_file"`
+	} `yaml:"service"`
+	AuthServicePublicUrl  string `yaml:"auth_service_public_url"`
 	AuthServicePrivateUrl string `yaml:"auth_service_private_url"`
+	UserServiceUrl        string `yaml:"user_service_url"`
+
+	// proxies, in CIDR notation, whose X-Forwarded-For tells the client ip, empty ignores the header
+	TrustedProxies []string `yaml:"trusted_proxies"`
 }
 
 var Config Cfg
@@ -47,4 +183,77 @@ func init() {
 	if os.Getenv("DB_HOST") != "" {
 		Config.Database.Host = os.Getenv("DB_HOST")
 	}
+
+	if os.Getenv("REFRESH_TOKEN_SECRET") != "" {
+		Config.Secret.RefreshToken = os.Getenv("REFRESH_TOKEN_SECRET")
+	}
+
+	if os.Getenv("SEED_ADMIN_PASSWORD") != "" {
+		Config.Seed.AdminPassword = os.Getenv("SEED_ADMIN_PASSWORD")
+	}
+
+	if os.Getenv("SEED_USER_PASSWORD") != "" {
+		Config.Seed.UserPassword = os.Getenv("SEED_USER_PASSWORD")
+	}
+
+	if os.Getenv("SERVICE_CLIENT_SECRET") != "" {
+		Config.Service.ClientSecret = os.Getenv("SERVICE_CLIENT_SECRET")
+	}
+
+	for i, client := range Config.Service.Clients {
+		if secret := os.Getenv(clientSecretEnv(client.ClientID)); secret != "" {
+			Config.Service.Clients[i].ClientSecret = secret
+		}
+	}
+
+	if os.Getenv("TRUSTED_PROXIES") != "" {
+		Config.TrustedProxies = strings.Split(os.Getenv("TRUSTED_PROXIES"), ",")
+	}
+
+	if os.Getenv("NOTIFIER_TYPE") != "" {
+		Config.Notifier.Type = os.Getenv("NOTIFIER_TYPE")
+	}
+
+	if os.Getenv("SMTP_HOST") != "" {
+		Config.Notifier.SMTP.Host = os.Getenv("SMTP_HOST")
+	}
+
+	if os.Getenv("SMTP_PASSWORD") != "" {
+		Config.Notifier.SMTP.Password = os.Getenv("SMTP_PASSWORD")
+	}
+
+	if os.Getenv("USER_SERVICE_URL") != "" {
+		Config.UserServiceUrl = os.Getenv("USER_SERVICE_URL")
+	}
+
+	if os.Getenv("AUTH_SERVICE_PUBLIC_URL") != "" {
+		Config.AuthServicePublicUrl = os.Getenv("AUTH_SERVICE_PUBLIC_URL")
+	}
+}
+
+// clientSecretEnv is the env of the secret of a service client, SERVICE_CLIENT_SECRET_ then
+// the client id in upper case with every character other than a letter or a digit replaced by _
+func clientSecretEnv(clientID string) string {
+	name := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, clientID)
+
+	return "SERVICE_CLIENT_SECRET_" + strings.ToUpper(name)
+}
+
+// ReadSecret return secret, set by env, or else the content of file without surrounding white space
+func ReadSecret(secret, file string) (string, error) {
+	if secret != "" || file == "" {
+		return secret, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret: %w", err)
+	}
+
+	return string(bytes.TrimSpace(data)), nil
 }
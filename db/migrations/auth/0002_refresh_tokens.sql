@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    jti VARCHAR(255) NOT NULL,
+    family_ksuid VARCHAR(255) NOT NULL,
+    user_ksuid VARCHAR(255) NOT NULL,
+    expires_at DATETIME NOT NULL,
+    revoked_at DATETIME NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY(jti),
+    INDEX idx_refresh_tokens_family_ksuid (family_ksuid),
+    INDEX idx_refresh_tokens_user_ksuid (user_ksuid)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE refresh_tokens;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS signing_keys (
+    kid VARCHAR(255) NOT NULL,
+    token_type VARCHAR(50) NOT NULL,
+    algorithm VARCHAR(50) NOT NULL,
+    private_key TEXT NOT NULL,
+    retired_at DATETIME NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY(kid),
+    INDEX idx_signing_keys_token_type (token_type)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE signing_keys;
+-- +goose StatementEnd
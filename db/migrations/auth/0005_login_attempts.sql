@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS login_attempts (
+    `key` VARCHAR(255) NOT NULL,
+    failed_count INT NOT NULL DEFAULT 0,
+    lock_count INT NOT NULL DEFAULT 0,
+    locked_until DATETIME NULL,
+    last_failed_at DATETIME NOT NULL,
+    PRIMARY KEY(`key`)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE login_attempts;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS mfa_secrets (
+    user_ksuid VARCHAR(255) NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    confirmed_at DATETIME NULL,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY(user_ksuid)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE mfa_secrets;
+-- +goose StatementEnd
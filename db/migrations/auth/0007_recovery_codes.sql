@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    code_hash VARCHAR(255) NOT NULL,
+    user_ksuid VARCHAR(255) NOT NULL,
+    used_at DATETIME NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY(code_hash),
+    INDEX idx_recovery_codes_user_ksuid (user_ksuid)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE recovery_codes;
+-- +goose StatementEnd
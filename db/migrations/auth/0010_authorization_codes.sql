@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS authorization_codes (
+    code_hash VARCHAR(255) NOT NULL,
+    client_id VARCHAR(255) NOT NULL,
+    user_ksuid VARCHAR(255) NOT NULL,
+    redirect_uri TEXT NOT NULL,
+    code_challenge VARCHAR(255) NOT NULL,
+    scope VARCHAR(1000) NOT NULL DEFAULT '',
+    expires_at DATETIME NOT NULL,
+    used_at DATETIME NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY(code_hash)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE authorization_codes;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS password_histories (
+    ksuid VARCHAR(255) NOT NULL,
+    user_ksuid VARCHAR(255) NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY(ksuid),
+    INDEX idx_password_histories_user_ksuid (user_ksuid)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE password_histories;
+-- +goose StatementEnd
@@ -0,0 +1,71 @@
+-- +goose Up
+-- columns added to users after 0001. Migrations run on every start without versioning,
+-- so a column is added only when information_schema does not have it yet. The statements
+-- share the session variables in the transaction goose runs the file in
+
+SET @add_password_changed_at = IF(
+    (SELECT COUNT(*) FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 'users' AND column_name = 'password_changed_at') = 0,
+    'ALTER TABLE users ADD COLUMN password_changed_at DATETIME NULL',
+    'DO 0'
+);
+PREPARE add_column FROM @add_password_changed_at;
+EXECUTE add_column;
+DEALLOCATE PREPARE add_column;
+
+SET @add_email = IF(
+    (SELECT COUNT(*) FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 'users' AND column_name = 'email') = 0,
+    'ALTER TABLE users ADD COLUMN email VARCHAR(255) NULL',
+    'DO 0'
+);
+PREPARE add_column FROM @add_email;
+EXECUTE add_column;
+DEALLOCATE PREPARE add_column;
+
+SET @add_email_unique = IF(
+    (SELECT COUNT(*) FROM information_schema.statistics
+        WHERE table_schema = DATABASE() AND table_name = 'users' AND column_name = 'email' AND non_unique = 0) = 0,
+    'ALTER TABLE users ADD UNIQUE INDEX idx_users_email (email)',
+    'DO 0'
+);
+PREPARE add_index FROM @add_email_unique;
+EXECUTE add_index;
+DEALLOCATE PREPARE add_index;
+
+SET @add_email_verified_at = IF(
+    (SELECT COUNT(*) FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 'users' AND column_name = 'email_verified_at') = 0,
+    'ALTER TABLE users ADD COLUMN email_verified_at DATETIME NULL',
+    'DO 0'
+);
+PREPARE add_column FROM @add_email_verified_at;
+EXECUTE add_column;
+DEALLOCATE PREPARE add_column;
+
+SET @add_invited_at = IF(
+    (SELECT COUNT(*) FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 'users' AND column_name = 'invited_at') = 0,
+    'ALTER TABLE users ADD COLUMN invited_at DATETIME NULL',
+    'DO 0'
+);
+PREPARE add_column FROM @add_invited_at;
+EXECUTE add_column;
+DEALLOCATE PREPARE add_column;
+
+SET @add_deleted_at = IF(
+    (SELECT COUNT(*) FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 'users' AND column_name = 'deleted_at') = 0,
+    'ALTER TABLE users ADD COLUMN deleted_at DATETIME NULL',
+    'DO 0'
+);
+PREPARE add_column FROM @add_deleted_at;
+EXECUTE add_column;
+DEALLOCATE PREPARE add_column;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN deleted_at;
+ALTER TABLE users DROP COLUMN invited_at;
+ALTER TABLE users DROP COLUMN email_verified_at;
+ALTER TABLE users DROP COLUMN email;
+ALTER TABLE users DROP COLUMN password_changed_at;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- columns added to authorization_codes after 0010. Migrations run on every start without versioning,
+-- so a column is added only when information_schema does not have it yet. The statements
+-- share the session variables in the transaction goose runs the file in
+
+SET @add_nonce = IF(
+    (SELECT COUNT(*) FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 'authorization_codes' AND column_name = 'nonce') = 0,
+    'ALTER TABLE authorization_codes ADD COLUMN nonce VARCHAR(255) NOT NULL DEFAULT ''''',
+    'DO 0'
+);
+PREPARE add_column FROM @add_nonce;
+EXECUTE add_column;
+DEALLOCATE PREPARE add_column;
+
+-- +goose Down
+ALTER TABLE authorization_codes DROP COLUMN nonce;
@@ -0,0 +1,26 @@
+-- +goose Up
+-- the OAuth client and granted scope of a session, empty for /login.
+-- Like 0014, a column is added only when information_schema does not have it yet
+
+SET @add_client_id = IF(
+    (SELECT COUNT(*) FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 'sessions' AND column_name = 'client_id') = 0,
+    'ALTER TABLE sessions ADD COLUMN client_id VARCHAR(255) NOT NULL DEFAULT ''''',
+    'DO 0'
+);
+PREPARE add_column FROM @add_client_id;
+EXECUTE add_column;
+DEALLOCATE PREPARE add_column;
+
+SET @add_scope = IF(
+    (SELECT COUNT(*) FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 'sessions' AND column_name = 'scope') = 0,
+    'ALTER TABLE sessions ADD COLUMN scope VARCHAR(1024) NOT NULL DEFAULT ''''',
+    'DO 0'
+);
+PREPARE add_column FROM @add_scope;
+EXECUTE add_column;
+DEALLOCATE PREPARE add_column;
+
+-- +goose Down
+ALTER TABLE sessions DROP COLUMN client_id, DROP COLUMN scope;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- a user who must verify the email address before the first login, e.g. registered
+-- through an allowed email domain. Like 0014, the column is added only once
+
+SET @add_email_verification_required = IF(
+    (SELECT COUNT(*) FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 'users' AND column_name = 'email_verification_required') = 0,
+    'ALTER TABLE users ADD COLUMN email_verification_required TINYINT(1) NOT NULL DEFAULT 0',
+    'DO 0'
+);
+PREPARE add_column FROM @add_email_verification_required;
+EXECUTE add_column;
+DEALLOCATE PREPARE add_column;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN email_verification_required;
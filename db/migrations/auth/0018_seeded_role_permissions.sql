@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the built-in permissions once granted to a built-in role on start, a permission
+-- removed from the role through the API is not granted again on the next start
+CREATE TABLE IF NOT EXISTS seeded_role_permissions (
+    role VARCHAR(50) NOT NULL,
+    permission VARCHAR(100) NOT NULL,
+    PRIMARY KEY(role, permission)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- every start so far granted the built-in permissions the built-in roles still have
+INSERT IGNORE INTO seeded_role_permissions (role, permission)
+SELECT role, permission FROM role_permissions WHERE role IN ('admin', 'user', 'service');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE seeded_role_permissions;
+-- +goose StatementEnd
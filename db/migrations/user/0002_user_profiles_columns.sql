@@ -0,0 +1,39 @@
+-- +goose Up
+-- columns added to user_profiles after 0001. Migrations run on every start without versioning,
+-- so a column is added only when information_schema does not have it yet. The statements
+-- share the session variables in the transaction goose runs the file in
+
+SET @add_version = IF(
+    (SELECT COUNT(*) FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 'user_profiles' AND column_name = 'version') = 0,
+    'ALTER TABLE user_profiles ADD COLUMN version INT NOT NULL DEFAULT 1',
+    'DO 0'
+);
+PREPARE add_column FROM @add_version;
+EXECUTE add_column;
+DEALLOCATE PREPARE add_column;
+
+SET @add_updated_at = IF(
+    (SELECT COUNT(*) FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 'user_profiles' AND column_name = 'updated_at') = 0,
+    'ALTER TABLE user_profiles ADD COLUMN updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP',
+    'DO 0'
+);
+PREPARE add_column FROM @add_updated_at;
+EXECUTE add_column;
+DEALLOCATE PREPARE add_column;
+
+SET @add_deleted_at = IF(
+    (SELECT COUNT(*) FROM information_schema.columns
+        WHERE table_schema = DATABASE() AND table_name = 'user_profiles' AND column_name = 'deleted_at') = 0,
+    'ALTER TABLE user_profiles ADD COLUMN deleted_at DATETIME NULL',
+    'DO 0'
+);
+PREPARE add_column FROM @add_deleted_at;
+EXECUTE add_column;
+DEALLOCATE PREPARE add_column;
+
+-- +goose Down
+ALTER TABLE user_profiles DROP COLUMN deleted_at;
+ALTER TABLE user_profiles DROP COLUMN updated_at;
+ALTER TABLE user_profiles DROP COLUMN version;
@@ -0,0 +1,67 @@
+package key_controller
+
+import (
+	"net/http"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/adesupraptolaia/user_login/internal/usecase"
+	"github.com/adesupraptolaia/user_login/pkg/jwt"
+	"github.com/adesupraptolaia/user_login/pkg/validator"
+	"github.com/labstack/echo/v4"
+)
+
+type KeyHandler struct {
+	uc usecase.SigningKeyUC
+}
+
+func NewKeyHandler(uc usecase.SigningKeyUC) KeyHandler {
+	return KeyHandler{
+		uc: uc,
+	}
+}
+
+// JWKS godoc
+// @Summary JSON Web Key Set
+// @Description Public keys to verify access tokens, select the key by the kid header of the token
+// @Tags Public
+// @Produce json
+// @Success 200 {object} jwt.JWKS
+// @Router /.well-known/jwks.json [get]
+func (h KeyHandler) JWKS(ctx echo.Context) error {
+	ctx.Response().Header().Set("Cache-Control", "public, max-age=300")
+
+	return ctx.JSON(http.StatusOK, jwt.PublicJWKS())
+}
+
+// RotateKey godoc
+// @Summary Rotate Signing Key
+// @Description Requires permission key:rotate. Rotate the signing key of access or refresh token, previous keys keep verifying tokens until they expire
+// @Tags Private
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}"
+// @Param payload body entity.RotateKeyRequest true "Request Payload"
+// @Success 201 {object} SuccessResp
+// @Response 400 {object} ErrorResp
+// @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /keys/rotate [post]
+func (h KeyHandler) RotateKey(ctx echo.Context) error {
+	req := entity.RotateKeyRequest{}
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	err := validator.ValidateStruct(req)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	newKey, err := h.uc.RotateKey(req.TokenType)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	return ctx.JSON(http.StatusCreated, SuccessResponse(newKey))
+}
@@ -0,0 +1,92 @@
+package oauth_controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/adesupraptolaia/user_login/internal/usecase"
+	"github.com/adesupraptolaia/user_login/pkg/validator"
+	"github.com/labstack/echo/v4"
+)
+
+// GetClients godoc
+// @Summary Get OAuth Clients
+// @Description Requires permission oauth_client:manage
+// @Tags Private
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200 {object} ClientsSuccessResp
+// @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /oauth/clients [get]
+func (h OAuthHandler) GetClients(ctx echo.Context) error {
+	clients, err := h.uc.GetClients()
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	return ctx.JSON(http.StatusOK, SuccessClientsResponse(clients))
+}
+
+// CreateClient godoc
+// @Summary Register OAuth Client
+// @Description Requires permission oauth_client:manage. The client secret is only returned here, a public client gets no secret and must use PKCE
+// @Tags Private
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}"
+// @Param payload body entity.OAuthClientRequest true "Request Payload"
+// @Success 201 {object} ClientCredentialsSuccessResp
+// @Response 400 {object} ErrorResp
+// @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /oauth/clients [post]
+func (h OAuthHandler) CreateClient(ctx echo.Context) error {
+	req := entity.OAuthClientRequest{}
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	err := validator.ValidateStruct(req)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	client, err := h.uc.CreateClient(req)
+	if errors.Is(err, usecase.ErrInvalidClientMetadata) {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	return ctx.JSON(http.StatusCreated, SuccessClientCredentialsResponse(client))
+}
+
+// DeleteClient godoc
+// @Summary Delete OAuth Client
+// @Description Requires permission oauth_client:manage. Tokens already issued to the client stay valid until they expire
+// @Tags Private
+// @Produce  json
+// @Param client_id path string true "Client ID"
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200 {object} StatusResp
+// @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 404 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /oauth/clients/{client_id} [delete]
+func (h OAuthHandler) DeleteClient(ctx echo.Context) error {
+	err := h.uc.DeleteClient(ctx.Param("client_id"))
+	if errors.Is(err, usecase.ErrOAuthClientNotFound) {
+		return ctx.JSON(http.StatusNotFound, ErrorResponse(err.Error()))
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	return ctx.JSON(http.StatusOK, SuccessStatusResponse())
+}
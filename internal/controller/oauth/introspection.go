@@ -0,0 +1,78 @@
+package oauth_controller
+
+import (
+	"net/http"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/adesupraptolaia/user_login/pkg/validator"
+	"github.com/labstack/echo/v4"
+)
+
+// Introspect godoc
+// @Summary Token Introspection
+// @Description Requires permission token:introspect. Report whether an access or refresh token is active (RFC 7662), with its claims.
+// @Description A token is inactive once revoked, logged out, or when its user is deleted or has changed password or role
+// @Tags Private
+// @Accept  x-www-form-urlencoded
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}"
+// @Param token formData string true "Access or refresh token"
+// @Param token_type_hint formData string false "access_token or refresh_token"
+// @Success 200 {object} entity.Introspection
+// @Response 400 {object} TokenErrorResp
+// @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 500 {object} TokenErrorResp
+// @Router /oauth/introspect [post]
+func (h OAuthHandler) Introspect(ctx echo.Context) error {
+	req := entity.TokenRequest{}
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, TokenErrorResponse("invalid_request", err.Error()))
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, TokenErrorResponse("invalid_request", err.Error()))
+	}
+
+	introspection, err := h.tokenUC.Introspect(req.Token)
+	if err != nil {
+		return tokenError(ctx, err)
+	}
+
+	ctx.Response().Header().Set("Cache-Control", "no-store")
+
+	return ctx.JSON(http.StatusOK, introspection)
+}
+
+// Revoke godoc
+// @Summary Token Revocation
+// @Description Requires permission token:revoke. Revoke an access or refresh token (RFC 7009), revoking a refresh token ends its session.
+// @Description Services verifying access tokens locally only see the revocation through introspection
+// @Tags Private
+// @Accept  x-www-form-urlencoded
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}"
+// @Param token formData string true "Access or refresh token"
+// @Param token_type_hint formData string false "access_token or refresh_token"
+// @Success 200 {object} StatusResp
+// @Response 400 {object} TokenErrorResp
+// @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 500 {object} TokenErrorResp
+// @Router /oauth/revoke [post]
+func (h OAuthHandler) Revoke(ctx echo.Context) error {
+	req := entity.TokenRequest{}
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, TokenErrorResponse("invalid_request", err.Error()))
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, TokenErrorResponse("invalid_request", err.Error()))
+	}
+
+	if err := h.tokenUC.Revoke(req.Token); err != nil {
+		return tokenError(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusOK, SuccessStatusResponse())
+}
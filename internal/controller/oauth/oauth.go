@@ -0,0 +1,399 @@
+package oauth_controller
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/adesupraptolaia/user_login/internal/usecase"
+	"github.com/adesupraptolaia/user_login/pkg/jwt"
+	"github.com/labstack/echo/v4"
+)
+
+type OAuthHandler struct {
+	uc             usecase.OAuthUC
+	userUC         usecase.UserUC
+	tokenUC        usecase.TokenUC
+	loginAttemptUC usecase.LoginAttemptUC
+	mfaUC          usecase.MfaUC
+	emailUC        usecase.EmailVerificationUC
+}
+
+func NewOAuthHandler(uc usecase.OAuthUC, userUC usecase.UserUC, tokenUC usecase.TokenUC, loginAttemptUC usecase.LoginAttemptUC, mfaUC usecase.MfaUC, emailUC usecase.EmailVerificationUC) OAuthHandler {
+	return OAuthHandler{
+		uc:             uc,
+		userUC:         userUC,
+		tokenUC:        tokenUC,
+		loginAttemptUC: loginAttemptUC,
+		mfaUC:          mfaUC,
+		emailUC:        emailUC,
+	}
+}
+
+// Authorize godoc
+// @Summary OAuth 2.0 Authorization Endpoint
+// @Description Authorization code grant with PKCE (S256 only). Show the login page, the user is sent back to redirect_uri with code and state
+// @Tags Public
+// @Produce html
+// @Param response_type query string true "code"
+// @Param client_id query string true "Client ID"
+// @Param redirect_uri query string false "Registered redirect uri, required when the client has several"
+// @Param scope query string false "Space separated scopes"
+// @Param state query string false "Returned as is"
+// @Param code_challenge query string true "BASE64URL(SHA256(code_verifier))"
+// @Param code_challenge_method query string true "S256"
+// @Param nonce query string false "Copied into the ID Token"
+// @Success 200
+// @Response 302
+// @Response 400
+// @Router /oauth/authorize [get]
+func (h OAuthHandler) Authorize(ctx echo.Context) error {
+	req := authorizeRequest(ctx)
+
+	client, err := h.uc.ValidateAuthorizeRequest(&req)
+	if err != nil {
+		return authorizeError(ctx, req, err)
+	}
+
+	return renderAuthorize(ctx, http.StatusOK, authorizePage{ClientName: client.Name, Request: req})
+}
+
+// AuthorizeLogin godoc
+// @Summary OAuth 2.0 Authorization Endpoint Login
+// @Description Submit the login page, then the MFA code when the user has enrolled MFA. Failed attempts count toward the account lockout of /login
+// @Tags Public
+// @Accept x-www-form-urlencoded
+// @Produce html
+// @Param username formData string false "Username"
+// @Param password formData string false "Password"
+// @Param mfa_token formData string false "Given by the login step when MFA is enrolled"
+// @Param code formData string false "TOTP or recovery code"
+// @Success 200
+// @Response 302
+// @Response 400
+// @Response 401
+// @Response 423
+// @Response 429
+// @Router /oauth/authorize [post]
+func (h OAuthHandler) AuthorizeLogin(ctx echo.Context) error {
+	req := authorizeRequest(ctx)
+
+	client, err := h.uc.ValidateAuthorizeRequest(&req)
+	if err != nil {
+		return authorizeError(ctx, req, err)
+	}
+
+	page := authorizePage{ClientName: client.Name, Request: req}
+	ip := ctx.RealIP()
+
+	var user *entity.User
+	if mfaToken := ctx.FormValue("mfa_token"); mfaToken != "" {
+		claims, err := h.tokenUC.ValidateMfaToken(mfaToken)
+		if err != nil {
+			page.Error = "your session has expired, please sign in again"
+			return renderAuthorize(ctx, http.StatusUnauthorized, page)
+		}
+
+		user, err = h.userUC.GetUserByKsuid(claims.UserKsuid)
+		if err != nil {
+			page.Error = "your session has expired, please sign in again"
+			return renderAuthorize(ctx, http.StatusUnauthorized, page)
+		}
+
+		page.MfaToken = mfaToken
+
+		if err := h.loginAttemptUC.CheckLogin(user.Username, ip); err != nil {
+			page.Error = err.Error()
+			return renderAuthorize(ctx, lockedStatus(err), page)
+		}
+
+		if err := h.mfaUC.Verify(user.Ksuid, ctx.FormValue("code")); err != nil {
+			if err := h.loginAttemptUC.RecordFailedLogin(user.Username, ip); err != nil {
+				page.Error = err.Error()
+				return renderAuthorize(ctx, lockedStatus(err), page)
+			}
+			page.Error = usecase.ErrInvalidMfaCode.Error()
+			return renderAuthorize(ctx, http.StatusUnauthorized, page)
+		}
+
+		if err := h.loginAttemptUC.RecordSuccessLogin(user.Username); err != nil {
+			return renderError(ctx, http.StatusInternalServerError, err.Error())
+		}
+
+		err = h.tokenUC.UseMfaToken(claims)
+		if errors.Is(err, usecase.ErrMfaTokenUsed) {
+			page.MfaToken = ""
+			page.Error = "your session has expired, please sign in again"
+			return renderAuthorize(ctx, http.StatusUnauthorized, page)
+		}
+		if err != nil {
+			return renderError(ctx, http.StatusInternalServerError, err.Error())
+		}
+	} else {
+		username := ctx.FormValue("username")
+
+		if err := h.loginAttemptUC.CheckLogin(username, ip); err != nil {
+			page.Error = err.Error()
+			return renderAuthorize(ctx, lockedStatus(err), page)
+		}
+
+		// unknown username counts as a failed attempt too
+		user, err = h.userUC.Login(username, ctx.FormValue("password"))
+		if err != nil {
+			if err := h.loginAttemptUC.RecordFailedLogin(username, ip); err != nil {
+				page.Error = err.Error()
+				return renderAuthorize(ctx, lockedStatus(err), page)
+			}
+			page.Error = "wrong username or password"
+			return renderAuthorize(ctx, http.StatusUnauthorized, page)
+		}
+
+		if err := h.loginAttemptUC.RecordSuccessLogin(username); err != nil {
+			return renderError(ctx, http.StatusInternalServerError, err.Error())
+		}
+
+		if err := h.emailUC.CheckLogin(user); err != nil {
+			page.Error = err.Error()
+			return renderAuthorize(ctx, http.StatusForbidden, page)
+		}
+
+		enrolled, err := h.mfaUC.IsEnrolled(user.Ksuid)
+		if err != nil {
+			return renderError(ctx, http.StatusInternalServerError, err.Error())
+		}
+
+		if enrolled {
+			page.MfaToken, err = jwt.CreateMfaToken(user.Ksuid, user.Role)
+			if err != nil {
+				return renderError(ctx, http.StatusInternalServerError, err.Error())
+			}
+			return renderAuthorize(ctx, http.StatusOK, page)
+		}
+	}
+
+	code, err := h.uc.CreateAuthorizationCode(req, user.Ksuid)
+	if err != nil {
+		return renderError(ctx, http.StatusInternalServerError, err.Error())
+	}
+
+	return redirect(ctx, req.RedirectURI, url.Values{"code": {code}}, req.State)
+}
+
+// Token godoc
+// @Summary OAuth 2.0 Token Endpoint
+// @Description Grant types authorization_code (with code_verifier), refresh_token and client_credentials.
+// @Description An ID Token is issued with the authorization code when scope has openid.
+// @Description Tokens of a user are for user app only, with the permissions of the role within the granted scope, and refreshed by the same client only.
+// @Description Confidential clients authenticate with HTTP Basic or client_id and client_secret, public clients send client_id only
+// @Tags Public
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param Authorization header string false "Basic {base64 client_id:client_secret}"
+// @Param grant_type formData string true "authorization_code, refresh_token or client_credentials"
+// @Param client_id formData string false "Client ID"
+// @Param client_secret formData string false "Client Secret"
+// @Param code formData string false "Authorization code"
+// @Param redirect_uri formData string false "Same redirect_uri as the authorization request"
+// @Param code_verifier formData string false "PKCE code verifier"
+// @Param refresh_token formData string false "Refresh token"
+// @Param scope formData string false "Space separated scopes of client_credentials"
+// @Success 200 {object} entity.OAuthToken
+// @Response 400 {object} TokenErrorResp
+// @Response 401 {object} TokenErrorResp
+// @Response 500 {object} TokenErrorResp
+// @Router /oauth/token [post]
+func (h OAuthHandler) Token(ctx echo.Context) error {
+	ctx.Response().Header().Set("Cache-Control", "no-store")
+	ctx.Response().Header().Set("Pragma", "no-cache")
+
+	clientID, clientSecret, ok := ctx.Request().BasicAuth()
+	if ok {
+		// credentials are form encoded before HTTP Basic, RFC 6749 section 2.3.1
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID, clientSecret = ctx.FormValue("client_id"), ctx.FormValue("client_secret")
+	}
+
+	client, err := h.uc.AuthenticateClient(clientID, clientSecret)
+	if err != nil {
+		return tokenError(ctx, err)
+	}
+
+	switch grantType := ctx.FormValue("grant_type"); grantType {
+	case entity.GRANT_AUTHORIZATION_CODE:
+		code, err := h.uc.ExchangeAuthorizationCode(client, ctx.FormValue("code"), ctx.FormValue("redirect_uri"), ctx.FormValue("code_verifier"))
+		if err != nil {
+			return tokenError(ctx, err)
+		}
+
+		user, err := h.userUC.GetUserByKsuid(code.UserKsuid)
+		if err != nil {
+			return tokenError(ctx, usecase.ErrInvalidGrant)
+		}
+
+		token, err := h.tokenUC.CreateClientSessionToken(user, client.ClientID, code.Scope)
+		if err != nil {
+			return tokenError(ctx, err)
+		}
+
+		oauthToken := oauthToken(client, token)
+
+		if contains(strings.Fields(code.Scope), entity.OIDC_SCOPE) {
+			oauthToken.IDToken, err = jwt.CreateIDToken(user.Ksuid, user.Username, client.ClientID, code.Nonce, code.CreatedAt)
+			if err != nil {
+				return tokenError(ctx, err)
+			}
+		}
+
+		return ctx.JSON(http.StatusOK, oauthToken)
+
+	case entity.GRANT_REFRESH_TOKEN:
+		if !client.HasGrantType(entity.GRANT_REFRESH_TOKEN) {
+			return tokenError(ctx, usecase.ErrUnauthorizedClient)
+		}
+
+		token, err := h.tokenUC.RefreshToken(ctx.FormValue("refresh_token"), client.ClientID)
+		if errors.Is(err, usecase.ErrInvalidRefreshToken) {
+			return ctx.JSON(http.StatusBadRequest, TokenErrorResponse("invalid_grant", err.Error()))
+		}
+		if err != nil {
+			return tokenError(ctx, err)
+		}
+
+		return ctx.JSON(http.StatusOK, oauthToken(client, token))
+
+	case entity.GRANT_CLIENT_CREDENTIALS:
+		token, err := h.uc.CreateClientToken(client, ctx.FormValue("scope"))
+		if err != nil {
+			return tokenError(ctx, err)
+		}
+
+		return ctx.JSON(http.StatusOK, token)
+
+	default:
+		return ctx.JSON(http.StatusBadRequest, TokenErrorResponse("unsupported_grant_type", "unsupported grant_type "+grantType))
+	}
+}
+
+func authorizeRequest(ctx echo.Context) entity.AuthorizeRequest {
+	return entity.AuthorizeRequest{
+		ResponseType:        ctx.FormValue("response_type"),
+		ClientID:            ctx.FormValue("client_id"),
+		RedirectURI:         ctx.FormValue("redirect_uri"),
+		Scope:               ctx.FormValue("scope"),
+		State:               ctx.FormValue("state"),
+		CodeChallenge:       ctx.FormValue("code_challenge"),
+		CodeChallengeMethod: ctx.FormValue("code_challenge_method"),
+		Nonce:               ctx.FormValue("nonce"),
+	}
+}
+
+// authorizeError send the error back to the client, unless the client or
+// redirect_uri is invalid, then the error is shown to the user
+func authorizeError(ctx echo.Context, req entity.AuthorizeRequest, err error) error {
+	var oauthErr *usecase.OAuthError
+	if errors.As(err, &oauthErr) {
+		return redirect(ctx, req.RedirectURI, url.Values{
+			"error":             {oauthErr.Code},
+			"error_description": {oauthErr.Description},
+		}, req.State)
+	}
+
+	return renderError(ctx, http.StatusBadRequest, err.Error())
+}
+
+func tokenError(ctx echo.Context, err error) error {
+	var oauthErr *usecase.OAuthError
+	switch {
+	case errors.Is(err, usecase.ErrInvalidClient):
+		ctx.Response().Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+		return ctx.JSON(http.StatusUnauthorized, TokenErrorResponse("invalid_client", err.Error()))
+	case errors.As(err, &oauthErr):
+		return ctx.JSON(http.StatusBadRequest, TokenErrorResponse(oauthErr.Code, oauthErr.Description))
+	default:
+		return ctx.JSON(http.StatusInternalServerError, TokenErrorResponse("server_error", err.Error()))
+	}
+}
+
+// lockedStatus is the status of the login page refused by the login throttling
+func lockedStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrAccountLocked):
+		return http.StatusLocked
+	case errors.Is(err, usecase.ErrTooManyAttempts):
+		return http.StatusTooManyRequests
+	}
+
+	return http.StatusInternalServerError
+}
+
+func oauthToken(client *entity.OAuthClient, token *entity.Token) *entity.OAuthToken {
+	oauthToken := &entity.OAuthToken{
+		AccessToken: token.AccessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(jwt.ACCESS_TOKEN_TTL.Seconds()),
+		Scope:       token.Scope,
+	}
+
+	if client.HasGrantType(entity.GRANT_REFRESH_TOKEN) {
+		oauthToken.RefreshToken = token.RefreshToken
+	}
+
+	return oauthToken
+}
+
+func contains(list []string, item string) bool {
+	for _, i := range list {
+		if i == item {
+			return true
+		}
+	}
+
+	return false
+}
+
+func redirect(ctx echo.Context, redirectURI string, params url.Values, state string) error {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return renderError(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	query := u.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	if state != "" {
+		query.Set("state", state)
+	}
+	u.RawQuery = query.Encode()
+
+	return ctx.Redirect(http.StatusFound, u.String())
+}
+
+func renderAuthorize(ctx echo.Context, status int, page authorizePage) error {
+	return render(ctx, status, authorizeTemplate, page)
+}
+
+func renderError(ctx echo.Context, status int, message string) error {
+	return render(ctx, status, errorTemplate, message)
+}
+
+func render(ctx echo.Context, status int, tmpl *template.Template, data interface{}) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+
+	// the login page must not be framed by another site
+	header := ctx.Response().Header()
+	header.Set("Cache-Control", "no-store")
+	header.Set("X-Frame-Options", "DENY")
+	header.Set("Content-Security-Policy", "frame-ancestors 'none'")
+
+	return ctx.HTMLBlob(status, buf.Bytes())
+}
@@ -0,0 +1,73 @@
+package oauth_controller
+
+import (
+	"github.com/adesupraptolaia/user_login/internal/entity"
+)
+
+// swagger:model
+type ClientsSuccessResp struct {
+	// success
+	Status string               `json:"status"`
+	Data   []entity.OAuthClient `json:"data"`
+}
+
+// swagger:model
+type ClientCredentialsSuccessResp struct {
+	// success
+	Status string                         `json:"status"`
+	Data   *entity.OAuthClientCredentials `json:"data"`
+}
+
+// swagger:model
+type StatusResp struct {
+	// success
+	Status string `json:"status"`
+}
+
+// swagger:model
+type ErrorResp struct {
+	// error
+	Status       string `json:"status"`
+	ErrorMessage string `json:"error_message"`
+}
+
+// TokenErrorResp is the error of the token endpoint, RFC 6749 section 5.2
+// swagger:model
+type TokenErrorResp struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+func SuccessClientsResponse(data []entity.OAuthClient) ClientsSuccessResp {
+	return ClientsSuccessResp{
+		Status: "success",
+		Data:   data,
+	}
+}
+
+func SuccessClientCredentialsResponse(data *entity.OAuthClientCredentials) ClientCredentialsSuccessResp {
+	return ClientCredentialsSuccessResp{
+		Status: "success",
+		Data:   data,
+	}
+}
+
+func SuccessStatusResponse() StatusResp {
+	return StatusResp{
+		Status: "success",
+	}
+}
+
+func ErrorResponse(error_message string) ErrorResp {
+	return ErrorResp{
+		Status:       "error",
+		ErrorMessage: error_message,
+	}
+}
+
+func TokenErrorResponse(error_code, error_description string) TokenErrorResp {
+	return TokenErrorResp{
+		Error:            error_code,
+		ErrorDescription: error_description,
+	}
+}
@@ -0,0 +1,60 @@
+package oauth_controller
+
+import (
+	"html/template"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+)
+
+// authorizePage is the data of authorizeTemplate, the request is carried
+// in hidden fields from the login step to the MFA step
+type authorizePage struct {
+	ClientName string
+	Request    entity.AuthorizeRequest
+	MfaToken   string
+	Error      string
+}
+
+var authorizeTemplate = template.Must(template.New("authorize").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Sign in</title>
+</head>
+<body>
+<h1>Sign in to {{.ClientName}}</h1>
+{{if .Error}}<p role="alert">{{.Error}}</p>{{end}}
+<form method="post" action="/oauth/authorize">
+<input type="hidden" name="response_type" value="{{.Request.ResponseType}}">
+<input type="hidden" name="client_id" value="{{.Request.ClientID}}">
+<input type="hidden" name="redirect_uri" value="{{.Request.RedirectURI}}">
+<input type="hidden" name="scope" value="{{.Request.Scope}}">
+<input type="hidden" name="state" value="{{.Request.State}}">
+<input type="hidden" name="code_challenge" value="{{.Request.CodeChallenge}}">
+<input type="hidden" name="code_challenge_method" value="{{.Request.CodeChallengeMethod}}">
+<input type="hidden" name="nonce" value="{{.Request.Nonce}}">
+{{if .MfaToken}}
+<input type="hidden" name="mfa_token" value="{{.MfaToken}}">
+<label>Authentication or recovery code <input name="code" autocomplete="one-time-code" required autofocus></label>
+{{else}}
+<label>Username <input name="username" autocomplete="username" required autofocus></label>
+<label>Password <input type="password" name="password" autocomplete="current-password" required></label>
+{{end}}
+<button type="submit">Continue</button>
+</form>
+</body>
+</html>
+`))
+
+var errorTemplate = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Authorization error</title>
+</head>
+<body>
+<h1>Authorization error</h1>
+<p>{{.}}</p>
+</body>
+</html>
+`))
@@ -0,0 +1,78 @@
+package oidc_controller
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	auth_middleware "github.com/adesupraptolaia/user_login/internal/middleware/auth"
+	"github.com/adesupraptolaia/user_login/internal/usecase"
+	"github.com/adesupraptolaia/user_login/pkg/jwt"
+	"github.com/labstack/echo/v4"
+)
+
+type OIDCHandler struct {
+	uc usecase.UserInfoUC
+}
+
+func NewOIDCHandler(uc usecase.UserInfoUC) OIDCHandler {
+	return OIDCHandler{
+		uc: uc,
+	}
+}
+
+// Discovery godoc
+// @Summary OpenID Connect Discovery
+// @Description Endpoints and capabilities of the auth app, the issuer is jwt.issuer of config
+// @Tags Public
+// @Produce json
+// @Success 200 {object} entity.OpenIDConfiguration
+// @Router /.well-known/openid-configuration [get]
+func (h OIDCHandler) Discovery(ctx echo.Context) error {
+	issuer := strings.TrimSuffix(jwt.ISSUER, "/")
+
+	ctx.Response().Header().Set("Cache-Control", "public, max-age=300")
+
+	return ctx.JSON(http.StatusOK, entity.OpenIDConfiguration{
+		Issuer:                            jwt.ISSUER,
+		AuthorizationEndpoint:             issuer + "/oauth/authorize",
+		TokenEndpoint:                     issuer + "/oauth/token",
+		UserinfoEndpoint:                  issuer + "/userinfo",
+		JwksURI:                           issuer + "/.well-known/jwks.json",
+		ScopesSupported:                   []string{entity.OIDC_SCOPE, "profile", "address"},
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{entity.GRANT_AUTHORIZATION_CODE, entity.GRANT_REFRESH_TOKEN, entity.GRANT_CLIENT_CREDENTIALS},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  jwt.SigningAlgorithms(),
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+		ClaimsSupported:                   []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "preferred_username", "name", "birthdate", "address"},
+	})
+}
+
+// UserInfo godoc
+// @Summary OpenID Connect UserInfo
+// @Description Standard claims of the logged in user, mapped from the profile in user app
+// @Tags Public
+// @Produce json
+// @Param Authorization header string true "Bearer {access_token}"
+// @Success 200 {object} entity.UserInfo
+// @Response 401 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /userinfo [get]
+func (h OIDCHandler) UserInfo(ctx echo.Context) error {
+	claims := auth_middleware.GetClaims(ctx)
+	accessToken, _ := auth_middleware.GetBearerToken(ctx)
+
+	userInfo, err := h.uc.GetUserInfo(claims.UserKsuid, accessToken)
+	if errors.Is(err, usecase.ErrUserInfoNotFound) {
+		ctx.Response().Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		return ctx.JSON(http.StatusUnauthorized, ErrorResponse(err.Error()))
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	return ctx.JSON(http.StatusOK, userInfo)
+}
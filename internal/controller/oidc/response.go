@@ -0,0 +1,15 @@
+package oidc_controller
+
+// swagger:model
+type ErrorResp struct {
+	// error
+	Status       string `json:"status"`
+	ErrorMessage string `json:"error_message"`
+}
+
+func ErrorResponse(error_message string) ErrorResp {
+	return ErrorResp{
+		Status:       "error",
+		ErrorMessage: error_message,
+	}
+}
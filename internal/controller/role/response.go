@@ -0,0 +1,101 @@
+package role_controller
+
+import (
+	"github.com/adesupraptolaia/user_login/internal/entity"
+)
+
+// swagger:model
+type RoleSuccessResp struct {
+	// success
+	Status string       `json:"status"`
+	Data   *entity.Role `json:"data"`
+}
+
+// swagger:model
+type RolesSuccessResp struct {
+	// success
+	Status string        `json:"status"`
+	Data   []entity.Role `json:"data"`
+}
+
+// swagger:model
+type PermissionSuccessResp struct {
+	// success
+	Status string             `json:"status"`
+	Data   *entity.Permission `json:"data"`
+}
+
+// swagger:model
+type PermissionsSuccessResp struct {
+	// success
+	Status string              `json:"status"`
+	Data   []entity.Permission `json:"data"`
+}
+
+// swagger:model
+type UserSuccessResp struct {
+	// success
+	Status string       `json:"status"`
+	Data   *entity.User `json:"data"`
+}
+
+// swagger:model
+type StatusResp struct {
+	// success
+	Status string `json:"status"`
+}
+
+// swagger:model
+type ErrorResp struct {
+	// error
+	Status       string `json:"status"`
+	ErrorMessage string `json:"error_message"`
+}
+
+func SuccessRoleResponse(data *entity.Role) RoleSuccessResp {
+	return RoleSuccessResp{
+		Status: "success",
+		Data:   data,
+	}
+}
+
+func SuccessRolesResponse(data []entity.Role) RolesSuccessResp {
+	return RolesSuccessResp{
+		Status: "success",
+		Data:   data,
+	}
+}
+
+func SuccessPermissionResponse(data *entity.Permission) PermissionSuccessResp {
+	return PermissionSuccessResp{
+		Status: "success",
+		Data:   data,
+	}
+}
+
+func SuccessPermissionsResponse(data []entity.Permission) PermissionsSuccessResp {
+	return PermissionsSuccessResp{
+		Status: "success",
+		Data:   data,
+	}
+}
+
+func SuccessUserResponse(data *entity.User) UserSuccessResp {
+	return UserSuccessResp{
+		Status: "success",
+		Data:   data,
+	}
+}
+
+func SuccessStatusResponse() StatusResp {
+	return StatusResp{
+		Status: "success",
+	}
+}
+
+func ErrorResponse(error_message string) ErrorResp {
+	return ErrorResp{
+		Status:       "error",
+		ErrorMessage: error_message,
+	}
+}
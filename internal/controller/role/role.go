@@ -0,0 +1,256 @@
+package role_controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/adesupraptolaia/user_login/internal/usecase"
+	"github.com/adesupraptolaia/user_login/pkg/validator"
+	"github.com/labstack/echo/v4"
+)
+
+type RoleHandler struct {
+	uc usecase.RoleUC
+}
+
+func NewRoleHandler(uc usecase.RoleUC) RoleHandler {
+	return RoleHandler{
+		uc: uc,
+	}
+}
+
+// GetRoles godoc
+// @Summary List Roles
+// @Description Requires permission role:manage. List every role with its permissions
+// @Tags Private
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200 {object} RolesSuccessResp
+// @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /roles [get]
+func (h RoleHandler) GetRoles(ctx echo.Context) error {
+	roles, err := h.uc.GetRoles()
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	return ctx.JSON(http.StatusOK, SuccessRolesResponse(roles))
+}
+
+// CreateRole godoc
+// @Summary Create Role
+// @Description Requires permission role:manage
+// @Tags Private
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}"
+// @Param payload body entity.RoleRequest true "Request Payload"
+// @Success 201 {object} RoleSuccessResp
+// @Response 400 {object} ErrorResp
+// @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 409 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /roles [post]
+func (h RoleHandler) CreateRole(ctx echo.Context) error {
+	req := entity.RoleRequest{}
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	err := validator.ValidateStruct(req)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	role, err := h.uc.CreateRole(req)
+	switch {
+	case errors.Is(err, usecase.ErrUnknownPermission):
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	case errors.Is(err, usecase.ErrRoleAlreadyExists):
+		return ctx.JSON(http.StatusConflict, ErrorResponse(err.Error()))
+	case err != nil:
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	return ctx.JSON(http.StatusCreated, SuccessRoleResponse(role))
+}
+
+// SetRolePermissions godoc
+// @Summary Set Role Permissions
+// @Description Requires permission role:manage. Replace every permission of the role, users get them on their next token refresh
+// @Tags Private
+// @Accept  json
+// @Produce  json
+// @Param name path string true "Name of Role"
+// @Param Authorization header string true "Bearer {token}"
+// @Param payload body entity.RolePermissionsRequest true "Request Payload"
+// @Success 200 {object} RoleSuccessResp
+// @Response 400 {object} ErrorResp
+// @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 404 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /roles/{name}/permissions [put]
+func (h RoleHandler) SetRolePermissions(ctx echo.Context) error {
+	name := ctx.Param("name")
+
+	req := entity.RolePermissionsRequest{}
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	err := validator.ValidateStruct(req)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	role, err := h.uc.SetRolePermissions(name, req.Permissions)
+	switch {
+	case errors.Is(err, usecase.ErrRoleNotFound):
+		return ctx.JSON(http.StatusNotFound, ErrorResponse(err.Error()))
+	case errors.Is(err, usecase.ErrUnknownPermission), errors.Is(err, usecase.ErrAdminRoleLockedOut):
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	case err != nil:
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	return ctx.JSON(http.StatusOK, SuccessRoleResponse(role))
+}
+
+// DeleteRole godoc
+// @Summary Delete Role
+// @Description Requires permission role:manage. Built-in roles admin and user can't be deleted, nor a role still assigned to users
+// @Tags Private
+// @Produce  json
+// @Param name path string true "Name of Role"
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200 {object} StatusResp
+// @Response 400 {object} ErrorResp
+// @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 404 {object} ErrorResp
+// @Response 409 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /roles/{name} [delete]
+func (h RoleHandler) DeleteRole(ctx echo.Context) error {
+	name := ctx.Param("name")
+
+	err := h.uc.DeleteRole(name)
+	switch {
+	case errors.Is(err, usecase.ErrRoleNotFound):
+		return ctx.JSON(http.StatusNotFound, ErrorResponse(err.Error()))
+	case errors.Is(err, usecase.ErrBuiltInRole):
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	case errors.Is(err, usecase.ErrRoleInUse):
+		return ctx.JSON(http.StatusConflict, ErrorResponse(err.Error()))
+	case err != nil:
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	return ctx.JSON(http.StatusOK, SuccessStatusResponse())
+}
+
+// GetPermissions godoc
+// @Summary List Permissions
+// @Description Requires permission role:manage
+// @Tags Private
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200 {object} PermissionsSuccessResp
+// @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /permissions [get]
+func (h RoleHandler) GetPermissions(ctx echo.Context) error {
+	permissions, err := h.uc.GetPermissions()
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	return ctx.JSON(http.StatusOK, SuccessPermissionsResponse(permissions))
+}
+
+// CreatePermission godoc
+// @Summary Create Permission
+// @Description Requires permission role:manage
+// @Tags Private
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}"
+// @Param payload body entity.PermissionRequest true "Request Payload"
+// @Success 201 {object} PermissionSuccessResp
+// @Response 400 {object} ErrorResp
+// @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 409 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /permissions [post]
+func (h RoleHandler) CreatePermission(ctx echo.Context) error {
+	req := entity.PermissionRequest{}
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	err := validator.ValidateStruct(req)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	permission, err := h.uc.CreatePermission(req)
+	if errors.Is(err, usecase.ErrPermissionExists) {
+		return ctx.JSON(http.StatusConflict, ErrorResponse(err.Error()))
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	return ctx.JSON(http.StatusCreated, SuccessPermissionResponse(permission))
+}
+
+// AssignRole godoc
+// @Summary Assign Role
+// @Description Requires permission role:manage. Change the role of a user, the user has to login again
+// @Tags Private
+// @Accept  json
+// @Produce  json
+// @Param ksuid path string true "Ksuid of User"
+// @Param Authorization header string true "Bearer {token}"
+// @Param payload body entity.AssignRoleRequest true "Request Payload"
+// @Success 200 {object} UserSuccessResp
+// @Response 400 {object} ErrorResp
+// @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 404 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /user/{ksuid}/role [put]
+func (h RoleHandler) AssignRole(ctx echo.Context) error {
+	ksuid := ctx.Param("ksuid")
+
+	req := entity.AssignRoleRequest{}
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	err := validator.ValidateStruct(req)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	user, err := h.uc.AssignRole(ksuid, req.Role)
+	switch {
+	case errors.Is(err, usecase.ErrRoleNotFound):
+		return ctx.JSON(http.StatusNotFound, ErrorResponse(err.Error()))
+	case errors.Is(err, usecase.ErrServiceRole):
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	case err != nil:
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	user.Password = ""
+
+	return ctx.JSON(http.StatusOK, SuccessUserResponse(user))
+}
@@ -0,0 +1,33 @@
+package service_controller
+
+import (
+	"github.com/adesupraptolaia/user_login/internal/entity"
+)
+
+// swagger:model
+type ServiceTokenSuccessResp struct {
+	// success
+	Status string               `json:"status"`
+	Data   *entity.ServiceToken `json:"data"`
+}
+
+// swagger:model
+type ErrorResp struct {
+	// error
+	Status       string `json:"status"`
+	ErrorMessage string `json:"error_message"`
+}
+
+func SuccessServiceTokenResponse(data *entity.ServiceToken) ServiceTokenSuccessResp {
+	return ServiceTokenSuccessResp{
+		Status: "success",
+		Data:   data,
+	}
+}
+
+func ErrorResponse(error_message string) ErrorResp {
+	return ErrorResp{
+		Status:       "error",
+		ErrorMessage: error_message,
+	}
+}
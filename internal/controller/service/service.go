@@ -0,0 +1,52 @@
+package service_controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/adesupraptolaia/user_login/internal/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+type ServiceHandler struct {
+	uc usecase.ServiceClientUC
+}
+
+func NewServiceHandler(uc usecase.ServiceClientUC) ServiceHandler {
+	return ServiceHandler{
+		uc: uc,
+	}
+}
+
+// CreateServiceToken godoc
+// @Summary Service Token
+// @Description Client credentials grant for backend services. The client authenticates with HTTP Basic client_id:client_secret, or with client_id and client_secret form values, and gets an access token with role service for the private server
+// @Tags Private
+// @Accept  x-www-form-urlencoded
+// @Produce  json
+// @Param Authorization header string false "Basic {base64 client_id:client_secret}"
+// @Param client_id formData string false "Client ID"
+// @Param client_secret formData string false "Client Secret"
+// @Success 200 {object} ServiceTokenSuccessResp
+// @Response 401 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /service/token [post]
+func (h ServiceHandler) CreateServiceToken(ctx echo.Context) error {
+	clientID, clientSecret, ok := ctx.Request().BasicAuth()
+	if !ok {
+		clientID, clientSecret = ctx.FormValue("client_id"), ctx.FormValue("client_secret")
+	}
+
+	token, err := h.uc.CreateServiceToken(clientID, clientSecret)
+	if errors.Is(err, usecase.ErrInvalidClient) {
+		ctx.Response().Header().Set("WWW-Authenticate", `Basic realm="service"`)
+		return ctx.JSON(http.StatusUnauthorized, ErrorResponse(err.Error()))
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	ctx.Response().Header().Set("Cache-Control", "no-store")
+
+	return ctx.JSON(http.StatusOK, SuccessServiceTokenResponse(token))
+}
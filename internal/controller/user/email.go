@@ -0,0 +1,73 @@
+package user_controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/adesupraptolaia/user_login/internal/usecase"
+	"github.com/adesupraptolaia/user_login/pkg/validator"
+	"github.com/labstack/echo/v4"
+)
+
+// VerifyEmail godoc
+// @Summary Verify Email
+// @Description Verify the email address with the token sent to it, also accepted as query parameter on GET for links in emails
+// @Tags Public
+// @Accept  json
+// @Produce  json
+// @Param payload body entity.VerifyEmailRequest true "payload"
+// @Success 200 {object} UserSuccessResp
+// @Response 400 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /email/verify [post]
+func (h UserHandler) VerifyEmail(ctx echo.Context) error {
+	req := entity.VerifyEmailRequest{}
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	user, err := h.emailUC.VerifyEmail(req.Token)
+	if errors.Is(err, usecase.ErrInvalidVerificationToken) {
+		return ctx.JSON(http.StatusBadRequest, ErrorCodeResponse(INVALID_TOKEN, err.Error()))
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	user.Password = ""
+
+	return ctx.JSON(http.StatusOK, SuccessResponse(user))
+}
+
+// SendVerification godoc
+// @Summary Send Email Verification
+// @Description Send another verification link to the email address of the user, the response is the same whether the username exists or not
+// @Tags Public
+// @Accept  json
+// @Produce  json
+// @Param payload body entity.SendVerificationRequest true "payload"
+// @Success 202 {object} StatusResp
+// @Response 400 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /email/verify/send [post]
+func (h UserHandler) SendVerification(ctx echo.Context) error {
+	req := entity.SendVerificationRequest{}
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	if err := h.emailUC.ResendVerification(req.Username); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	return ctx.JSON(http.StatusAccepted, SuccessStatusResponse())
+}
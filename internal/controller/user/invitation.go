@@ -0,0 +1,137 @@
+package user_controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/adesupraptolaia/user_login/internal/usecase"
+	"github.com/adesupraptolaia/user_login/pkg/validator"
+	"github.com/labstack/echo/v4"
+)
+
+// InviteUser godoc
+// @Summary Invite User
+// @Description Requires a service token with permission user:create. The user is created without password and can't login
+// @Description until choosing one on /invite/accept with the invite_token. A verification link is sent to the email address, if any
+// @Tags Private
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}"
+// @Param payload body entity.InviteUserRequest true "Request Payload"
+// @Success 201 {object} InvitationSuccessResp
+// @Response 400 {object} ErrorResp
+// @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 409 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /invite [post]
+func (h UserHandler) InviteUser(ctx echo.Context) error {
+	req := entity.InviteUserRequest{}
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	invitation, err := h.invitationUC.InviteUser(req)
+	if errors.Is(err, usecase.ErrUserAlreadyExists) {
+		return ctx.JSON(http.StatusConflict, ErrorResponse(err.Error()))
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	// the user can ask for another one through /email/verify/send
+	user := &entity.User{Ksuid: invitation.UserKsuid, Username: invitation.Username, Email: invitation.Email}
+	if err := h.emailUC.SendVerification(user); err != nil {
+		ctx.Logger().Errorf("error when send email verification, err: %s", err.Error())
+	}
+
+	return ctx.JSON(http.StatusCreated, SuccessInvitationResponse(invitation))
+}
+
+// GetInvitations godoc
+// @Summary Get pending Invitations
+// @Description Requires a service token with permission user:create. The expired invitations are listed too, until revoked
+// @Tags Private
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200 {object} InvitationsSuccessResp
+// @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /invites [get]
+func (h UserHandler) GetInvitations(ctx echo.Context) error {
+	invitations, err := h.invitationUC.GetInvitations()
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	return ctx.JSON(http.StatusOK, SuccessInvitationsResponse(invitations))
+}
+
+// RevokeInvitation godoc
+// @Summary Revoke Invitation
+// @Description Requires a service token with permission user:delete. The invited user is deleted, unless the invitation is already accepted
+// @Tags Private
+// @Accept  json
+// @Produce  json
+// @Param ksuid path string true "Ksuid of User"
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200 {object} UserSuccessResp
+// @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 404 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /invite/{ksuid} [delete]
+func (h UserHandler) RevokeInvitation(ctx echo.Context) error {
+	user, err := h.invitationUC.RevokeInvitation(ctx.Param("ksuid"))
+	if errors.Is(err, usecase.ErrInvitationNotFound) {
+		return ctx.JSON(http.StatusNotFound, ErrorResponse(err.Error()))
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	return ctx.JSON(http.StatusOK, SuccessResponse(user))
+}
+
+// AcceptInvitation godoc
+// @Summary Accept Invitation
+// @Description Choose the password with the invite_token given to the invited user, who can login from then on
+// @Tags Public
+// @Accept  json
+// @Produce  json
+// @Param payload body entity.AcceptInvitationRequest true "payload"
+// @Success 200 {object} StatusResp
+// @Response 400 {object} PasswordPolicyErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /invite/accept [post]
+func (h UserHandler) AcceptInvitation(ctx echo.Context) error {
+	req := entity.AcceptInvitationRequest{}
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	_, err := h.invitationUC.AcceptInvitation(req)
+	if errors.Is(err, usecase.ErrInvalidInvitationToken) {
+		return ctx.JSON(http.StatusBadRequest, ErrorCodeResponse(INVALID_TOKEN, err.Error()))
+	}
+	policyErr := &usecase.PasswordPolicyError{}
+	if errors.As(err, &policyErr) {
+		return ctx.JSON(http.StatusBadRequest, PasswordPolicyErrorResponse(policyErr.Error(), policyErr.Violations))
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	return ctx.JSON(http.StatusOK, SuccessStatusResponse())
+}
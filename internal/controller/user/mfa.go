@@ -0,0 +1,165 @@
+package user_controller
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	auth_middleware "github.com/adesupraptolaia/user_login/internal/middleware/auth"
+	"github.com/adesupraptolaia/user_login/internal/usecase"
+	"github.com/adesupraptolaia/user_login/pkg/jwt"
+	"github.com/adesupraptolaia/user_login/pkg/validator"
+	"github.com/labstack/echo/v4"
+)
+
+// EnrollMfa godoc
+// @Summary Enroll MFA
+// @Description Create a TOTP secret for the logged in user, MFA is required on login once confirmed
+// @Tags Public
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Bearer {access_token}"
+// @Success 201 {object} MfaEnrollmentResp
+// @Response 401 {object} ErrorResp
+// @Response 409 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /mfa/enroll [post]
+func (h UserHandler) EnrollMfa(ctx echo.Context) error {
+	claims := auth_middleware.GetClaims(ctx)
+
+	user, err := h.uc.GetUserByKsuid(claims.UserKsuid)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, ErrorResponse("Unauthorize"))
+	}
+
+	enrollment, err := h.mfaUC.Enroll(user)
+	if errors.Is(err, usecase.ErrMfaAlreadyEnrolled) {
+		return ctx.JSON(http.StatusConflict, ErrorResponse(err.Error()))
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	return ctx.JSON(http.StatusCreated, SuccessMfaEnrollmentResponse(enrollment))
+}
+
+// ConfirmMfa godoc
+// @Summary Confirm MFA
+// @Description Confirm the enrolled TOTP secret with a first code, the recovery codes are only shown once
+// @Tags Public
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Bearer {access_token}"
+// @Param payload body entity.MfaCodeRequest true "payload"
+// @Success 200 {object} RecoveryCodesResp
+// @Response 400 {object} ErrorResp
+// @Response 401 {object} ErrorResp
+// @Response 409 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /mfa/confirm [post]
+func (h UserHandler) ConfirmMfa(ctx echo.Context) error {
+	req := entity.MfaCodeRequest{}
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	claims := auth_middleware.GetClaims(ctx)
+
+	err := validator.ValidateStruct(req)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	recoveryCodes, err := h.mfaUC.Confirm(claims.UserKsuid, req.Code)
+	switch {
+	case errors.Is(err, usecase.ErrInvalidMfaCode):
+		return ctx.JSON(http.StatusBadRequest, ErrorCodeResponse(INVALID_MFA_CODE, err.Error()))
+	case errors.Is(err, usecase.ErrMfaNotEnrolled), errors.Is(err, usecase.ErrMfaAlreadyEnrolled):
+		return ctx.JSON(http.StatusConflict, ErrorResponse(err.Error()))
+	case err != nil:
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	return ctx.JSON(http.StatusOK, SuccessRecoveryCodesResponse(recoveryCodes))
+}
+
+// LoginMfa godoc
+// @Summary Login MFA
+// @Description Swap the mfa_token given by /login and a TOTP or recovery code for the token pair
+// @Tags Public
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Bearer {mfa_token}"
+// @Param payload body entity.MfaCodeRequest true "payload"
+// @Success 201 {object} TokenSuccessResp
+// @Response 400 {object} ErrorResp
+// @Response 401 {object} ErrorResp
+// @Response 423 {object} ErrorResp
+// @Response 429 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /login/mfa [post]
+func (h UserHandler) LoginMfa(ctx echo.Context) error {
+	req := entity.MfaCodeRequest{}
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	mfaToken, err := auth_middleware.GetBearerToken(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, ErrorResponse("Unauthorize"))
+	}
+
+	claims, err := h.tokenUC.ValidateMfaToken(mfaToken)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, ErrorResponse("Unauthorize"))
+	}
+
+	err = validator.ValidateStruct(req)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	user, err := h.uc.GetUserByKsuid(claims.UserKsuid)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, ErrorResponse("Unauthorize"))
+	}
+
+	// wrong codes count toward the same lockout as wrong passwords
+	ip := ctx.RealIP()
+	if err := h.loginAttemptUC.CheckLogin(user.Username, ip); err != nil {
+		return lockedResponse(ctx, err)
+	}
+
+	if err := h.mfaUC.Verify(user.Ksuid, req.Code); err != nil {
+		if err := h.loginAttemptUC.RecordFailedLogin(user.Username, ip); err != nil {
+			return lockedResponse(ctx, err)
+		}
+		return ctx.JSON(http.StatusUnauthorized, ErrorCodeResponse(INVALID_MFA_CODE, "invalid mfa code"))
+	}
+
+	if err := h.loginAttemptUC.RecordSuccessLogin(user.Username); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	err = h.tokenUC.UseMfaToken(claims)
+	if errors.Is(err, usecase.ErrMfaTokenUsed) {
+		return ctx.JSON(http.StatusUnauthorized, ErrorResponse("Unauthorize"))
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	token, err := h.tokenUC.CreateToken(user)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	// the first party login has no client id, the ID token is issued for auth app
+	idToken, err := jwt.CreateIDToken(user.Ksuid, user.Username, jwt.AUTH_PUBLIC_AUDIENCE, "", time.Now())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	return ctx.JSON(http.StatusCreated, SuccessTokenResponse(token.UserKsuid, token.AccessToken, token.RefreshToken, idToken))
+}
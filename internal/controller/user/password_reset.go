@@ -0,0 +1,84 @@
+package user_controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/adesupraptolaia/user_login/internal/usecase"
+	"github.com/adesupraptolaia/user_login/pkg/validator"
+	"github.com/labstack/echo/v4"
+)
+
+// ForgotPassword godoc
+// @Summary Forgot Password
+// @Description Send a single-use password reset token to the user, the response is the same whether the username exists or not
+// @Tags Public
+// @Accept  json
+// @Produce  json
+// @Param payload body entity.ForgotPasswordRequest true "payload"
+// @Success 202 {object} StatusResp
+// @Response 400 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /password/forgot [post]
+func (h UserHandler) ForgotPassword(ctx echo.Context) error {
+	req := entity.ForgotPasswordRequest{}
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	if err := h.resetUC.ForgotPassword(req.Username); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	return ctx.JSON(http.StatusAccepted, SuccessStatusResponse())
+}
+
+// ResetPassword godoc
+// @Summary Reset Password
+// @Description Set a new password with the token from /password/forgot, every session of the user is ended and the login is unlocked
+// @Tags Public
+// @Accept  json
+// @Produce  json
+// @Param payload body entity.ResetPasswordRequest true "payload"
+// @Success 200 {object} StatusResp
+// @Response 400 {object} PasswordPolicyErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /password/reset [post]
+func (h UserHandler) ResetPassword(ctx echo.Context) error {
+	req := entity.ResetPasswordRequest{}
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	user, err := h.resetUC.ResetPassword(req.Token, req.NewPassword)
+	if errors.Is(err, usecase.ErrInvalidResetToken) {
+		return ctx.JSON(http.StatusBadRequest, ErrorCodeResponse(INVALID_TOKEN, err.Error()))
+	}
+	policyErr := &usecase.PasswordPolicyError{}
+	if errors.As(err, &policyErr) {
+		return ctx.JSON(http.StatusBadRequest, PasswordPolicyErrorResponse(policyErr.Error(), policyErr.Violations))
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	if err := h.tokenUC.RevokeUserTokens(user.Ksuid); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	// the user proved the identity, a lockout from the forgotten password is lifted
+	if err := h.loginAttemptUC.Unlock(user.Username); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	return ctx.JSON(http.StatusOK, SuccessStatusResponse())
+}
@@ -11,11 +11,33 @@ type UserSuccessResp struct {
 	Data   *entity.User `json:"data"`
 }
 
+// swagger:model
+type UsersSuccessResp struct {
+	// success
+	Status string        `json:"status"`
+	Data   []entity.User `json:"data"`
+}
+
+// swagger:model
+type InvitationSuccessResp struct {
+	// success
+	Status string             `json:"status"`
+	Data   *entity.Invitation `json:"data"`
+}
+
+// swagger:model
+type InvitationsSuccessResp struct {
+	// success
+	Status string              `json:"status"`
+	Data   []entity.Invitation `json:"data"`
+}
+
 // swagger:model
 type TokenData struct {
 	Ksuid        string `json:"ksuid,omitempty"`
 	AccessToken  string `json:"access_token,omitempty"`
 	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
 }
 
 // swagger:model
@@ -25,20 +47,103 @@ type TokenSuccessResp struct {
 	Data   TokenData `json:"data"`
 }
 
+// swagger:model
+type StatusResp struct {
+	// success
+	Status string `json:"status"`
+}
+
+// swagger:model
+type MfaPendingData struct {
+	MfaToken string `json:"mfa_token"`
+}
+
+// swagger:model
+type MfaPendingResp struct {
+	// mfa_required
+	Status string         `json:"status"`
+	Data   MfaPendingData `json:"data"`
+}
+
+// swagger:model
+type MfaEnrollmentResp struct {
+	// success
+	Status string                `json:"status"`
+	Data   *entity.MfaEnrollment `json:"data"`
+}
+
+// swagger:model
+type RecoveryCodesData struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// swagger:model
+type RecoveryCodesResp struct {
+	// success
+	Status string            `json:"status"`
+	Data   RecoveryCodesData `json:"data"`
+}
+
+// error code let the client tell apart errors sharing the same status
+const (
+	ACCOUNT_LOCKED     = "account_locked"
+	TOO_MANY_ATTEMPTS  = "too_many_attempts"
+	INVALID_MFA_CODE   = "invalid_mfa_code"
+	WEAK_PASSWORD      = "weak_password"
+	INVALID_TOKEN      = "invalid_token"
+	EMAIL_NOT_VERIFIED = "email_not_verified"
+)
+
 // swagger:model
 type ErrorResp struct {
 	// error
 	Status       string `json:"status"`
+	ErrorCode    string `json:"error_code,omitempty"`
 	ErrorMessage string `json:"error_message"`
 }
 
-func SuccessTokenResponse(ksuid, accessToken, refreshToken string) TokenSuccessResp {
+// swagger:model
+type PasswordPolicyErrorResp struct {
+	// error
+	Status       string                     `json:"status"`
+	ErrorCode    string                     `json:"error_code"`
+	ErrorMessage string                     `json:"error_message"`
+	Violations   []entity.PasswordViolation `json:"violations"`
+}
+
+func SuccessTokenResponse(ksuid, accessToken, refreshToken, idToken string) TokenSuccessResp {
 	return TokenSuccessResp{
 		Status: "success",
 		Data: TokenData{
 			Ksuid:        ksuid,
 			AccessToken:  accessToken,
 			RefreshToken: refreshToken,
+			IDToken:      idToken,
+		},
+	}
+}
+
+func SuccessMfaPendingResponse(mfaToken string) MfaPendingResp {
+	return MfaPendingResp{
+		Status: "mfa_required",
+		Data: MfaPendingData{
+			MfaToken: mfaToken,
+		},
+	}
+}
+
+func SuccessMfaEnrollmentResponse(data *entity.MfaEnrollment) MfaEnrollmentResp {
+	return MfaEnrollmentResp{
+		Status: "success",
+		Data:   data,
+	}
+}
+
+func SuccessRecoveryCodesResponse(recoveryCodes []string) RecoveryCodesResp {
+	return RecoveryCodesResp{
+		Status: "success",
+		Data: RecoveryCodesData{
+			RecoveryCodes: recoveryCodes,
 		},
 	}
 }
@@ -50,9 +155,53 @@ func SuccessResponse(data *entity.User) UserSuccessResp {
 	}
 }
 
+func SuccessUsersResponse(data []entity.User) UsersSuccessResp {
+	return UsersSuccessResp{
+		Status: "success",
+		Data:   data,
+	}
+}
+
+func SuccessInvitationResponse(data *entity.Invitation) InvitationSuccessResp {
+	return InvitationSuccessResp{
+		Status: "success",
+		Data:   data,
+	}
+}
+
+func SuccessInvitationsResponse(data []entity.Invitation) InvitationsSuccessResp {
+	return InvitationsSuccessResp{
+		Status: "success",
+		Data:   data,
+	}
+}
+
+func SuccessStatusResponse() StatusResp {
+	return StatusResp{
+		Status: "success",
+	}
+}
+
 func ErrorResponse(error_message string) ErrorResp {
 	return ErrorResp{
 		Status:       "error",
 		ErrorMessage: error_message,
 	}
 }
+
+func ErrorCodeResponse(error_code, error_message string) ErrorResp {
+	return ErrorResp{
+		Status:       "error",
+		ErrorCode:    error_code,
+		ErrorMessage: error_message,
+	}
+}
+
+func PasswordPolicyErrorResponse(error_message string, violations []entity.PasswordViolation) PasswordPolicyErrorResp {
+	return PasswordPolicyErrorResp{
+		Status:       "error",
+		ErrorCode:    WEAK_PASSWORD,
+		ErrorMessage: error_message,
+		Violations:   violations,
+	}
+}
@@ -1,37 +1,58 @@
 package user_controller
 
 import (
-	"fmt"
+	"errors"
+	"math"
 	"net/http"
-	"strings"
+	"strconv"
+	"time"
 
 	"github.com/adesupraptolaia/user_login/internal/entity"
+	auth_middleware "github.com/adesupraptolaia/user_login/internal/middleware/auth"
 	"github.com/adesupraptolaia/user_login/internal/usecase"
-	"github.com/adesupraptolaia/user_login/internal/utils"
 	"github.com/adesupraptolaia/user_login/pkg/jwt"
 	"github.com/adesupraptolaia/user_login/pkg/validator"
 	"github.com/labstack/echo/v4"
 )
 
 type UserHandler struct {
-	uc usecase.UserUC
+	uc             usecase.UserUC
+	tokenUC        usecase.TokenUC
+	loginAttemptUC usecase.LoginAttemptUC
+	mfaUC          usecase.MfaUC
+	resetUC        usecase.PasswordResetUC
+	emailUC        usecase.EmailVerificationUC
+	invitationUC   usecase.InvitationUC
 }
 
-func NewUserHandler(uc usecase.UserUC) UserHandler {
+func NewUserHandler(uc usecase.UserUC, tokenUC usecase.TokenUC, loginAttemptUC usecase.LoginAttemptUC, mfaUC usecase.MfaUC, resetUC usecase.PasswordResetUC, emailUC usecase.EmailVerificationUC, invitationUC usecase.InvitationUC) UserHandler {
 	return UserHandler{
-		uc: uc,
+		uc:             uc,
+		tokenUC:        tokenUC,
+		loginAttemptUC: loginAttemptUC,
+		mfaUC:          mfaUC,
+		resetUC:        resetUC,
+		emailUC:        emailUC,
+		invitationUC:   invitationUC,
 	}
 }
 
 // Login godoc
 // @Summary Login
-// @Description Login using username and password
+// @Description Login using username and password, the account is locked for a while after too many failed attempts.
+// @Description When the user has enrolled MFA, a mfa_token is returned instead, to be used on /login/mfa.
+// @Description A verified email address may be required by config
 // @Tags Public
 // @Accept  json
 // @Produce  json
 // @Param payload body entity.UserRequest true "payload"
-// @Success 200 {object} TokenSuccessResp
+// @Success 200 {object} MfaPendingResp
+// @Success 201 {object} TokenSuccessResp
 // @Response 400 {object} ErrorResp
+// @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 423 {object} ErrorResp
+// @Response 429 {object} ErrorResp
 // @Response 500 {object} ErrorResp
 // @Router /login [post]
 func (h UserHandler) Login(ctx echo.Context) error {
@@ -40,32 +61,58 @@ func (h UserHandler) Login(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
 	}
 
-	user, err := h.uc.GetUserByUsername(req.Username)
+	ip := ctx.RealIP()
+	if err := h.loginAttemptUC.CheckLogin(req.Username, ip); err != nil {
+		return lockedResponse(ctx, err)
+	}
+
+	// unknown username counts as a failed attempt too
+	user, err := h.uc.Login(req.Username, req.Password)
 	if err != nil {
+		if err := h.loginAttemptUC.RecordFailedLogin(req.Username, ip); err != nil {
+			return lockedResponse(ctx, err)
+		}
+		return ctx.JSON(http.StatusUnauthorized, ErrorResponse("wrong username or password"))
+	}
+
+	if err := h.loginAttemptUC.RecordSuccessLogin(req.Username); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
 	}
 
-	// check password
-	if !utils.IsPasswordMatch(user.Password, req.Password) {
-		return ctx.JSON(http.StatusUnauthorized, ErrorResponse("wrong username or password"))
+	if err := h.emailUC.CheckLogin(user); err != nil {
+		return ctx.JSON(http.StatusForbidden, ErrorCodeResponse(EMAIL_NOT_VERIFIED, err.Error()))
+	}
+
+	enrolled, err := h.mfaUC.IsEnrolled(user.Ksuid)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	if enrolled {
+		mfaToken, err := jwt.CreateMfaToken(user.Ksuid, user.Role)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+		}
+		return ctx.JSON(http.StatusOK, SuccessMfaPendingResponse(mfaToken))
 	}
 
-	accessToken, err := jwt.CreateAccessToken(user.Ksuid, user.Role)
+	token, err := h.tokenUC.CreateToken(user)
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
 	}
 
-	refreshToken, err := jwt.CreateRefreshToken(user.Ksuid, user.Role)
+	// the first party login has no client id, the ID token is issued for auth app
+	idToken, err := jwt.CreateIDToken(user.Ksuid, user.Username, jwt.AUTH_PUBLIC_AUDIENCE, "", time.Now())
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
 	}
 
-	return ctx.JSON(http.StatusCreated, SuccessTokenResponse(user.Ksuid, accessToken, refreshToken))
+	return ctx.JSON(http.StatusCreated, SuccessTokenResponse(token.UserKsuid, token.AccessToken, token.RefreshToken, idToken))
 }
 
 // RefreshAccessToken godoc
 // @Summary Refresh Token
-// @Description Refresh AccessToken
+// @Description Refresh AccessToken, the refresh token is rotated and can't be used again
 // @Tags Public
 // @Accept json
 // @Produce json
@@ -75,40 +122,135 @@ func (h UserHandler) Login(ctx echo.Context) error {
 // @Response 500 {object} ErrorResp
 // @Router /refresh [get]
 func (h UserHandler) RefreshToken(ctx echo.Context) error {
-	refreshToken, err := getBearerToken(ctx.Request().Header.Get("Authorization"))
+	refreshToken, err := auth_middleware.GetBearerToken(ctx)
 	if err != nil {
 		return ctx.JSON(http.StatusUnauthorized, ErrorResponse("Unauthorize"))
 	}
 
-	claims, err := jwt.ValidateRefreshToken(refreshToken)
+	token, err := h.tokenUC.RefreshToken(refreshToken, "")
+	if errors.Is(err, usecase.ErrInvalidRefreshToken) {
+		return ctx.JSON(http.StatusUnauthorized, ErrorResponse("Unauthorize"))
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	return ctx.JSON(http.StatusOK, SuccessTokenResponse(token.UserKsuid, token.AccessToken, token.RefreshToken, ""))
+}
+
+// Logout godoc
+// @Summary Logout
+// @Description End the session of the given refresh token
+// @Tags Public
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {refresh_token}"
+// @Success 200 {object} StatusResp
+// @Response 401 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /logout [post]
+func (h UserHandler) Logout(ctx echo.Context) error {
+	refreshToken, err := auth_middleware.GetBearerToken(ctx)
 	if err != nil {
 		return ctx.JSON(http.StatusUnauthorized, ErrorResponse("Unauthorize"))
 	}
 
-	user, err := h.uc.GetUserByKsuid(claims.UserKsuid)
-	if err != nil || user.Role != claims.Role {
+	err = h.tokenUC.Logout(refreshToken)
+	if errors.Is(err, usecase.ErrInvalidRefreshToken) {
 		return ctx.JSON(http.StatusUnauthorized, ErrorResponse("Unauthorize"))
 	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	return ctx.JSON(http.StatusOK, SuccessStatusResponse())
+}
+
+// LogoutAll godoc
+// @Summary Logout Everywhere
+// @Description End every session of the logged in user
+// @Tags Public
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {access_token}"
+// @Success 200 {object} StatusResp
+// @Response 401 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /logout/all [post]
+func (h UserHandler) LogoutAll(ctx echo.Context) error {
+	claims := auth_middleware.GetClaims(ctx)
+
+	if err := h.tokenUC.RevokeUserTokens(claims.UserKsuid); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	return ctx.JSON(http.StatusOK, SuccessStatusResponse())
+}
+
+// ChangePassword godoc
+// @Summary Change Password
+// @Description Change password of the logged in user, refresh tokens issued before the change are invalidated
+// @Tags Public
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Bearer {access_token}"
+// @Param payload body entity.ChangePasswordRequest true "payload"
+// @Success 200 {object} TokenSuccessResp
+// @Response 400 {object} PasswordPolicyErrorResp
+// @Response 401 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /password [post]
+func (h UserHandler) ChangePassword(ctx echo.Context) error {
+	req := entity.ChangePasswordRequest{}
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
 
-	accessToken, err := jwt.CreateAccessToken(user.Ksuid, user.Role)
+	claims := auth_middleware.GetClaims(ctx)
+
+	err := validator.ValidateStruct(req)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	user, err := h.uc.ChangePassword(claims.UserKsuid, req)
+	if errors.Is(err, usecase.ErrWrongPassword) {
+		return ctx.JSON(http.StatusUnauthorized, ErrorResponse("wrong old password"))
+	}
+	policyErr := &usecase.PasswordPolicyError{}
+	if errors.As(err, &policyErr) {
+		return ctx.JSON(http.StatusBadRequest, PasswordPolicyErrorResponse(policyErr.Error(), policyErr.Violations))
+	}
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
 	}
 
-	return ctx.JSON(http.StatusOK, SuccessTokenResponse(user.Ksuid, accessToken, refreshToken))
+	if err = h.tokenUC.RevokeUserTokens(user.Ksuid); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	// the refresh tokens issued before are invalid now, give the user a new pair
+	token, err := h.tokenUC.CreateToken(user)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	return ctx.JSON(http.StatusOK, SuccessTokenResponse(token.UserKsuid, token.AccessToken, token.RefreshToken, ""))
 }
 
 // CreateUser godoc
 // @Summary Create new User
-// @Description Only admin can create new user
+// @Description Requires a service token with permission user:create. A verification link is sent to the email address, if any
 // @Tags Private
 // @Accept  json
 // @Produce  json
 // @Param Authorization header string true "Bearer {token}"
 // @Param payload body entity.UserRequest true "Request Payload"
 // @Success 201 {object} UserSuccessResp
-// @Response 400 {object} ErrorResp
+// @Response 400 {object} PasswordPolicyErrorResp
 // @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 409 {object} ErrorResp
 // @Response 500 {object} ErrorResp
 // @Router /user/create [post]
 func (h UserHandler) CreateUser(ctx echo.Context) error {
@@ -118,34 +260,72 @@ func (h UserHandler) CreateUser(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
 	}
 
-	accessToken, err := getBearerToken(ctx.Request().Header.Get("Authorization"))
+	err = validator.ValidateStruct(userProfile)
 	if err != nil {
-		return ctx.JSON(http.StatusUnauthorized, ErrorResponse("Unauthorize"))
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
 	}
 
-	err = jwt.IsAdmin(accessToken)
+	newUser, err := h.uc.CreateUser(userProfile)
+	policyErr := &usecase.PasswordPolicyError{}
+	if errors.As(err, &policyErr) {
+		return ctx.JSON(http.StatusBadRequest, PasswordPolicyErrorResponse(policyErr.Error(), policyErr.Violations))
+	}
+	if errors.Is(err, usecase.ErrUserAlreadyExists) {
+		return ctx.JSON(http.StatusConflict, ErrorResponse(err.Error()))
+	}
 	if err != nil {
-		return ctx.JSON(http.StatusUnauthorized, ErrorResponse("Unauthorize"))
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
 	}
 
-	err = validator.ValidateStruct(userProfile)
-	if err != nil {
+	// the user can ask for another one through /email/verify/send
+	if err := h.emailUC.SendVerification(newUser); err != nil {
+		ctx.Logger().Errorf("error when send email verification, err: %s", err.Error())
+	}
+
+	newUser.Password = ""
+
+	return ctx.JSON(http.StatusCreated, SuccessResponse(newUser))
+}
+
+// LookupUsers godoc
+// @Summary Lookup Users
+// @Description Requires a service token with permission user:read. Get up to 100 users by ksuid at once, the unknown ksuids are left out, and the deleted ones unless include_deleted
+// @Tags Private
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}"
+// @Param payload body entity.UsersLookupRequest true "Request Payload"
+// @Success 200 {object} UsersSuccessResp
+// @Response 400 {object} ErrorResp
+// @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /users/lookup [post]
+func (h UserHandler) LookupUsers(ctx echo.Context) error {
+	req := entity.UsersLookupRequest{}
+	if err := ctx.Bind(&req); err != nil {
 		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
 	}
 
-	newUser, err := h.uc.CreateUser(userProfile)
+	if err := validator.ValidateStruct(req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	users, err := h.uc.GetUsersByKsuids(req.Ksuids, req.IncludeDeleted)
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
 	}
 
-	newUser.Password = ""
+	for i := range users {
+		users[i].Password = ""
+	}
 
-	return ctx.JSON(http.StatusCreated, SuccessResponse(newUser))
+	return ctx.JSON(http.StatusOK, SuccessUsersResponse(users))
 }
 
 // DeleteUser godoc
 // @Summary Delete User
-// @Description Only admin can delete user
+// @Description Requires a service token with permission user:delete. The user is marked deleted, it can be restored until it is purged
 // @Tags Private
 // @Accept  json
 // @Produce  json
@@ -153,41 +333,161 @@ func (h UserHandler) CreateUser(ctx echo.Context) error {
 // @Param Authorization header string true "Bearer {token}"
 // @Success 200 {object} UserSuccessResp
 // @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 404 {object} ErrorResp
 // @Response 500 {object} ErrorResp
 // @Router /user/{user_ksuid} [delete]
 func (h UserHandler) DeleteUser(ctx echo.Context) error {
 	ksuid := ctx.Param("ksuid")
 
-	accessToken, err := getBearerToken(ctx.Request().Header.Get("Authorization"))
+	deletedUser, err := h.uc.DeleteUser(ksuid)
+	if errors.Is(err, usecase.ErrUserNotFound) {
+		return ctx.JSON(http.StatusNotFound, ErrorResponse(err.Error()))
+	}
+	if errors.Is(err, usecase.ErrUserNotDeletable) {
+		return ctx.JSON(http.StatusForbidden, ErrorResponse(err.Error()))
+	}
 	if err != nil {
-		return ctx.JSON(http.StatusUnauthorized, ErrorResponse("Unauthorize"))
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
 	}
 
-	err = jwt.IsAdmin(accessToken)
+	deletedUser.Password = ""
+
+	return ctx.JSON(http.StatusOK, SuccessResponse(deletedUser))
+}
+
+// RestoreUser godoc
+// @Summary Restore User
+// @Description Requires a service token with permission user:delete. Undo the deletion of a user not purged yet
+// @Tags Private
+// @Accept  json
+// @Produce  json
+// @Param ksuid path string true "Ksuid of User"
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200 {object} UserSuccessResp
+// @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 404 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /user/{ksuid}/restore [post]
+func (h UserHandler) RestoreUser(ctx echo.Context) error {
+	user, err := h.uc.RestoreUser(ctx.Param("ksuid"))
+	if errors.Is(err, usecase.ErrUserNotDeleted) {
+		return ctx.JSON(http.StatusNotFound, ErrorResponse(err.Error()))
+	}
 	if err != nil {
-		return ctx.JSON(http.StatusUnauthorized, ErrorResponse("Unauthorize"))
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
 	}
 
-	deletedUser, err := h.uc.DeleteUser(ksuid)
+	user.Password = ""
+
+	return ctx.JSON(http.StatusOK, SuccessResponse(user))
+}
+
+// PurgeUser godoc
+// @Summary Purge User
+// @Description Requires a service token with permission user:delete. Permanently delete a deleted user
+// @Tags Private
+// @Accept  json
+// @Produce  json
+// @Param ksuid path string true "Ksuid of User"
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200 {object} StatusResp
+// @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 404 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /user/{ksuid}/purge [delete]
+func (h UserHandler) PurgeUser(ctx echo.Context) error {
+	err := h.uc.PurgeUser(ctx.Param("ksuid"))
+	if errors.Is(err, usecase.ErrUserNotDeleted) {
+		return ctx.JSON(http.StatusNotFound, ErrorResponse(err.Error()))
+	}
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
 	}
 
-	deletedUser.Password = ""
+	return ctx.JSON(http.StatusOK, SuccessStatusResponse())
+}
 
-	return ctx.JSON(http.StatusOK, SuccessResponse(deletedUser))
+// ForceLogout godoc
+// @Summary Force Logout User
+// @Description Requires permission user:logout. End every session of a user
+// @Tags Private
+// @Accept  json
+// @Produce  json
+// @Param ksuid path string true "Ksuid of User"
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200 {object} StatusResp
+// @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 404 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /user/{ksuid}/logout [post]
+func (h UserHandler) ForceLogout(ctx echo.Context) error {
+	ksuid := ctx.Param("ksuid")
+
+	_, err := h.uc.GetUserByKsuid(ksuid)
+	if errors.Is(err, usecase.ErrUserNotFound) {
+		return ctx.JSON(http.StatusNotFound, ErrorResponse(err.Error()))
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	if err := h.tokenUC.RevokeUserTokens(ksuid); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	return ctx.JSON(http.StatusOK, SuccessStatusResponse())
 }
 
-func getBearerToken(auth string) (string, error) {
-	if !strings.HasPrefix(auth, "Bearer ") {
-		return "", fmt.Errorf("missing bearer token")
+// UnlockUser godoc
+// @Summary Unlock User
+// @Description Requires permission user:unlock. Unlock a user locked out after too many failed logins
+// @Tags Private
+// @Accept  json
+// @Produce  json
+// @Param ksuid path string true "Ksuid of User"
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200 {object} StatusResp
+// @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 404 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /user/{ksuid}/unlock [post]
+func (h UserHandler) UnlockUser(ctx echo.Context) error {
+	ksuid := ctx.Param("ksuid")
+
+	user, err := h.uc.GetUserByKsuid(ksuid)
+	if errors.Is(err, usecase.ErrUserNotFound) {
+		return ctx.JSON(http.StatusNotFound, ErrorResponse(err.Error()))
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	if err = h.loginAttemptUC.Unlock(user.Username); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
 	}
 
-	token := strings.Replace(auth, "Bearer ", "", -1)
+	return ctx.JSON(http.StatusOK, SuccessStatusResponse())
+}
+
+// lockedResponse tell the client how long to wait when the login is locked
+func lockedResponse(ctx echo.Context, err error) error {
+	lockedErr := &usecase.LockedError{}
+	if errors.As(err, &lockedErr) {
+		retryAfter := int(math.Ceil(time.Until(lockedErr.Until).Seconds()))
+		ctx.Response().Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	}
 
-	if token == "" {
-		return "", fmt.Errorf("missing bearer token")
+	switch {
+	case errors.Is(err, usecase.ErrAccountLocked):
+		return ctx.JSON(http.StatusLocked, ErrorCodeResponse(ACCOUNT_LOCKED, err.Error()))
+	case errors.Is(err, usecase.ErrTooManyAttempts):
+		return ctx.JSON(http.StatusTooManyRequests, ErrorCodeResponse(TOO_MANY_ATTEMPTS, err.Error()))
 	}
 
-	return token, nil
+	return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
 }
@@ -0,0 +1,60 @@
+package user_profile_controller
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	errMissingIfMatch = errors.New("If-Match header with the ETag of the profile is required")
+	errInvalidIfMatch = errors.New("If-Match header doesn't match the ETag of the profile")
+)
+
+// profileETag is the ETag of the version of a profile
+func profileETag(version int) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// ifMatchVersion return the version of the profile in the If-Match header,
+// which must be a single strong ETag given by GET /user/:user_ksuid
+func ifMatchVersion(ctx echo.Context) (int, error) {
+	ifMatch := strings.TrimSpace(ctx.Request().Header.Get("If-Match"))
+	if ifMatch == "" {
+		return 0, errMissingIfMatch
+	}
+
+	if len(ifMatch) < 2 || !strings.HasPrefix(ifMatch, `"`) || !strings.HasSuffix(ifMatch, `"`) {
+		return 0, errInvalidIfMatch
+	}
+
+	version, err := strconv.Atoi(ifMatch[1 : len(ifMatch)-1])
+	if err != nil {
+		return 0, errInvalidIfMatch
+	}
+
+	return version, nil
+}
+
+// noneMatch report whether the If-None-Match header doesn't list the ETag, weak ETags match their strong one
+func noneMatch(ctx echo.Context, etag string) bool {
+	ifNoneMatch := strings.TrimSpace(ctx.Request().Header.Get("If-None-Match"))
+	if ifNoneMatch == "" {
+		return true
+	}
+
+	if ifNoneMatch == "*" {
+		return false
+	}
+
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == etag {
+			return false
+		}
+	}
+
+	return true
+}
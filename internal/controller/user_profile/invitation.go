@@ -0,0 +1,96 @@
+package user_profile_controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/adesupraptolaia/user_login/internal/usecase"
+	"github.com/adesupraptolaia/user_login/pkg/validator"
+	"github.com/labstack/echo/v4"
+)
+
+// InviteUser godoc
+// @Summary Invite a new User
+// @Description Requires permission profile:create. The user and its profile are created without password,
+// @Description the invite_token is given to the user to choose one on /invite/accept of auth app
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}"
+// @Param payload body entity.InviteUserProfileRequest true "Request Payload"
+// @Success 201 {object} InvitationSuccessResp
+// @Response 400 {object} ErrorResp
+// @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 409 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /invite [post]
+func (h userProfileHandler) InviteUser(ctx echo.Context) error {
+	req := entity.InviteUserProfileRequest{}
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	err := validator.ValidateStruct(req)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	invitation, err := h.uc.InviteUserProfile(req)
+	if errors.Is(err, usecase.ErrUserAlreadyExists) {
+		return ctx.JSON(http.StatusConflict, ErrorResponse(err.Error()))
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	return ctx.JSON(http.StatusCreated, SuccessInvitationResponse(invitation))
+}
+
+// GetInvitations godoc
+// @Summary Get pending Invitations
+// @Description Requires permission profile:create. Invited users who have not chosen a password yet, the expired ones included
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200 {object} InvitationsSuccessResp
+// @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /invites [get]
+func (h userProfileHandler) GetInvitations(ctx echo.Context) error {
+	invitations, err := h.uc.GetInvitations()
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	return ctx.JSON(http.StatusOK, SuccessInvitationsResponse(invitations))
+}
+
+// RevokeInvitation godoc
+// @Summary Revoke Invitation
+// @Description Requires permission profile:delete. The invited user and its profile are deleted, unless the invitation is already accepted
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Param user_ksuid path string true "Ksuid of User"
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200 {object} SuccessResp
+// @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 404 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /invite/{user_ksuid} [delete]
+func (h userProfileHandler) RevokeInvitation(ctx echo.Context) error {
+	deletedUser, err := h.uc.RevokeInvitation(ctx.Param("user_ksuid"))
+	if errors.Is(err, usecase.ErrInvitationNotFound) {
+		return ctx.JSON(http.StatusNotFound, ErrorResponse(err.Error()))
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	return ctx.JSON(http.StatusOK, SuccessResponse(deletedUser))
+}
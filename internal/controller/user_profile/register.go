@@ -0,0 +1,49 @@
+package user_profile_controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/adesupraptolaia/user_login/internal/usecase"
+	"github.com/adesupraptolaia/user_login/pkg/validator"
+	"github.com/labstack/echo/v4"
+)
+
+// Register godoc
+// @Summary Register a new User
+// @Description Create the user and its profile without an admin, only when registration is enabled. The invite code or email domain is checked when configured
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Param payload body entity.RegisterRequest true "Request Payload"
+// @Success 201 {object} SuccessResp
+// @Response 400 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 429 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /register [post]
+func (h userProfileHandler) Register(ctx echo.Context) error {
+	req := entity.RegisterRequest{}
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	err := validator.ValidateStruct(req)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	newUser, err := h.registrationUC.Register(req)
+	if errors.Is(err, usecase.ErrRegistrationDisabled) || errors.Is(err, usecase.ErrInvalidInviteCode) || errors.Is(err, usecase.ErrEmailDomainNotAllowed) {
+		return ctx.JSON(http.StatusForbidden, ErrorResponse(err.Error()))
+	}
+	if errors.Is(err, usecase.ErrRegistrationRejected) {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	return ctx.JSON(http.StatusCreated, SuccessResponse(newUser))
+}
@@ -1,7 +1,10 @@
 package user_profile_controller
 
 import (
+	"fmt"
+
 	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/adesupraptolaia/user_login/internal/usecase"
 )
 
 // swagger:model
@@ -11,6 +14,27 @@ type SuccessResp struct {
 	Data   entity.UserProfile `json:"data"`
 }
 
+// swagger:model
+type UserListSuccessResp struct {
+	// success
+	Status string           `json:"status"`
+	Data   *entity.UserList `json:"data"`
+}
+
+// swagger:model
+type InvitationSuccessResp struct {
+	// success
+	Status string             `json:"status"`
+	Data   *entity.Invitation `json:"data"`
+}
+
+// swagger:model
+type InvitationsSuccessResp struct {
+	// success
+	Status string              `json:"status"`
+	Data   []entity.Invitation `json:"data"`
+}
+
 // swagger:model
 type ErrorResp struct {
 	// error
@@ -18,6 +42,15 @@ type ErrorResp struct {
 	ErrorMessage string `json:"error_message"`
 }
 
+// swagger:model
+type FieldErrorResp struct {
+	// error
+	Status       string `json:"status"`
+	ErrorMessage string `json:"error_message"`
+	// reason by refused field
+	Fields map[string]string `json:"fields"`
+}
+
 func SuccessResponse(data *entity.UserProfile) SuccessResp {
 	return SuccessResp{
 		Status: "success",
@@ -25,9 +58,43 @@ func SuccessResponse(data *entity.UserProfile) SuccessResp {
 	}
 }
 
+func SuccessUserListResponse(data *entity.UserList) UserListSuccessResp {
+	return UserListSuccessResp{
+		Status: "success",
+		Data:   data,
+	}
+}
+
+func SuccessInvitationResponse(data *entity.Invitation) InvitationSuccessResp {
+	return InvitationSuccessResp{
+		Status: "success",
+		Data:   data,
+	}
+}
+
+func SuccessInvitationsResponse(data []entity.Invitation) InvitationsSuccessResp {
+	return InvitationsSuccessResp{
+		Status: "success",
+		Data:   data,
+	}
+}
+
 func ErrorResponse(error_message string) ErrorResp {
 	return ErrorResp{
 		Status:       "error",
 		ErrorMessage: error_message,
 	}
 }
+
+func FieldErrorResponse(err *usecase.FieldPermissionError) FieldErrorResp {
+	fields := map[string]string{}
+	for _, field := range err.Fields {
+		fields[field] = fmt.Sprintf("can't be changed by role %s", err.Role)
+	}
+
+	return FieldErrorResp{
+		Status:       "error",
+		ErrorMessage: err.Error(),
+		Fields:       fields,
+	}
+}
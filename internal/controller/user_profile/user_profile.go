@@ -1,24 +1,28 @@
 package user_profile_controller
 
 import (
-	"fmt"
+	"errors"
+	"io"
+	"mime"
 	"net/http"
-	"strings"
 
 	"github.com/adesupraptolaia/user_login/internal/entity"
+	auth_middleware "github.com/adesupraptolaia/user_login/internal/middleware/auth"
+	"github.com/adesupraptolaia/user_login/internal/repo"
 	"github.com/adesupraptolaia/user_login/internal/usecase"
-	"github.com/adesupraptolaia/user_login/pkg/jwt"
 	"github.com/adesupraptolaia/user_login/pkg/validator"
 	"github.com/labstack/echo/v4"
 )
 
 type userProfileHandler struct {
-	uc usecase.UserProfileUC
+	uc             usecase.UserProfileUC
+	registrationUC usecase.RegistrationUC
 }
 
-func NewUserProfileHandler(uc usecase.UserProfileUC) userProfileHandler {
+func NewUserProfileHandler(uc usecase.UserProfileUC, registrationUC usecase.RegistrationUC) userProfileHandler {
 	return userProfileHandler{
-		uc: uc,
+		uc:             uc,
+		registrationUC: registrationUC,
 	}
 }
 
@@ -30,34 +34,78 @@ func NewUserProfileHandler(uc usecase.UserProfileUC) userProfileHandler {
 // @Produce  json
 // @Param user_ksuid path string true "Ksuid of User"
 // @Param Authorization header string true "Bearer {token}"
+// @Param If-None-Match header string false "ETag of the profile already got"
 // @Success 200 {object} SuccessResp
+// @Header 200 {string} ETag "Version of the profile, the If-Match of the updates"
+// @Success 304 "The profile is still at the ETag of If-None-Match"
 // @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
 // @Response 500 {object} ErrorResp
 // @Router /user/{user_ksuid} [get]
 func (h userProfileHandler) GetUser(ctx echo.Context) error {
 	userKsuid := ctx.Param("user_ksuid")
 
-	accessToken, err := getBearerToken(ctx.Request().Header.Get("Authorization"))
+	newUser, err := h.uc.GetUserProfile(userKsuid)
 	if err != nil {
-		return ctx.JSON(http.StatusUnauthorized, ErrorResponse("Unauthorize"))
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
 	}
 
-	// Admin and User can access this API
-	if err = jwt.ValidateAccessToken(accessToken, userKsuid); err != nil {
-		return ctx.JSON(http.StatusUnauthorized, ErrorResponse("Unauthorize"))
+	etag := profileETag(newUser.Version)
+	ctx.Response().Header().Set("ETag", etag)
+
+	if !noneMatch(ctx, etag) {
+		return ctx.NoContent(http.StatusNotModified)
 	}
 
-	newUser, err := h.uc.GetUserProfile(userKsuid)
+	return ctx.JSON(http.StatusOK, SuccessResponse(newUser))
+}
+
+// GetUsers godoc
+// @Summary List Users
+// @Description Requires permission profile:read:any. The users are paged by cursor, 20 per page unless limit is given
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Bearer {token}"
+// @Param name query string false "Substring of the name"
+// @Param address query string false "Address"
+// @Param date_of_birth_from query string false "Born on or after, 2006-01-02"
+// @Param date_of_birth_to query string false "Born on or before, 2006-01-02"
+// @Param role query string false "Role"
+// @Param sort query string false "user_ksuid, name or date_of_birth, descending when prefixed by -"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Users per page, up to 100"
+// @Success 200 {object} UserListSuccessResp
+// @Response 400 {object} ErrorResp
+// @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /users [get]
+func (h userProfileHandler) GetUsers(ctx echo.Context) error {
+	req := entity.UserListRequest{}
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	err := validator.ValidateStruct(req)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	users, err := h.uc.GetUserProfiles(req)
+	if errors.Is(err, usecase.ErrInvalidCursor) {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
 	}
 
-	return ctx.JSON(http.StatusOK, SuccessResponse(newUser))
+	return ctx.JSON(http.StatusOK, SuccessUserListResponse(users))
 }
 
 // CreateUser godoc
 // @Summary Create New User
-// @Description Only admin can create new user
+// @Description Requires permission profile:create
 // @Tags users
 // @Accept  json
 // @Produce  json
@@ -66,6 +114,8 @@ func (h userProfileHandler) GetUser(ctx echo.Context) error {
 // @Success 201 {object} SuccessResp
 // @Response 400 {object} ErrorResp
 // @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 409 {object} ErrorResp
 // @Response 500 {object} ErrorResp
 // @Router /user/create [post]
 func (h userProfileHandler) CreateUser(ctx echo.Context) error {
@@ -74,21 +124,17 @@ func (h userProfileHandler) CreateUser(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
 	}
 
-	accessToken, err := getBearerToken(ctx.Request().Header.Get("Authorization"))
-	if err != nil {
-		return ctx.JSON(http.StatusUnauthorized, ErrorResponse("Unauthorize"))
-	}
-
-	if err = jwt.IsAdmin(accessToken); err != nil {
-		return ctx.JSON(http.StatusUnauthorized, ErrorResponse("Unauthorize"))
-	}
-
-	err = validator.ValidateStruct(userProfile)
+	err := validator.ValidateStruct(userProfile)
 	if err != nil {
 		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
 	}
 
 	newUser, err := h.uc.CreateUserProfile(userProfile)
+	// auth service refused the user, e.g. 400 for a weak password or 409 for a username already taken
+	authErr := &repo.AuthError{}
+	if errors.As(err, &authErr) && (authErr.StatusCode == http.StatusBadRequest || authErr.StatusCode == http.StatusConflict) {
+		return ctx.JSON(authErr.StatusCode, ErrorResponse(authErr.Message))
+	}
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
 	}
@@ -98,16 +144,22 @@ func (h userProfileHandler) CreateUser(ctx echo.Context) error {
 
 // UpdateUser godoc
 // @Summary Update User Profile
-// @Description Only admin can update user profile
+// @Description Requires permission profile:update:any, or profile:update:self on the own profile,
+// @Description then only the fields editable by the role can be different from the saved ones
 // @Tags users
 // @Accept  json
 // @Produce  json
 // @Param user_ksuid path string true "Ksuid of User"
 // @Param Authorization header string true "Bearer {token}"
+// @Param If-Match header string true "ETag of the profile the update is based on"
 // @Param payload body entity.UserProfile true "Request Payload"
 // @Success 200 {object} SuccessResp
+// @Header 200 {string} ETag "New version of the profile"
 // @Response 400 {object} ErrorResp
 // @Response 401 {object} ErrorResp
+// @Response 403 {object} FieldErrorResp
+// @Response 412 {object} ErrorResp
+// @Response 428 {object} ErrorResp
 // @Response 500 {object} ErrorResp
 // @Router /user/{user_ksuid}/update [post]
 func (h userProfileHandler) UpdateUser(ctx echo.Context) error {
@@ -118,31 +170,117 @@ func (h userProfileHandler) UpdateUser(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
 	}
 
-	accessToken, err := getBearerToken(ctx.Request().Header.Get("Authorization"))
+	err := validator.ValidateStruct(userProfile)
 	if err != nil {
-		return ctx.JSON(http.StatusUnauthorized, ErrorResponse("Unauthorize"))
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
 	}
 
-	if err = jwt.IsAdmin(accessToken); err != nil {
-		return ctx.JSON(http.StatusUnauthorized, ErrorResponse("Unauthorize"))
+	userProfile.Version, err = ifMatchVersion(ctx)
+	if errors.Is(err, errMissingIfMatch) {
+		return ctx.JSON(http.StatusPreconditionRequired, ErrorResponse(err.Error()))
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusPreconditionFailed, ErrorResponse(err.Error()))
 	}
 
-	err = validator.ValidateStruct(userProfile)
+	var updatedUser *entity.UserProfile
+	if claims := auth_middleware.GetClaims(ctx); claims.HasPermission(entity.PERM_PROFILE_UPDATE) {
+		updatedUser, err = h.uc.UpdateUserProfile(userKsuid, userProfile)
+	} else {
+		updatedUser, err = h.uc.UpdateOwnUserProfile(userKsuid, claims.Role, userProfile)
+	}
+	fieldErr := &usecase.FieldPermissionError{}
+	if errors.As(err, &fieldErr) {
+		return ctx.JSON(http.StatusForbidden, FieldErrorResponse(fieldErr))
+	}
+	if errors.Is(err, usecase.ErrVersionMismatch) {
+		return ctx.JSON(http.StatusPreconditionFailed, ErrorResponse(err.Error()))
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
+	}
+
+	ctx.Response().Header().Set("ETag", profileETag(updatedUser.Version))
+
+	return ctx.JSON(http.StatusOK, SuccessResponse(updatedUser))
+}
+
+// PatchUser godoc
+// @Summary Patch User Profile
+// @Description Requires permission profile:update:any, or profile:update:self on the own profile to change the fields editable by the role. Change only some fields with a JSON Merge Patch (RFC 7396),
+// @Description or a JSON Patch (RFC 6902) by its content type. A plain JSON body is a merge patch. Only the changed fields are validated
+// @Tags users
+// @Accept  application/merge-patch+json,application/json-patch+json,json
+// @Produce  json
+// @Param user_ksuid path string true "Ksuid of User"
+// @Param Authorization header string true "Bearer {token}"
+// @Param If-Match header string true "ETag of the profile the patch is based on"
+// @Param payload body object true "Merge patch object, or array of patch operations"
+// @Success 200 {object} SuccessResp
+// @Header 200 {string} ETag "New version of the profile"
+// @Response 400 {object} ErrorResp
+// @Response 401 {object} ErrorResp
+// @Response 403 {object} FieldErrorResp
+// @Response 412 {object} ErrorResp
+// @Response 415 {object} ErrorResp
+// @Response 428 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /user/{user_ksuid} [patch]
+func (h userProfileHandler) PatchUser(ctx echo.Context) error {
+	userKsuid := ctx.Param("user_ksuid")
+
+	contentType, _, err := mime.ParseMediaType(ctx.Request().Header.Get(echo.HeaderContentType))
+	if err != nil {
+		return ctx.JSON(http.StatusUnsupportedMediaType, ErrorResponse(usecase.ErrUnsupportedPatch.Error()))
+	}
+	if contentType == echo.MIMEApplicationJSON {
+		contentType = entity.MERGE_PATCH
+	}
+
+	patch, err := io.ReadAll(ctx.Request().Body)
 	if err != nil {
 		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
 	}
 
-	updatedUser, err := h.uc.UpdateUserProfile(userKsuid, userProfile)
+	version, err := ifMatchVersion(ctx)
+	if errors.Is(err, errMissingIfMatch) {
+		return ctx.JSON(http.StatusPreconditionRequired, ErrorResponse(err.Error()))
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusPreconditionFailed, ErrorResponse(err.Error()))
+	}
+
+	var updatedUser *entity.UserProfile
+	if claims := auth_middleware.GetClaims(ctx); claims.HasPermission(entity.PERM_PROFILE_UPDATE) {
+		updatedUser, err = h.uc.PatchUserProfile(userKsuid, version, contentType, patch)
+	} else {
+		updatedUser, err = h.uc.PatchOwnUserProfile(userKsuid, version, claims.Role, contentType, patch)
+	}
+	fieldErr := &usecase.FieldPermissionError{}
+	if errors.As(err, &fieldErr) {
+		return ctx.JSON(http.StatusForbidden, FieldErrorResponse(fieldErr))
+	}
+	if errors.Is(err, usecase.ErrVersionMismatch) {
+		return ctx.JSON(http.StatusPreconditionFailed, ErrorResponse(err.Error()))
+	}
+	if errors.Is(err, usecase.ErrUnsupportedPatch) {
+		return ctx.JSON(http.StatusUnsupportedMediaType, ErrorResponse(err.Error()))
+	}
+	if errors.Is(err, usecase.ErrInvalidPatch) {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
 	}
 
+	ctx.Response().Header().Set("ETag", profileETag(updatedUser.Version))
+
 	return ctx.JSON(http.StatusOK, SuccessResponse(updatedUser))
 }
 
 // DeleteUser godoc
 // @Summary Delete User
-// @Description Only admin can delete user
+// @Description Requires permission profile:delete. The user can be restored during the grace period, then it is purged
 // @Tags users
 // @Accept  json
 // @Produce  json
@@ -150,20 +288,12 @@ func (h userProfileHandler) UpdateUser(ctx echo.Context) error {
 // @Param Authorization header string true "Bearer {token}"
 // @Success 200 {object} SuccessResp
 // @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
 // @Response 500 {object} ErrorResp
 // @Router /user/{user_ksuid} [delete]
 func (h userProfileHandler) DeleteUser(ctx echo.Context) error {
 	userKsuid := ctx.Param("user_ksuid")
 
-	accessToken, err := getBearerToken(ctx.Request().Header.Get("Authorization"))
-	if err != nil {
-		return ctx.JSON(http.StatusUnauthorized, ErrorResponse("Unauthorize"))
-	}
-
-	if err = jwt.IsAdmin(accessToken); err != nil {
-		return ctx.JSON(http.StatusUnauthorized, ErrorResponse("Unauthorize"))
-	}
-
 	deletedUser, err := h.uc.DeleteUserProfile(userKsuid)
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
@@ -172,16 +302,34 @@ func (h userProfileHandler) DeleteUser(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, SuccessResponse(deletedUser))
 }
 
-func getBearerToken(auth string) (string, error) {
-	if !strings.HasPrefix(auth, "Bearer ") {
-		return "", fmt.Errorf("missing bearer token")
-	}
-
-	token := strings.Replace(auth, "Bearer ", "", -1)
+// RestoreUser godoc
+// @Summary Restore Deleted User
+// @Description Requires permission profile:restore. Undo the deletion of the user and its profile during the grace period
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Param user_ksuid path string true "Ksuid of User"
+// @Param Authorization header string true "Bearer {token}"
+// @Success 200 {object} SuccessResp
+// @Response 401 {object} ErrorResp
+// @Response 403 {object} ErrorResp
+// @Response 404 {object} ErrorResp
+// @Response 410 {object} ErrorResp
+// @Response 500 {object} ErrorResp
+// @Router /user/{user_ksuid}/restore [post]
+func (h userProfileHandler) RestoreUser(ctx echo.Context) error {
+	userKsuid := ctx.Param("user_ksuid")
 
-	if token == "" {
-		return "", fmt.Errorf("missing bearer token")
+	restoredUser, err := h.uc.RestoreUserProfile(userKsuid)
+	if errors.Is(err, usecase.ErrUserNotDeleted) {
+		return ctx.JSON(http.StatusNotFound, ErrorResponse(err.Error()))
+	}
+	if errors.Is(err, usecase.ErrRestorePeriodExpired) {
+		return ctx.JSON(http.StatusGone, ErrorResponse(err.Error()))
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse(err.Error()))
 	}
 
-	return token, nil
+	return ctx.JSON(http.StatusOK, SuccessResponse(restoredUser))
 }
@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+// swagger:model
+type InviteUserRequest struct {
+	Username string `json:"username" validate:"required"`
+	Email    string `json:"email,omitempty" validate:"omitempty,email"`
+}
+
+// swagger:model
+type InviteUserProfileRequest struct {
+	UserProfile
+	Username string `json:"username" validate:"required"`
+	Email    string `json:"email,omitempty" validate:"omitempty,email"`
+}
+
+// swagger:model
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// Invitation of a user waiting to choose a password, the token is only given when the user is invited
+// swagger:model
+type Invitation struct {
+	UserKsuid   string       `json:"user_ksuid"`
+	Username    string       `json:"username"`
+	Email       *string      `json:"email,omitempty"`
+	InvitedAt   time.Time    `json:"invited_at"`
+	ExpiresAt   time.Time    `json:"expires_at"`
+	InviteToken string       `json:"invite_token,omitempty"`
+	Profile     *UserProfile `json:"profile,omitempty"`
+}
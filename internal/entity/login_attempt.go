@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+// LoginAttempt count the failed logins of a username or an ip address,
+// the key is prefixed by its kind, e.g. "username:admin" or "ip:127.0.0.1"
+type LoginAttempt struct {
+	Key          string `gorm:"primaryKey"`
+	FailedCount  int
+	LockCount    int
+	LockedUntil  *time.Time
+	LastFailedAt time.Time
+}
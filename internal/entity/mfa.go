@@ -0,0 +1,33 @@
+package entity
+
+import "time"
+
+// MfaSecret is the TOTP secret of a user, the second factor is required
+// on login once the secret is confirmed
+type MfaSecret struct {
+	UserKsuid   string `gorm:"primaryKey"`
+	Secret      string
+	ConfirmedAt *time.Time
+	// the last accepted time step, a code can't be used twice
+	LastUsedStep int64
+	CreatedAt    time.Time
+}
+
+// RecoveryCode replace the TOTP code once, when the authenticator is lost
+type RecoveryCode struct {
+	CodeHash  string `gorm:"primaryKey"`
+	UserKsuid string
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// swagger:model
+type MfaEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// swagger:model
+type MfaCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
@@ -0,0 +1,116 @@
+package entity
+
+import "time"
+
+// grant types of the OAuth 2.0 token endpoint
+const (
+	GRANT_AUTHORIZATION_CODE string = "authorization_code"
+	GRANT_REFRESH_TOKEN      string = "refresh_token"
+	GRANT_CLIENT_CREDENTIALS string = "client_credentials"
+)
+
+// OAuthClient is an application getting tokens through the OAuth 2.0 endpoints.
+// A client without secret is public (browser or mobile app), it must use PKCE.
+// swagger:model
+type OAuthClient struct {
+	ClientID         string    `json:"client_id" gorm:"primaryKey"`
+	ClientSecretHash string    `json:"-"`
+	Name             string    `json:"name"`
+	RedirectURIs     []string  `json:"redirect_uris" gorm:"serializer:json"`
+	GrantTypes       []string  `json:"grant_types" gorm:"serializer:json"`
+	Scopes           []string  `json:"scopes" gorm:"serializer:json"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// IsPublic report whether the client can't keep a secret
+func (c *OAuthClient) IsPublic() bool {
+	return c.ClientSecretHash == ""
+}
+
+// HasRedirectURI report whether the uri is registered, it has to match exactly
+func (c *OAuthClient) HasRedirectURI(uri string) bool {
+	return contains(c.RedirectURIs, uri)
+}
+
+func (c *OAuthClient) HasGrantType(grantType string) bool {
+	return contains(c.GrantTypes, grantType)
+}
+
+// HasScopes report whether every scope is registered for the client
+func (c *OAuthClient) HasScopes(scopes []string) bool {
+	for _, scope := range scopes {
+		if !contains(c.Scopes, scope) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// AuthorizationCode is the server side record of a code issued by /oauth/authorize,
+// the code itself is only stored hashed and can be exchanged once
+type AuthorizationCode struct {
+	CodeHash    string `gorm:"primaryKey"`
+	ClientID    string
+	UserKsuid   string
+	RedirectURI string
+	// S256 challenge of the PKCE code verifier
+	CodeChallenge string
+	Scope         string
+	// copied into the ID Token when scope has openid
+	Nonce     string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// AuthorizeRequest is the query of /oauth/authorize
+type AuthorizeRequest struct {
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	Nonce               string
+}
+
+// swagger:model
+type OAuthClientRequest struct {
+	Name         string   `json:"name" validate:"required,max=100"`
+	RedirectURIs []string `json:"redirect_uris"`
+	GrantTypes   []string `json:"grant_types" validate:"required"`
+	Scopes       []string `json:"scopes"`
+	// a public client gets no secret
+	Public bool `json:"public"`
+}
+
+// OAuthClientCredentials is returned once when the client is registered,
+// the secret can't be shown again
+// swagger:model
+type OAuthClientCredentials struct {
+	*OAuthClient
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// OAuthToken is the response of the token endpoint, RFC 6749 section 5.1
+// swagger:model
+type OAuthToken struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+}
+
+func contains(list []string, item string) bool {
+	for _, i := range list {
+		if i == item {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,37 @@
+package entity
+
+// OIDC_SCOPE is the scope a client requests to get an ID Token from the authorization code
+const OIDC_SCOPE = "openid"
+
+// OpenIDConfiguration is the discovery document of OpenID Connect Discovery 1.0
+// swagger:model
+type OpenIDConfiguration struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserinfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JwksURI                           string   `json:"jwks_uri"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+// UserInfo is the response of /userinfo, standard claims of OpenID Connect Core section 5.1
+// swagger:model
+type UserInfo struct {
+	Sub               string           `json:"sub"`
+	PreferredUsername string           `json:"preferred_username,omitempty"`
+	Name              string           `json:"name,omitempty"`
+	Birthdate         string           `json:"birthdate,omitempty"`
+	Address           *UserInfoAddress `json:"address,omitempty"`
+}
+
+// swagger:model
+type UserInfoAddress struct {
+	Formatted string `json:"formatted"`
+}
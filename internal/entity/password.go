@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+// PasswordHistory keep a previous password hash of a user, so it can not be reused
+type PasswordHistory struct {
+	Ksuid        string `gorm:"primaryKey"`
+	UserKsuid    string
+	PasswordHash string
+	CreatedAt    time.Time
+}
+
+// rules of the password policy
+const (
+	PASSWORD_MIN_LENGTH = "min_length"
+	PASSWORD_MAX_LENGTH = "max_length"
+	PASSWORD_UPPERCASE  = "uppercase"
+	PASSWORD_LOWERCASE  = "lowercase"
+	PASSWORD_DIGIT      = "digit"
+	PASSWORD_SYMBOL     = "symbol"
+	PASSWORD_USERNAME   = "username"
+	PASSWORD_HISTORY    = "history"
+	PASSWORD_BREACHED   = "breached"
+)
+
+// swagger:model
+type PasswordViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// PasswordReset is a single-use token sent to the user who forgot the password,
+// only the SHA-256 of the token is stored
+type PasswordReset struct {
+	TokenHash string `gorm:"primaryKey"`
+	UserKsuid string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// swagger:model
+type ForgotPasswordRequest struct {
+	Username string `json:"username" validate:"required"`
+}
+
+// swagger:model
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
+}
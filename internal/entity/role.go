@@ -0,0 +1,116 @@
+package entity
+
+import "time"
+
+// swagger:model
+type Role struct {
+	Name        string    `json:"name" gorm:"primaryKey"`
+	Permissions []string  `json:"permissions" gorm:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// swagger:model
+type Permission struct {
+	Name        string    `json:"name" gorm:"primaryKey"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type RolePermission struct {
+	Role       string `gorm:"primaryKey"`
+	Permission string `gorm:"primaryKey"`
+}
+
+// swagger:model
+type RoleRequest struct {
+	Name        string   `json:"name" validate:"required,max=50"`
+	Permissions []string `json:"permissions"`
+}
+
+// swagger:model
+type RolePermissionsRequest struct {
+	Permissions []string `json:"permissions" validate:"required"`
+}
+
+// swagger:model
+type PermissionRequest struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description"`
+}
+
+// swagger:model
+type AssignRoleRequest struct {
+	Role string `json:"role" validate:"required"`
+}
+
+// permissions are named resource:action, or resource:action:scope
+// when the action is allowed on the user's own resource only
+const (
+	PERM_PROFILE_READ_SELF   = "profile:read:self"
+	PERM_PROFILE_READ_ANY    = "profile:read:any"
+	PERM_PROFILE_CREATE      = "profile:create"
+	PERM_PROFILE_UPDATE      = "profile:update:any"
+	PERM_PROFILE_UPDATE_SELF = "profile:update:self"
+	PERM_PROFILE_DELETE      = "profile:delete"
+	PERM_PROFILE_RESTORE     = "profile:restore"
+	PERM_USER_READ           = "user:read"
+	PERM_USER_CREATE         = "user:create"
+	PERM_USER_DELETE         = "user:delete"
+	PERM_USER_LOGOUT         = "user:logout"
+	PERM_USER_UNLOCK         = "user:unlock"
+	PERM_KEY_ROTATE          = "key:rotate"
+	PERM_ROLE_MANAGE         = "role:manage"
+	PERM_OAUTH_CLIENT        = "oauth_client:manage"
+	PERM_TOKEN_INTROSPECT    = "token:introspect"
+	PERM_TOKEN_REVOKE        = "token:revoke"
+)
+
+// PERMISSIONS are the built-in permissions, all of them are granted to ADMIN
+var PERMISSIONS = map[string]string{
+	PERM_PROFILE_READ_SELF:   "read own profile",
+	PERM_PROFILE_READ_ANY:    "read any profile",
+	PERM_PROFILE_CREATE:      "create user with profile",
+	PERM_PROFILE_UPDATE:      "update any profile",
+	PERM_PROFILE_UPDATE_SELF: "update the editable fields of own profile",
+	PERM_PROFILE_DELETE:      "delete user with profile",
+	PERM_PROFILE_RESTORE:     "restore deleted user with profile",
+	PERM_USER_READ:           "read any user",
+	PERM_USER_CREATE:         "create user",
+	PERM_USER_DELETE:         "delete user",
+	PERM_USER_LOGOUT:         "end every session of a user",
+	PERM_USER_UNLOCK:         "unlock a locked out user",
+	PERM_KEY_ROTATE:          "rotate token signing keys",
+	PERM_ROLE_MANAGE:         "manage roles, permissions and role of users",
+	PERM_OAUTH_CLIENT:        "register and delete oauth clients",
+	PERM_TOKEN_INTROSPECT:    "check whether a token is active",
+	PERM_TOKEN_REVOKE:        "revoke any access or refresh token",
+}
+
+// USER_PERMISSIONS are granted to USER when the role is created
+var USER_PERMISSIONS = []string{
+	PERM_PROFILE_READ_SELF,
+	PERM_PROFILE_UPDATE_SELF,
+}
+
+// PRIVATE_PERMISSIONS are required by the routes of the private port of auth app
+var PRIVATE_PERMISSIONS = []string{
+	PERM_USER_READ,
+	PERM_USER_CREATE,
+	PERM_USER_DELETE,
+	PERM_USER_LOGOUT,
+	PERM_USER_UNLOCK,
+	PERM_KEY_ROTATE,
+	PERM_ROLE_MANAGE,
+	PERM_OAUTH_CLIENT,
+	PERM_TOKEN_INTROSPECT,
+	PERM_TOKEN_REVOKE,
+}
+
+// SERVICE_PERMISSIONS are granted to SERVICE when the role is created
+var SERVICE_PERMISSIONS = []string{
+	PERM_USER_READ,
+	PERM_USER_CREATE,
+	PERM_USER_DELETE,
+	PERM_TOKEN_INTROSPECT,
+	PERM_TOKEN_REVOKE,
+}
@@ -0,0 +1,15 @@
+package entity
+
+// ServiceClient is a backend service calling the auth private server on its own behalf
+type ServiceClient struct {
+	ClientID     string
+	ClientSecret string
+}
+
+// swagger:model
+type ServiceToken struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	// in seconds
+	ExpiresIn int64 `json:"expires_in"`
+}
@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// Session is started on every login and ended on logout.
+// The session ksuid is also the family ksuid of its refresh tokens.
+// A session started by an OAuth client keeps the client and the granted scope,
+// its tokens are limited to them on every refresh.
+type Session struct {
+	Ksuid     string `gorm:"primaryKey"`
+	UserKsuid string
+	ClientID  string
+	Scope     string
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// swagger:model
+type SigningKey struct {
+	Kid       string `json:"kid" gorm:"primaryKey"`
+	TokenType string `json:"token_type"`
+	Algorithm string `json:"algorithm"`
+	// PEM encoded private key, or base64 encoded secret for HMAC
+	PrivateKey string     `json:"-"`
+	RetiredAt  *time.Time `json:"retired_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// swagger:model
+type RotateKeyRequest struct {
+	TokenType string `json:"token_type" validate:"required,oneof=access refresh"`
+}
+
+const (
+	ACCESS_TOKEN  string = "access"
+	REFRESH_TOKEN string = "refresh"
+)
@@ -0,0 +1,62 @@
+package entity
+
+import "time"
+
+// Token is a pair of access token and refresh token issued to a user
+type Token struct {
+	UserKsuid    string
+	AccessToken  string
+	RefreshToken string
+	// granted scope of a token issued to an OAuth client
+	Scope string
+}
+
+// RefreshToken is the server side record of an issued refresh token.
+// Every login starts a new family, each rotation adds a token to the same family.
+type RefreshToken struct {
+	Jti         string `gorm:"primaryKey"`
+	FamilyKsuid string
+	UserKsuid   string
+	ExpiresAt   time.Time
+	RevokedAt   *time.Time
+	CreatedAt   time.Time
+}
+
+// RevokedToken is an access token revoked before it expires, or an MFA pending token
+// already used to login, it is kept until then so the token is refused
+type RevokedToken struct {
+	Jti       string `gorm:"primaryKey"`
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// swagger:model
+type TokenRequest struct {
+	Token string `json:"token" form:"token" validate:"required"`
+	// access_token or refresh_token, accepted but not needed to find the token
+	TokenTypeHint string `json:"token_type_hint" form:"token_type_hint"`
+}
+
+// Introspection is the response of RFC 7662, an inactive token only has active false
+// swagger:model
+type Introspection struct {
+	Active      bool     `json:"active"`
+	TokenType   string   `json:"token_type,omitempty"`
+	Sub         string   `json:"sub,omitempty"`
+	Username    string   `json:"username,omitempty"`
+	Role        string   `json:"role,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	Sid         string   `json:"sid,omitempty"`
+	Iss         string   `json:"iss,omitempty"`
+	Aud         []string `json:"aud,omitempty"`
+	Jti         string   `json:"jti,omitempty"`
+	Exp         int64    `json:"exp,omitempty"`
+	Iat         int64    `json:"iat,omitempty"`
+	Nbf         int64    `json:"nbf,omitempty"`
+}
+
+// token_type_hint of RFC 7009 and RFC 7662
+const (
+	ACCESS_TOKEN_HINT  string = "access_token"
+	REFRESH_TOKEN_HINT string = "refresh_token"
+)
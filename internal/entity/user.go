@@ -1,20 +1,75 @@
 package entity
 
+import "time"
+
 // swagger:model
 type User struct {
-	Ksuid    string `json:"ksuid"`
-	Username string `json:"username" validate:"required"`
-	Password string `json:"password,omitempty" validate:"required"`
-	Role     string `json:"role"`
+	Ksuid             string     `json:"ksuid"`
+	Username          string     `json:"username" validate:"required"`
+	Password          string     `json:"password,omitempty" validate:"required"`
+	Role              string     `json:"role"`
+	PasswordChangedAt *time.Time `json:"-"`
+	// optional, unique among users
+	Email           *string    `json:"email,omitempty"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	// the user can't login until the email address is verified, whatever email_verification.required_for_login
+	EmailVerificationRequired bool `json:"email_verification_required,omitempty"`
+	// set while the user is invited by admin and has not chosen a password yet
+	InvitedAt *time.Time `json:"invited_at,omitempty"`
+	// set when the user is deleted, until the user is restored or purged
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// IsEmailVerified report whether the user has an email address and has verified it
+func (u *User) IsEmailVerified() bool {
+	return u.Email != nil && u.EmailVerifiedAt != nil
+}
+
+// IsInvitationPending report whether the user is invited and can't login yet
+func (u *User) IsInvitationPending() bool {
+	return u.InvitedAt != nil
+}
+
+// IsDeleted report whether the user is deleted and waits to be restored or purged
+func (u *User) IsDeleted() bool {
+	return u.DeletedAt != nil
 }
 
 // swagger:model
 type UserRequest struct {
 	Username string `json:"username" validate:"required"`
 	Password string `json:"password" validate:"required"`
+	Email    string `json:"email,omitempty" validate:"omitempty,email"`
+	// keep the user from login until the email address is verified, requires the email
+	EmailVerificationRequired bool `json:"email_verification_required,omitempty" validate:"excluded_without=Email"`
+}
+
+// swagger:model
+type UsersLookupRequest struct {
+	Ksuids []string `json:"ksuids" validate:"required,min=1,max=100"`
+	// find the deleted users not purged yet too, with their deleted_at
+	IncludeDeleted bool `json:"include_deleted"`
+}
+
+// swagger:model
+type VerifyEmailRequest struct {
+	Token string `json:"token" query:"token" validate:"required"`
+}
+
+// swagger:model
+type SendVerificationRequest struct {
+	Username string `json:"username" validate:"required"`
+}
+
+// swagger:model
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
 }
 
 const (
 	ADMIN string = "admin"
 	USER  string = "user"
+	// role of the tokens issued to service clients, never assigned to a user
+	SERVICE string = "service"
 )
@@ -1,16 +1,81 @@
 package entity
 
+import "time"
+
 // swagger:model
 type UserProfile struct {
 	UserKsuid   string `json:"user_ksuid,omitempty" gorm:"primaryKey"`
 	Name        string `json:"name" validate:"required"`
 	DateOfBirth string `json:"date_of_birth" validate:"required,date=2006-01-02"`
 	Address     string `json:"address" validate:"required"`
+	// incremented on every update, the ETag of the profile
+	Version   int       `json:"version,omitempty" gorm:"default:1"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// set when the user is deleted, until the user is restored or purged
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
+// content types of the patch of a profile
+const (
+	MERGE_PATCH = "application/merge-patch+json"
+	JSON_PATCH  = "application/json-patch+json"
+)
+
 // swagger:model
 type CreateUserRequest struct {
 	UserProfile
 	Username string `json:"username" validate:"required"`
 	Password string `json:"password,omitempty" validate:"required"`
+	Email    string `json:"email,omitempty" validate:"omitempty,email"`
+	// set by the registration policy, not by the client
+	EmailVerificationRequired bool `json:"-"`
+}
+
+// swagger:model
+type RegisterRequest struct {
+	CreateUserRequest
+	InviteCode string `json:"invite_code,omitempty"`
+}
+
+// UserListRequest filter, sort and page the users. Sort is a field, descending when prefixed by -,
+// the cursor is the next_cursor of the previous page, with the same filters and sort
+// swagger:model
+type UserListRequest struct {
+	// substring of the name
+	Name            string `query:"name"`
+	Address         string `query:"address"`
+	DateOfBirthFrom string `query:"date_of_birth_from" validate:"omitempty,date=2006-01-02"`
+	DateOfBirthTo   string `query:"date_of_birth_to" validate:"omitempty,date=2006-01-02"`
+	Role            string `query:"role"`
+	Sort            string `query:"sort" validate:"omitempty,oneof=user_ksuid -user_ksuid name -name date_of_birth -date_of_birth"`
+	Cursor          string `query:"cursor"`
+	Limit           int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// UserProfileFilter of the user_profiles, a page starts after the profile with the sort value and ksuid, if any
+type UserProfileFilter struct {
+	Name            string
+	Address         string
+	DateOfBirthFrom string
+	DateOfBirthTo   string
+	SortBy          string
+	Desc            bool
+	AfterValue      string
+	AfterKsuid      string
+	Limit           int
+}
+
+// swagger:model
+type UserListItem struct {
+	UserProfile
+	Username string  `json:"username,omitempty"`
+	Role     string  `json:"role,omitempty"`
+	Email    *string `json:"email,omitempty"`
+}
+
+// swagger:model
+type UserList struct {
+	Users []UserListItem `json:"users"`
+	// empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
 }
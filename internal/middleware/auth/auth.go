@@ -0,0 +1,145 @@
+// Package auth_middleware authenticate requests by their bearer access token,
+// and guard routes by role, permission or ownership of the resource.
+package auth_middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/adesupraptolaia/user_login/pkg/jwt"
+	"github.com/labstack/echo/v4"
+)
+
+// CLAIMS_KEY is the echo.Context key of the claims of an authenticated request
+const CLAIMS_KEY = "auth_claims"
+
+// TokenChecker tell whether a valid access token is still active, only the service
+// issuing the tokens knows the revoked ones and the ended sessions
+type TokenChecker interface {
+	IsAccessTokenActive(*jwt.Claims) (bool, error)
+}
+
+// Authenticate validate the bearer access token for the audience of the service
+// and put its claims into the context, the guards below must run after it.
+// A revoked token is accepted until it expires, use AuthenticateActive where the tokens are known
+func Authenticate(audience string) echo.MiddlewareFunc {
+	return AuthenticateActive(audience, nil)
+}
+
+// AuthenticateActive is Authenticate refusing as well a token the checker reports inactive,
+// e.g. revoked by /oauth/revoke or of a session ended by logout
+func AuthenticateActive(audience string, checker TokenChecker) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			accessToken, err := GetBearerToken(ctx)
+			if err != nil {
+				return unauthorized(ctx)
+			}
+
+			claims, err := jwt.GetAccessTokenClaims(accessToken, audience)
+			if err != nil {
+				return unauthorized(ctx)
+			}
+
+			if checker != nil {
+				active, err := checker.IsAccessTokenActive(claims)
+				if err != nil {
+					return ctx.JSON(http.StatusInternalServerError, errorResponse(err.Error()))
+				}
+				if !active {
+					return unauthorized(ctx)
+				}
+			}
+
+			ctx.Set(CLAIMS_KEY, claims)
+
+			return next(ctx)
+		}
+	}
+}
+
+// RequireRole allow the request when the role of the token is one of roles
+func RequireRole(roles ...string) echo.MiddlewareFunc {
+	return guard(func(ctx echo.Context, claims *jwt.Claims) bool {
+		for _, role := range roles {
+			if claims.Role == role {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// RequirePermission allow the request when the role of the token was granted the permission
+func RequirePermission(permission string) echo.MiddlewareFunc {
+	return guard(func(ctx echo.Context, claims *jwt.Claims) bool {
+		return claims.HasPermission(permission)
+	})
+}
+
+// RequireSelfOrPermission allow anyPermission, or selfPermission
+// when the ksuid in the path param is the user of the token
+func RequireSelfOrPermission(param, selfPermission, anyPermission string) echo.MiddlewareFunc {
+	return guard(func(ctx echo.Context, claims *jwt.Claims) bool {
+		if claims.HasPermission(anyPermission) {
+			return true
+		}
+
+		return claims.UserKsuid == ctx.Param(param) && claims.HasPermission(selfPermission)
+	})
+}
+
+// GetClaims return the claims put by Authenticate
+func GetClaims(ctx echo.Context) *jwt.Claims {
+	claims, _ := ctx.Get(CLAIMS_KEY).(*jwt.Claims)
+	return claims
+}
+
+// GetBearerToken return the token of the Authorization header
+func GetBearerToken(ctx echo.Context) (string, error) {
+	auth := ctx.Request().Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return "", fmt.Errorf("missing bearer token")
+	}
+
+	token := strings.TrimPrefix(auth, "Bearer ")
+
+	if token == "" {
+		return "", fmt.Errorf("missing bearer token")
+	}
+
+	return token, nil
+}
+
+func guard(allow func(echo.Context, *jwt.Claims) bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			// a route guarded without Authenticate has no claims, refuse it like a missing token
+			claims := GetClaims(ctx)
+			if claims == nil {
+				return unauthorized(ctx)
+			}
+
+			if !allow(ctx, claims) {
+				return ctx.JSON(http.StatusForbidden, errorResponse("Forbidden"))
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+func unauthorized(ctx echo.Context) error {
+	return ctx.JSON(http.StatusUnauthorized, errorResponse("Unauthorize"))
+}
+
+// errorResponse has the shape of the ErrorResp of the controllers,
+// which the middleware can not import as they import it
+func errorResponse(error_message string) map[string]string {
+	return map[string]string{
+		"status":        "error",
+		"error_message": error_message,
+	}
+}
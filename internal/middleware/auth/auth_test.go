@@ -0,0 +1,277 @@
+package auth_middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/adesupraptolaia/user_login/pkg/jwt"
+	"github.com/labstack/echo/v4"
+)
+
+// serve run the request through the middlewares to a handler answering 200,
+// with ksuid as the user_ksuid path param
+func serve(t *testing.T, accessToken, ksuid string, middlewares ...echo.MiddlewareFunc) int {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/user/"+ksuid, nil)
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+	ctx.SetParamNames("user_ksuid")
+	ctx.SetParamValues(ksuid)
+
+	var handler echo.HandlerFunc = func(ctx echo.Context) error {
+		if GetClaims(ctx) == nil {
+			t.Errorf("handler reached without claims")
+		}
+		return ctx.NoContent(http.StatusOK)
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	if err := handler(ctx); err != nil {
+		t.Fatalf("handler error = %v", err)
+	}
+
+	return rec.Code
+}
+
+func mustAccessToken(t *testing.T, userKsuid, role string, permissions, audience []string) string {
+	token, err := jwt.CreateAccessToken(userKsuid, role, "session_ksuid", permissions, audience)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return token
+}
+
+func TestAuthenticate(t *testing.T) {
+	publicToken := mustAccessToken(t, "ksuid", entity.USER, nil, []string{jwt.AUTH_PUBLIC_AUDIENCE, jwt.USER_APP_AUDIENCE})
+	refreshToken, _ := jwt.CreateRefreshToken("ksuid", entity.USER, "jti")
+
+	tests := []struct {
+		name        string
+		audience    string
+		accessToken string
+		want        int
+	}{
+		{
+			name:        "Success Token Of The Audience",
+			audience:    jwt.USER_APP_AUDIENCE,
+			accessToken: publicToken,
+			want:        http.StatusOK,
+		},
+		{
+			name:        "Failed Token Of Another Audience",
+			audience:    jwt.AUTH_PRIVATE_AUDIENCE,
+			accessToken: publicToken,
+			want:        http.StatusUnauthorized,
+		},
+		{
+			name:        "Failed Refresh Token",
+			audience:    jwt.AUTH_PUBLIC_AUDIENCE,
+			accessToken: refreshToken,
+			want:        http.StatusUnauthorized,
+		},
+		{
+			name:        "Failed Invalid Token",
+			audience:    jwt.AUTH_PUBLIC_AUDIENCE,
+			accessToken: "invalidToken",
+			want:        http.StatusUnauthorized,
+		},
+		{
+			name:        "Failed Without Token",
+			audience:    jwt.AUTH_PUBLIC_AUDIENCE,
+			accessToken: "",
+			want:        http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := serve(t, tt.accessToken, "ksuid", Authenticate(tt.audience)); got != tt.want {
+				t.Errorf("Authenticate() status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	audience := []string{jwt.AUTH_PRIVATE_AUDIENCE}
+
+	tests := []struct {
+		name        string
+		accessToken string
+		want        int
+	}{
+		{
+			name:        "Success Role Allowed",
+			accessToken: mustAccessToken(t, "ksuid", entity.SERVICE, nil, audience),
+			want:        http.StatusOK,
+		},
+		{
+			name:        "Failed Role Not Allowed",
+			accessToken: mustAccessToken(t, "ksuid", entity.ADMIN, nil, audience),
+			want:        http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := serve(t, tt.accessToken, "ksuid", Authenticate(jwt.AUTH_PRIVATE_AUDIENCE), RequireRole(entity.SERVICE))
+			if got != tt.want {
+				t.Errorf("RequireRole() status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	audience := []string{jwt.AUTH_PRIVATE_AUDIENCE}
+
+	tests := []struct {
+		name        string
+		accessToken string
+		want        int
+	}{
+		{
+			name:        "Success Permission Granted",
+			accessToken: mustAccessToken(t, "ksuid", entity.ADMIN, []string{entity.PERM_USER_READ, entity.PERM_USER_DELETE}, audience),
+			want:        http.StatusOK,
+		},
+		{
+			name:        "Failed Permission Not Granted",
+			accessToken: mustAccessToken(t, "ksuid", entity.ADMIN, []string{entity.PERM_USER_READ}, audience),
+			want:        http.StatusForbidden,
+		},
+		{
+			name:        "Failed Without Permissions",
+			accessToken: mustAccessToken(t, "ksuid", entity.ADMIN, nil, audience),
+			want:        http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := serve(t, tt.accessToken, "ksuid", Authenticate(jwt.AUTH_PRIVATE_AUDIENCE), RequirePermission(entity.PERM_USER_DELETE))
+			if got != tt.want {
+				t.Errorf("RequirePermission() status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireSelfOrPermission(t *testing.T) {
+	audience := []string{jwt.USER_APP_AUDIENCE}
+	selfToken := mustAccessToken(t, "ksuid", entity.USER, []string{entity.PERM_PROFILE_READ_SELF}, audience)
+
+	tests := []struct {
+		name        string
+		accessToken string
+		ksuid       string
+		want        int
+	}{
+		{
+			name:        "Success Self With Self Permission",
+			accessToken: selfToken,
+			ksuid:       "ksuid",
+			want:        http.StatusOK,
+		},
+		{
+			name:        "Success Other User With Any Permission",
+			accessToken: mustAccessToken(t, "admin_ksuid", entity.ADMIN, []string{entity.PERM_PROFILE_READ_ANY}, audience),
+			ksuid:       "ksuid",
+			want:        http.StatusOK,
+		},
+		{
+			name:        "Failed Other User With Self Permission",
+			accessToken: selfToken,
+			ksuid:       "other_ksuid",
+			want:        http.StatusForbidden,
+		},
+		{
+			name:        "Failed Self Without Self Permission",
+			accessToken: mustAccessToken(t, "ksuid", entity.USER, nil, audience),
+			ksuid:       "ksuid",
+			want:        http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := serve(t, tt.accessToken, tt.ksuid, Authenticate(jwt.USER_APP_AUDIENCE),
+				RequireSelfOrPermission("user_ksuid", entity.PERM_PROFILE_READ_SELF, entity.PERM_PROFILE_READ_ANY))
+			if got != tt.want {
+				t.Errorf("RequireSelfOrPermission() status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGuardWithoutAuthenticate(t *testing.T) {
+	accessToken := mustAccessToken(t, "ksuid", entity.ADMIN, []string{entity.PERM_USER_DELETE}, []string{jwt.AUTH_PRIVATE_AUDIENCE})
+
+	if got := serve(t, accessToken, "ksuid", RequirePermission(entity.PERM_USER_DELETE)); got != http.StatusUnauthorized {
+		t.Errorf("RequirePermission() without Authenticate status = %d, want %d", got, http.StatusUnauthorized)
+	}
+}
+
+// fakeChecker report the tokens of its jti inactive
+type fakeChecker struct {
+	inactive map[string]bool
+	err      error
+}
+
+func (c fakeChecker) IsAccessTokenActive(claims *jwt.Claims) (bool, error) {
+	return !c.inactive[claims.Id], c.err
+}
+
+func TestAuthenticateActive(t *testing.T) {
+	audience := []string{jwt.AUTH_PUBLIC_AUDIENCE}
+	activeToken := mustAccessToken(t, "ksuid", entity.USER, nil, audience)
+	revokedToken := mustAccessToken(t, "ksuid", entity.USER, nil, audience)
+	revokedClaims, _ := jwt.GetAccessTokenClaims(revokedToken, jwt.AUTH_PUBLIC_AUDIENCE)
+
+	checker := fakeChecker{inactive: map[string]bool{revokedClaims.Id: true}}
+
+	tests := []struct {
+		name        string
+		checker     TokenChecker
+		accessToken string
+		want        int
+	}{
+		{
+			name:        "Success Active Token",
+			checker:     checker,
+			accessToken: activeToken,
+			want:        http.StatusOK,
+		},
+		{
+			name:        "Failed Inactive Token",
+			checker:     checker,
+			accessToken: revokedToken,
+			want:        http.StatusUnauthorized,
+		},
+		{
+			name:        "Failed Checker Error",
+			checker:     fakeChecker{err: fmt.Errorf("error select")},
+			accessToken: activeToken,
+			want:        http.StatusInternalServerError,
+		},
+		{
+			name:        "Failed Invalid Token",
+			checker:     checker,
+			accessToken: "invalidToken",
+			want:        http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := serve(t, tt.accessToken, "ksuid", AuthenticateActive(jwt.AUTH_PUBLIC_AUDIENCE, tt.checker)); got != tt.want {
+				t.Errorf("AuthenticateActive() status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
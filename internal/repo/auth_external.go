@@ -6,29 +6,90 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/adesupraptolaia/user_login/config"
 	"github.com/adesupraptolaia/user_login/internal/entity"
-	"github.com/adesupraptolaia/user_login/pkg/jwt"
 	"github.com/labstack/gommon/log"
 )
 
 type AuthRepo interface {
+	GetUsers([]string) ([]entity.User, error)
+	GetUsersWithDeleted([]string) ([]entity.User, error)
 	CreateUser(entity.User) (*entity.User, error)
+	InviteUser(entity.InviteUserRequest) (*entity.Invitation, error)
+	GetInvitations() ([]entity.Invitation, error)
+	RevokeInvitation(string) (*entity.User, error)
 	DeleteUser(string) (*entity.User, error)
+	RestoreUser(string) (*entity.User, error)
+	PurgeUser(string) error
 }
 
-type authRepo struct{}
+// authRepo call the auth private server with a service token of its own client,
+// the token is cached until shortly before it expires
+type authRepo struct {
+	clientID     string
+	clientSecret string
 
-func NewAuthRepo() AuthRepo {
-	return &authRepo{}
+	mu             sync.Mutex
+	token          string
+	tokenExpiresAt time.Time
+}
+
+func NewAuthRepo(clientID, clientSecret string) AuthRepo {
+	return &authRepo{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+	}
 }
 
 type AuthReponse struct {
-	Status       string       `json:"status"`
-	ErrorMessage string       `json:"error_message"`
-	Data         *entity.User `json:"data"`
+	Status       string          `json:"status"`
+	ErrorMessage string          `json:"error_message"`
+	Data         json.RawMessage `json:"data"`
+}
+
+// AuthError is an error response of auth service, e.g. 409 for a username already taken
+type AuthError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *AuthError) Error() string {
+	return e.Message
+}
+
+type ServiceTokenResponse struct {
+	Status       string               `json:"status"`
+	ErrorMessage string               `json:"error_message"`
+	Data         *entity.ServiceToken `json:"data"`
+}
+
+// GetUsers get the users found among the ksuids, up to 100 at once
+func (repo *authRepo) GetUsers(userKsuids []string) ([]entity.User, error) {
+	return repo.lookupUsers(entity.UsersLookupRequest{Ksuids: userKsuids})
+}
+
+// GetUsersWithDeleted get the users found among the ksuids like GetUsers, the deleted ones not purged yet included
+func (repo *authRepo) GetUsersWithDeleted(userKsuids []string) ([]entity.User, error) {
+	return repo.lookupUsers(entity.UsersLookupRequest{Ksuids: userKsuids, IncludeDeleted: true})
+}
+
+func (repo *authRepo) lookupUsers(req entity.UsersLookupRequest) ([]entity.User, error) {
+	log.Infof("lookup %d users to auth service", len(req.Ksuids))
+
+	url := fmt.Sprintf("http://%s/users/lookup", getBaseURL())
+
+	result := []entity.User{}
+	if err := repo.doRequest(http.MethodPost, url, req, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
 }
 
 func (repo *authRepo) CreateUser(user entity.User) (*entity.User, error) {
@@ -36,7 +97,51 @@ func (repo *authRepo) CreateUser(user entity.User) (*entity.User, error) {
 
 	url := fmt.Sprintf("http://%s/user/create", getBaseURL())
 
-	return doRequest(http.MethodPost, url, user)
+	result := &entity.User{}
+	if err := repo.doRequest(http.MethodPost, url, user, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (repo *authRepo) InviteUser(req entity.InviteUserRequest) (*entity.Invitation, error) {
+	log.Info("invite user to auth service")
+
+	url := fmt.Sprintf("http://%s/invite", getBaseURL())
+
+	result := &entity.Invitation{}
+	if err := repo.doRequest(http.MethodPost, url, req, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (repo *authRepo) GetInvitations() ([]entity.Invitation, error) {
+	log.Info("get invitations from auth service")
+
+	url := fmt.Sprintf("http://%s/invites", getBaseURL())
+
+	result := []entity.Invitation{}
+	if err := repo.doRequest(http.MethodGet, url, nil, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (repo *authRepo) RevokeInvitation(userKsuid string) (*entity.User, error) {
+	log.Infof("revoke invitation of user with ksuid %s to auth service", userKsuid)
+
+	url := fmt.Sprintf("http://%s/invite/%s", getBaseURL(), userKsuid)
+
+	result := &entity.User{}
+	if err := repo.doRequest(http.MethodDelete, url, nil, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
 }
 
 func (repo *authRepo) DeleteUser(userKsuid string) (*entity.User, error) {
@@ -44,23 +149,50 @@ func (repo *authRepo) DeleteUser(userKsuid string) (*entity.User, error) {
 
 	url := fmt.Sprintf("http://%s/user/%s", getBaseURL(), userKsuid)
 
-	return doRequest(http.MethodDelete, url, nil)
+	result := &entity.User{}
+	if err := repo.doRequest(http.MethodDelete, url, nil, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (repo *authRepo) RestoreUser(userKsuid string) (*entity.User, error) {
+	log.Infof("restore user with ksuid %s to auth service", userKsuid)
+
+	url := fmt.Sprintf("http://%s/user/%s/restore", getBaseURL(), userKsuid)
+
+	result := &entity.User{}
+	if err := repo.doRequest(http.MethodPost, url, nil, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
 }
 
-func doRequest(httpMethod, url string, request interface{}) (*entity.User, error) {
+func (repo *authRepo) PurgeUser(userKsuid string) error {
+	log.Infof("purge user with ksuid %s to auth service", userKsuid)
+
+	url := fmt.Sprintf("http://%s/user/%s/purge", getBaseURL(), userKsuid)
+
+	return repo.doRequest(http.MethodDelete, url, nil, nil)
+}
+
+// doRequest call auth service and unmarshal the data of a success response into result, unless result is nil
+func (repo *authRepo) doRequest(httpMethod, url string, request, result interface{}) error {
 	reqJSON, err := json.Marshal(request)
 	if err != nil {
-		return nil, fmt.Errorf("error when marshal create user request, err: %s", err.Error())
+		return fmt.Errorf("error when marshal request, err: %s", err.Error())
 	}
 
 	req, err := http.NewRequest(httpMethod, url, bytes.NewBuffer(reqJSON))
 	if err != nil {
-		return nil, fmt.Errorf("error when make http request, err: %s", err.Error())
+		return fmt.Errorf("error when make http request, err: %s", err.Error())
 	}
 
-	accessToken, err := jwt.CreateAccessToken("2OokWa2yDw7yi7o9RpsAl58xuoW", entity.ADMIN)
+	accessToken, err := repo.serviceToken()
 	if err != nil {
-		return nil, fmt.Errorf("error when create accessToken, err %s", err.Error())
+		return err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -69,26 +201,97 @@ func doRequest(httpMethod, url string, request interface{}) (*entity.User, error
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error when calling to auth service, err: %s", err.Error())
+		return fmt.Errorf("error when calling to auth service, err: %s", err.Error())
 	}
 	defer resp.Body.Close()
 
+	// the token may be signed by a key rotated out, get a new one for the next request
+	if resp.StatusCode == http.StatusUnauthorized {
+		repo.resetServiceToken()
+	}
+
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("error when read response body, err: %s", err.Error())
+		return fmt.Errorf("error when read response body, err: %s", err.Error())
 	}
 
 	var response AuthReponse
 	err = json.Unmarshal(body, &response)
 	if err != nil {
-		return nil, fmt.Errorf("error when unmarshal response body with err %s", err.Error())
+		return fmt.Errorf("error when unmarshal response body with err %s", err.Error())
 	}
 
 	if response.Status != "success" {
-		return nil, fmt.Errorf(response.ErrorMessage)
+		return &AuthError{StatusCode: resp.StatusCode, Message: response.ErrorMessage}
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(response.Data, result); err != nil {
+		return fmt.Errorf("error when unmarshal response data with err %s", err.Error())
+	}
+
+	return nil
+}
+
+// serviceToken return the cached service token, or get a new one with the client credentials
+func (repo *authRepo) serviceToken() (string, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if repo.token != "" && time.Now().Before(repo.tokenExpiresAt) {
+		return repo.token, nil
+	}
+
+	log.Info("get service token from auth service")
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://%s/service/token", getBaseURL()), strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("error when make http request, err: %s", err.Error())
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(repo.clientID, repo.clientSecret)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error when calling to auth service, err: %s", err.Error())
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("error when read response body, err: %s", err.Error())
+	}
+
+	var response ServiceTokenResponse
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return "", fmt.Errorf("error when unmarshal response body with err %s", err.Error())
 	}
 
-	return response.Data, nil
+	if response.Status != "success" || response.Data == nil {
+		return "", fmt.Errorf("error when get service token, err: %s", response.ErrorMessage)
+	}
+
+	// renew a minute early, so the token doesn't expire on the way to auth service
+	repo.token = response.Data.AccessToken
+	repo.tokenExpiresAt = time.Now().Add(time.Duration(response.Data.ExpiresIn)*time.Second - time.Minute)
+
+	return repo.token, nil
+}
+
+func (repo *authRepo) resetServiceToken() {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.token = ""
 }
 
 func getBaseURL() string {
@@ -0,0 +1,68 @@
+package repo
+
+import (
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/labstack/gommon/log"
+	"gorm.io/gorm"
+)
+
+type AuthorizationCodesRepo interface {
+	CreateAuthorizationCode(entity.AuthorizationCode) (*entity.AuthorizationCode, error)
+	GetAuthorizationCode(string) (*entity.AuthorizationCode, error)
+	UseAuthorizationCode(string) error
+}
+
+type authorizationCodeRepo struct {
+	db *gorm.DB
+}
+
+func NewAuthorizationCode(db *gorm.DB) AuthorizationCodesRepo {
+	return &authorizationCodeRepo{
+		db: db.Table("authorization_codes").Debug(),
+	}
+}
+
+func (repo *authorizationCodeRepo) CreateAuthorizationCode(code entity.AuthorizationCode) (*entity.AuthorizationCode, error) {
+	err := repo.db.Create(&code).Error
+	if err != nil {
+		log.Errorf("error when CreateAuthorizationCode, err: %s", err.Error())
+		return nil, err
+	}
+
+	return &code, nil
+}
+
+func (repo *authorizationCodeRepo) GetAuthorizationCode(codeHash string) (*entity.AuthorizationCode, error) {
+	result := entity.AuthorizationCode{}
+
+	err := repo.db.
+		Where("code_hash = ?", codeHash).
+		First(&result).Error
+	if err != nil {
+		log.Errorf("error when GetAuthorizationCode, err: %s", err.Error())
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// UseAuthorizationCode fails with gorm.ErrRecordNotFound when the code is unknown, used or expired
+func (repo *authorizationCodeRepo) UseAuthorizationCode(codeHash string) error {
+	now := time.Now()
+
+	result := repo.db.
+		Where("code_hash = ? AND used_at IS NULL AND expires_at > ?", codeHash, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		log.Errorf("error when UseAuthorizationCode, err: %s", result.Error.Error())
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
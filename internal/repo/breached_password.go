@@ -0,0 +1,62 @@
+package repo
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/labstack/gommon/log"
+)
+
+type BreachedPasswordsRepo interface {
+	GetHashSuffixes(prefix string) ([]string, error)
+}
+
+// breachedPasswordRepo read the k-anonymity range files of Have I Been Pwned
+// from disk, one file per first 5 hex of the SHA-1, e.g. 5BAA6.txt, each line
+// holding the rest of the hash and its count, e.g. 1E4C9B93F3F0682250B6CF8331B7EE68FD8:10437277
+type breachedPasswordRepo struct {
+	dir string
+}
+
+// NewBreachedPassword read the range files in dir, an empty dir has no breached password
+func NewBreachedPassword(dir string) BreachedPasswordsRepo {
+	return &breachedPasswordRepo{
+		dir: dir,
+	}
+}
+
+// GetHashSuffixes return the uppercase SHA-1 suffixes of the breached passwords with the prefix
+func (repo *breachedPasswordRepo) GetHashSuffixes(prefix string) ([]string, error) {
+	if repo.dir == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(filepath.Join(repo.dir, strings.ToUpper(prefix)+".txt"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		log.Errorf("error when GetHashSuffixes, err: %s", err.Error())
+		return nil, err
+	}
+	defer file.Close()
+
+	suffixes := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		suffix, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if suffix != "" {
+			suffixes = append(suffixes, strings.ToUpper(suffix))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		log.Errorf("error when GetHashSuffixes, err: %s", err.Error())
+		return nil, err
+	}
+
+	return suffixes, nil
+}
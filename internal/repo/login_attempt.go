@@ -0,0 +1,100 @@
+package repo
+
+import (
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/labstack/gommon/log"
+	"gorm.io/gorm"
+)
+
+type LoginAttemptsRepo interface {
+	GetLoginAttempt(string) (*entity.LoginAttempt, error)
+	AddFailedAttempt(string, time.Time) (*entity.LoginAttempt, error)
+	LockLoginAttempt(string, time.Time) error
+	DeleteLoginAttempt(string) error
+	DeleteStaleLoginAttempts(time.Time) (int64, error)
+}
+
+type loginAttemptRepo struct {
+	db *gorm.DB
+}
+
+func NewLoginAttempt(db *gorm.DB) LoginAttemptsRepo {
+	return &loginAttemptRepo{
+		db: db.Table("login_attempts").Debug(),
+	}
+}
+
+func (repo *loginAttemptRepo) GetLoginAttempt(key string) (*entity.LoginAttempt, error) {
+	result := entity.LoginAttempt{}
+
+	err := repo.db.
+		Where("`key` = ?", key).
+		First(&result).Error
+	if err != nil {
+		log.Errorf("error when GetLoginAttempt, err: %s", err.Error())
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// AddFailedAttempt increment the failed count atomically, so every replica share the counter.
+// Failures before windowStart are forgotten.
+func (repo *loginAttemptRepo) AddFailedAttempt(key string, windowStart time.Time) (*entity.LoginAttempt, error) {
+	err := repo.db.Exec(
+		"INSERT INTO login_attempts (`key`, failed_count, last_failed_at) VALUES (?, 1, ?) "+
+			"ON DUPLICATE KEY UPDATE failed_count = IF(last_failed_at < ?, 1, failed_count + 1), last_failed_at = VALUES(last_failed_at)",
+		key, time.Now(), windowStart,
+	).Error
+	if err != nil {
+		log.Errorf("error when AddFailedAttempt, err: %s", err.Error())
+		return nil, err
+	}
+
+	return repo.GetLoginAttempt(key)
+}
+
+// LockLoginAttempt lock the key until lockedUntil and start counting again
+func (repo *loginAttemptRepo) LockLoginAttempt(key string, lockedUntil time.Time) error {
+	err := repo.db.
+		Where("`key` = ?", key).
+		Updates(map[string]interface{}{
+			"failed_count": 0,
+			"lock_count":   gorm.Expr("lock_count + 1"),
+			"locked_until": lockedUntil,
+		}).Error
+	if err != nil {
+		log.Errorf("error when LockLoginAttempt, err: %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (repo *loginAttemptRepo) DeleteLoginAttempt(key string) error {
+	err := repo.db.
+		Where("`key` = ?", key).
+		Delete(&entity.LoginAttempt{}).Error
+	if err != nil {
+		log.Errorf("error when DeleteLoginAttempt, err: %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// DeleteStaleLoginAttempts delete the keys without failure since lastFailedBefore and not locked anymore,
+// e.g. the many usernames guessed once by a client
+func (repo *loginAttemptRepo) DeleteStaleLoginAttempts(lastFailedBefore time.Time) (int64, error) {
+	result := repo.db.
+		Where("last_failed_at < ? AND (locked_until IS NULL OR locked_until < ?)", lastFailedBefore, time.Now()).
+		Delete(&entity.LoginAttempt{})
+	if result.Error != nil {
+		log.Errorf("error when DeleteStaleLoginAttempts, err: %s", result.Error.Error())
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,90 @@
+package repo
+
+import (
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/labstack/gommon/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MfaSecretsRepo interface {
+	GetMfaSecret(string) (*entity.MfaSecret, error)
+	SaveMfaSecret(entity.MfaSecret) (*entity.MfaSecret, error)
+	ConfirmMfaSecret(string, int64) error
+	UseMfaStep(string, int64) error
+}
+
+type mfaSecretRepo struct {
+	db *gorm.DB
+}
+
+func NewMfaSecret(db *gorm.DB) MfaSecretsRepo {
+	return &mfaSecretRepo{
+		db: db.Table("mfa_secrets").Debug(),
+	}
+}
+
+func (repo *mfaSecretRepo) GetMfaSecret(userKsuid string) (*entity.MfaSecret, error) {
+	result := entity.MfaSecret{}
+
+	err := repo.db.
+		Where("user_ksuid = ?", userKsuid).
+		First(&result).Error
+	if err != nil {
+		log.Errorf("error when GetMfaSecret, err: %s", err.Error())
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// SaveMfaSecret create the secret or replace the one of the user
+func (repo *mfaSecretRepo) SaveMfaSecret(mfaSecret entity.MfaSecret) (*entity.MfaSecret, error) {
+	err := repo.db.
+		Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(&mfaSecret).Error
+	if err != nil {
+		log.Errorf("error when SaveMfaSecret, err: %s", err.Error())
+		return nil, err
+	}
+
+	return &mfaSecret, nil
+}
+
+func (repo *mfaSecretRepo) ConfirmMfaSecret(userKsuid string, step int64) error {
+	result := repo.db.
+		Where("user_ksuid = ? AND confirmed_at IS NULL", userKsuid).
+		Updates(map[string]interface{}{
+			"confirmed_at":   time.Now(),
+			"last_used_step": step,
+		})
+	if result.Error != nil {
+		log.Errorf("error when ConfirmMfaSecret, err: %s", result.Error.Error())
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// UseMfaStep fails with gorm.ErrRecordNotFound when the step, or a later one, has been used
+func (repo *mfaSecretRepo) UseMfaStep(userKsuid string, step int64) error {
+	result := repo.db.
+		Where("user_ksuid = ? AND last_used_step < ?", userKsuid, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		log.Errorf("error when UseMfaStep, err: %s", result.Error.Error())
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -64,6 +64,176 @@ func (_m *AuthRepo) DeleteUser(_a0 string) (*entity.User, error) {
 	return r0, r1
 }
 
+// GetInvitations provides a mock function with given fields:
+func (_m *AuthRepo) GetInvitations() ([]entity.Invitation, error) {
+	ret := _m.Called()
+
+	var r0 []entity.Invitation
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]entity.Invitation, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []entity.Invitation); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Invitation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUsers provides a mock function with given fields: _a0
+func (_m *AuthRepo) GetUsers(_a0 []string) ([]entity.User, error) {
+	ret := _m.Called(_a0)
+
+	var r0 []entity.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]string) ([]entity.User, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func([]string) []entity.User); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]string) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUsersWithDeleted provides a mock function with given fields: _a0
+func (_m *AuthRepo) GetUsersWithDeleted(_a0 []string) ([]entity.User, error) {
+	ret := _m.Called(_a0)
+
+	var r0 []entity.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]string) ([]entity.User, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func([]string) []entity.User); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]string) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InviteUser provides a mock function with given fields: _a0
+func (_m *AuthRepo) InviteUser(_a0 entity.InviteUserRequest) (*entity.Invitation, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *entity.Invitation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(entity.InviteUserRequest) (*entity.Invitation, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(entity.InviteUserRequest) *entity.Invitation); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Invitation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(entity.InviteUserRequest) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PurgeUser provides a mock function with given fields: _a0
+func (_m *AuthRepo) PurgeUser(_a0 string) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RestoreUser provides a mock function with given fields: _a0
+func (_m *AuthRepo) RestoreUser(_a0 string) (*entity.User, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *entity.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*entity.User, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(string) *entity.User); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeInvitation provides a mock function with given fields: _a0
+func (_m *AuthRepo) RevokeInvitation(_a0 string) (*entity.User, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *entity.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*entity.User, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(string) *entity.User); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewAuthRepo interface {
 	mock.TestingT
 	Cleanup(func())
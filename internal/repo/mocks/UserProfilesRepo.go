@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	time "time"
+
 	entity "github.com/adesupraptolaia/user_login/internal/entity"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -64,6 +66,58 @@ func (_m *UserProfilesRepo) DeleteUserProfile(_a0 string) (*entity.UserProfile,
 	return r0, r1
 }
 
+// GetDeletedUserProfile provides a mock function with given fields: userKsuid
+func (_m *UserProfilesRepo) GetDeletedUserProfile(userKsuid string) (*entity.UserProfile, error) {
+	ret := _m.Called(userKsuid)
+
+	var r0 *entity.UserProfile
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*entity.UserProfile, error)); ok {
+		return rf(userKsuid)
+	}
+	if rf, ok := ret.Get(0).(func(string) *entity.UserProfile); ok {
+		r0 = rf(userKsuid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.UserProfile)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(userKsuid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDeletedUserProfiles provides a mock function with given fields: deletedBefore, limit
+func (_m *UserProfilesRepo) GetDeletedUserProfiles(deletedBefore time.Time, limit int) ([]entity.UserProfile, error) {
+	ret := _m.Called(deletedBefore, limit)
+
+	var r0 []entity.UserProfile
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time, int) ([]entity.UserProfile, error)); ok {
+		return rf(deletedBefore, limit)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time, int) []entity.UserProfile); ok {
+		r0 = rf(deletedBefore, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.UserProfile)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time, int) error); ok {
+		r1 = rf(deletedBefore, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetUserProfile provides a mock function with given fields: _a0
 func (_m *UserProfilesRepo) GetUserProfile(_a0 string) (*entity.UserProfile, error) {
 	ret := _m.Called(_a0)
@@ -90,6 +144,86 @@ func (_m *UserProfilesRepo) GetUserProfile(_a0 string) (*entity.UserProfile, err
 	return r0, r1
 }
 
+// GetUserProfiles provides a mock function with given fields: _a0
+func (_m *UserProfilesRepo) GetUserProfiles(_a0 entity.UserProfileFilter) ([]entity.UserProfile, error) {
+	ret := _m.Called(_a0)
+
+	var r0 []entity.UserProfile
+	var r1 error
+	if rf, ok := ret.Get(0).(func(entity.UserProfileFilter) ([]entity.UserProfile, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(entity.UserProfileFilter) []entity.UserProfile); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.UserProfile)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(entity.UserProfileFilter) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PatchUserProfile provides a mock function with given fields: userKsuid, version, columns
+func (_m *UserProfilesRepo) PatchUserProfile(userKsuid string, version int, columns map[string]interface{}) (*entity.UserProfile, error) {
+	ret := _m.Called(userKsuid, version, columns)
+
+	var r0 *entity.UserProfile
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int, map[string]interface{}) (*entity.UserProfile, error)); ok {
+		return rf(userKsuid, version, columns)
+	}
+	if rf, ok := ret.Get(0).(func(string, int, map[string]interface{}) *entity.UserProfile); ok {
+		r0 = rf(userKsuid, version, columns)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.UserProfile)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int, map[string]interface{}) error); ok {
+		r1 = rf(userKsuid, version, columns)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PurgeUserProfile provides a mock function with given fields: userKsuid, deletedBefore
+func (_m *UserProfilesRepo) PurgeUserProfile(userKsuid string, deletedBefore time.Time) error {
+	ret := _m.Called(userKsuid, deletedBefore)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, time.Time) error); ok {
+		r0 = rf(userKsuid, deletedBefore)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RestoreUserProfile provides a mock function with given fields: userKsuid, deletedSince
+func (_m *UserProfilesRepo) RestoreUserProfile(userKsuid string, deletedSince time.Time) error {
+	ret := _m.Called(userKsuid, deletedSince)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, time.Time) error); ok {
+		r0 = rf(userKsuid, deletedSince)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateUserProfile provides a mock function with given fields: _a0
 func (_m *UserProfilesRepo) UpdateUserProfile(_a0 entity.UserProfile) (*entity.UserProfile, error) {
 	ret := _m.Called(_a0)
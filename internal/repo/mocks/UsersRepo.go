@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	time "time"
+
 	entity "github.com/adesupraptolaia/user_login/internal/entity"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -12,6 +14,44 @@ type UsersRepo struct {
 	mock.Mock
 }
 
+// AcceptInvitation provides a mock function with given fields: ksuid, passwordHash
+func (_m *UsersRepo) AcceptInvitation(ksuid string, passwordHash string) error {
+	ret := _m.Called(ksuid, passwordHash)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(ksuid, passwordHash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CountUsersByRole provides a mock function with given fields: _a0
+func (_m *UsersRepo) CountUsersByRole(_a0 string) (int64, error) {
+	ret := _m.Called(_a0)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (int64, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(string) int64); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateUser provides a mock function with given fields: _a0
 func (_m *UsersRepo) CreateUser(_a0 entity.User) (*entity.User, error) {
 	ret := _m.Called(_a0)
@@ -38,6 +78,32 @@ func (_m *UsersRepo) CreateUser(_a0 entity.User) (*entity.User, error) {
 	return r0, r1
 }
 
+// DeleteInvitedUser provides a mock function with given fields: ksuid
+func (_m *UsersRepo) DeleteInvitedUser(ksuid string) (*entity.User, error) {
+	ret := _m.Called(ksuid)
+
+	var r0 *entity.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*entity.User, error)); ok {
+		return rf(ksuid)
+	}
+	if rf, ok := ret.Get(0).(func(string) *entity.User); ok {
+		r0 = rf(ksuid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(ksuid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteUser provides a mock function with given fields: _a0
 func (_m *UsersRepo) DeleteUser(_a0 string) (*entity.User, error) {
 	ret := _m.Called(_a0)
@@ -64,6 +130,84 @@ func (_m *UsersRepo) DeleteUser(_a0 string) (*entity.User, error) {
 	return r0, r1
 }
 
+// GetDeletedUsers provides a mock function with given fields: deletedBefore, limit
+func (_m *UsersRepo) GetDeletedUsers(deletedBefore time.Time, limit int) ([]entity.User, error) {
+	ret := _m.Called(deletedBefore, limit)
+
+	var r0 []entity.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time, int) ([]entity.User, error)); ok {
+		return rf(deletedBefore, limit)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time, int) []entity.User); ok {
+		r0 = rf(deletedBefore, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time, int) error); ok {
+		r1 = rf(deletedBefore, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetInvitedUsers provides a mock function with given fields:
+func (_m *UsersRepo) GetInvitedUsers() ([]entity.User, error) {
+	ret := _m.Called()
+
+	var r0 []entity.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]entity.User, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []entity.User); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserByEmail provides a mock function with given fields: _a0
+func (_m *UsersRepo) GetUserByEmail(_a0 string) (*entity.User, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *entity.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*entity.User, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(string) *entity.User); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetUserByKsuid provides a mock function with given fields: _a0
 func (_m *UsersRepo) GetUserByKsuid(_a0 string) (*entity.User, error) {
 	ret := _m.Called(_a0)
@@ -116,6 +260,74 @@ func (_m *UsersRepo) GetUserByUsername(_a0 string) (*entity.User, error) {
 	return r0, r1
 }
 
+// GetUsersByKsuids provides a mock function with given fields: ksuids, includeDeleted
+func (_m *UsersRepo) GetUsersByKsuids(ksuids []string, includeDeleted bool) ([]entity.User, error) {
+	ret := _m.Called(ksuids, includeDeleted)
+
+	var r0 []entity.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]string, bool) ([]entity.User, error)); ok {
+		return rf(ksuids, includeDeleted)
+	}
+	if rf, ok := ret.Get(0).(func([]string, bool) []entity.User); ok {
+		r0 = rf(ksuids, includeDeleted)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]string, bool) error); ok {
+		r1 = rf(ksuids, includeDeleted)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PurgeUser provides a mock function with given fields: ksuid, deletedBefore
+func (_m *UsersRepo) PurgeUser(ksuid string, deletedBefore time.Time) error {
+	ret := _m.Called(ksuid, deletedBefore)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, time.Time) error); ok {
+		r0 = rf(ksuid, deletedBefore)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RehashPassword provides a mock function with given fields: ksuid, oldHash, newHash
+func (_m *UsersRepo) RehashPassword(ksuid string, oldHash string, newHash string) error {
+	ret := _m.Called(ksuid, oldHash, newHash)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(ksuid, oldHash, newHash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RestoreUser provides a mock function with given fields: ksuid, deletedSince
+func (_m *UsersRepo) RestoreUser(ksuid string, deletedSince time.Time) error {
+	ret := _m.Called(ksuid, deletedSince)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, time.Time) error); ok {
+		r0 = rf(ksuid, deletedSince)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateUser provides a mock function with given fields: _a0, _a1
 func (_m *UsersRepo) UpdateUser(_a0 string, _a1 entity.User) (*entity.User, error) {
 	ret := _m.Called(_a0, _a1)
@@ -142,6 +354,20 @@ func (_m *UsersRepo) UpdateUser(_a0 string, _a1 entity.User) (*entity.User, erro
 	return r0, r1
 }
 
+// VerifyEmail provides a mock function with given fields: ksuid, email
+func (_m *UsersRepo) VerifyEmail(ksuid string, email string) error {
+	ret := _m.Called(ksuid, email)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(ksuid, email)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 type mockConstructorTestingTNewUsersRepo interface {
 	mock.TestingT
 	Cleanup(func())
@@ -0,0 +1,79 @@
+package repo
+
+import (
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/labstack/gommon/log"
+	"gorm.io/gorm"
+)
+
+type OAuthClientsRepo interface {
+	GetOAuthClients() ([]entity.OAuthClient, error)
+	GetOAuthClient(string) (*entity.OAuthClient, error)
+	CreateOAuthClient(entity.OAuthClient) (*entity.OAuthClient, error)
+	DeleteOAuthClient(string) error
+}
+
+type oauthClientRepo struct {
+	db *gorm.DB
+}
+
+func NewOAuthClient(db *gorm.DB) OAuthClientsRepo {
+	return &oauthClientRepo{
+		db: db.Table("oauth_clients").Debug(),
+	}
+}
+
+func (repo *oauthClientRepo) GetOAuthClients() ([]entity.OAuthClient, error) {
+	result := []entity.OAuthClient{}
+
+	err := repo.db.
+		Order("created_at").
+		Find(&result).Error
+	if err != nil {
+		log.Errorf("error when GetOAuthClients, err: %s", err.Error())
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (repo *oauthClientRepo) GetOAuthClient(clientID string) (*entity.OAuthClient, error) {
+	result := entity.OAuthClient{}
+
+	err := repo.db.
+		Where("client_id = ?", clientID).
+		First(&result).Error
+	if err != nil {
+		log.Errorf("error when GetOAuthClient, err: %s", err.Error())
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (repo *oauthClientRepo) CreateOAuthClient(client entity.OAuthClient) (*entity.OAuthClient, error) {
+	err := repo.db.Create(&client).Error
+	if err != nil {
+		log.Errorf("error when CreateOAuthClient, err: %s", err.Error())
+		return nil, err
+	}
+
+	return &client, nil
+}
+
+// DeleteOAuthClient fails with gorm.ErrRecordNotFound when the client is unknown
+func (repo *oauthClientRepo) DeleteOAuthClient(clientID string) error {
+	result := repo.db.
+		Where("client_id = ?", clientID).
+		Delete(&entity.OAuthClient{})
+	if result.Error != nil {
+		log.Errorf("error when DeleteOAuthClient, err: %s", result.Error.Error())
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
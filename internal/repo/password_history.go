@@ -0,0 +1,78 @@
+package repo
+
+import (
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/labstack/gommon/log"
+	"gorm.io/gorm"
+)
+
+type PasswordHistoriesRepo interface {
+	GetPasswordHistory(userKsuid string, limit int) ([]entity.PasswordHistory, error)
+	CreatePasswordHistory(entity.PasswordHistory) error
+	PrunePasswordHistory(userKsuid string, keep int) error
+}
+
+type passwordHistoryRepo struct {
+	db *gorm.DB
+}
+
+func NewPasswordHistory(db *gorm.DB) PasswordHistoriesRepo {
+	return &passwordHistoryRepo{
+		db: db.Table("password_histories").Debug(),
+	}
+}
+
+// GetPasswordHistory return the latest previous passwords of the user, newest first
+func (repo *passwordHistoryRepo) GetPasswordHistory(userKsuid string, limit int) ([]entity.PasswordHistory, error) {
+	result := []entity.PasswordHistory{}
+
+	err := repo.db.
+		Where("user_ksuid = ?", userKsuid).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&result).Error
+	if err != nil {
+		log.Errorf("error when GetPasswordHistory, err: %s", err.Error())
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (repo *passwordHistoryRepo) CreatePasswordHistory(passwordHistory entity.PasswordHistory) error {
+	err := repo.db.Create(&passwordHistory).Error
+	if err != nil {
+		log.Errorf("error when CreatePasswordHistory, err: %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// PrunePasswordHistory delete the previous passwords of the user older than the latest keep
+func (repo *passwordHistoryRepo) PrunePasswordHistory(userKsuid string, keep int) error {
+	ksuids := []string{}
+
+	err := repo.db.
+		Where("user_ksuid = ?", userKsuid).
+		Order("created_at DESC").
+		Pluck("ksuid", &ksuids).Error
+	if err != nil {
+		log.Errorf("error when PrunePasswordHistory, err: %s", err.Error())
+		return err
+	}
+
+	if len(ksuids) <= keep {
+		return nil
+	}
+
+	err = repo.db.
+		Where("ksuid IN ?", ksuids[keep:]).
+		Delete(&entity.PasswordHistory{}).Error
+	if err != nil {
+		log.Errorf("error when PrunePasswordHistory, err: %s", err.Error())
+		return err
+	}
+
+	return nil
+}
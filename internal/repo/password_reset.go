@@ -0,0 +1,82 @@
+package repo
+
+import (
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/labstack/gommon/log"
+	"gorm.io/gorm"
+)
+
+type PasswordResetsRepo interface {
+	CreatePasswordReset(entity.PasswordReset) (*entity.PasswordReset, error)
+	GetPasswordReset(string) (*entity.PasswordReset, error)
+	UsePasswordReset(string) error
+	UseUserPasswordResets(string) error
+}
+
+type passwordResetRepo struct {
+	db *gorm.DB
+}
+
+func NewPasswordReset(db *gorm.DB) PasswordResetsRepo {
+	return &passwordResetRepo{
+		db: db.Table("password_resets").Debug(),
+	}
+}
+
+func (repo *passwordResetRepo) CreatePasswordReset(passwordReset entity.PasswordReset) (*entity.PasswordReset, error) {
+	err := repo.db.Create(&passwordReset).Error
+	if err != nil {
+		log.Errorf("error when CreatePasswordReset, err: %s", err.Error())
+		return nil, err
+	}
+
+	return &passwordReset, nil
+}
+
+func (repo *passwordResetRepo) GetPasswordReset(tokenHash string) (*entity.PasswordReset, error) {
+	result := entity.PasswordReset{}
+
+	err := repo.db.
+		Where("token_hash = ?", tokenHash).
+		First(&result).Error
+	if err != nil {
+		log.Errorf("error when GetPasswordReset, err: %s", err.Error())
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// UsePasswordReset fails with gorm.ErrRecordNotFound when the token is unknown, used or expired
+func (repo *passwordResetRepo) UsePasswordReset(tokenHash string) error {
+	now := time.Now()
+
+	result := repo.db.
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		log.Errorf("error when UsePasswordReset, err: %s", result.Error.Error())
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// UseUserPasswordResets invalidate every pending token of the user
+func (repo *passwordResetRepo) UseUserPasswordResets(userKsuid string) error {
+	err := repo.db.
+		Where("user_ksuid = ? AND used_at IS NULL", userKsuid).
+		Update("used_at", time.Now()).Error
+	if err != nil {
+		log.Errorf("error when UseUserPasswordResets, err: %s", err.Error())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package repo
+
+import (
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/labstack/gommon/log"
+	"gorm.io/gorm"
+)
+
+type PermissionsRepo interface {
+	GetPermissions() ([]entity.Permission, error)
+	CreatePermission(entity.Permission) (*entity.Permission, error)
+}
+
+type permissionRepo struct {
+	db *gorm.DB
+}
+
+func NewPermission(db *gorm.DB) PermissionsRepo {
+	return &permissionRepo{
+		db: db.Table("permissions").Debug(),
+	}
+}
+
+func (repo *permissionRepo) GetPermissions() ([]entity.Permission, error) {
+	result := []entity.Permission{}
+
+	err := repo.db.
+		Order("name").
+		Find(&result).Error
+	if err != nil {
+		log.Errorf("error when GetPermissions, err: %s", err.Error())
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (repo *permissionRepo) CreatePermission(permission entity.Permission) (*entity.Permission, error) {
+	err := repo.db.Create(&permission).Error
+	if err != nil {
+		log.Errorf("error when CreatePermission, err: %s", err.Error())
+		return nil, err
+	}
+
+	return &permission, nil
+}
@@ -0,0 +1,58 @@
+package repo
+
+import (
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/labstack/gommon/log"
+	"gorm.io/gorm"
+)
+
+type RecoveryCodesRepo interface {
+	ReplaceRecoveryCodes(string, []entity.RecoveryCode) error
+	UseRecoveryCode(string, string) error
+}
+
+type recoveryCodeRepo struct {
+	db *gorm.DB
+}
+
+func NewRecoveryCode(db *gorm.DB) RecoveryCodesRepo {
+	return &recoveryCodeRepo{
+		db: db.Table("recovery_codes").Debug(),
+	}
+}
+
+// ReplaceRecoveryCodes delete every code of the user and save the new ones
+func (repo *recoveryCodeRepo) ReplaceRecoveryCodes(userKsuid string, codes []entity.RecoveryCode) error {
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_ksuid = ?", userKsuid).Delete(&entity.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&codes).Error
+	})
+	if err != nil {
+		log.Errorf("error when ReplaceRecoveryCodes, err: %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// UseRecoveryCode fails with gorm.ErrRecordNotFound when the code is unknown or used
+func (repo *recoveryCodeRepo) UseRecoveryCode(userKsuid, codeHash string) error {
+	result := repo.db.
+		Where("code_hash = ? AND user_ksuid = ? AND used_at IS NULL", codeHash, userKsuid).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		log.Errorf("error when UseRecoveryCode, err: %s", result.Error.Error())
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
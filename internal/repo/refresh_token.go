@@ -0,0 +1,93 @@
+package repo
+
+import (
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/labstack/gommon/log"
+	"gorm.io/gorm"
+)
+
+type RefreshTokensRepo interface {
+	GetRefreshToken(string) (*entity.RefreshToken, error)
+	CreateRefreshToken(entity.RefreshToken) (*entity.RefreshToken, error)
+	RevokeRefreshToken(string) error
+	RevokeFamily(string) error
+	RevokeUserRefreshTokens(string) error
+}
+
+type refreshTokenRepo struct {
+	db *gorm.DB
+}
+
+func NewRefreshToken(db *gorm.DB) RefreshTokensRepo {
+	return &refreshTokenRepo{
+		db: db.Table("refresh_tokens").Debug(),
+	}
+}
+
+func (repo *refreshTokenRepo) GetRefreshToken(jti string) (*entity.RefreshToken, error) {
+	result := entity.RefreshToken{}
+
+	err := repo.db.
+		Where("jti = ?", jti).
+		First(&result).Error
+	if err != nil {
+		log.Errorf("error when GetRefreshToken, err: %s", err.Error())
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (repo *refreshTokenRepo) CreateRefreshToken(refreshToken entity.RefreshToken) (*entity.RefreshToken, error) {
+	err := repo.db.Create(&refreshToken).Error
+	if err != nil {
+		log.Errorf("error when CreateRefreshToken, err: %s", err.Error())
+		return nil, err
+	}
+
+	return &refreshToken, nil
+}
+
+// RevokeRefreshToken return gorm.ErrRecordNotFound when the token is already revoked,
+// so two concurrent rotations of the same token can't both succeed
+func (repo *refreshTokenRepo) RevokeRefreshToken(jti string) error {
+	result := repo.db.
+		Where("jti = ? AND revoked_at IS NULL", jti).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		log.Errorf("error when RevokeRefreshToken, err: %s", result.Error.Error())
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (repo *refreshTokenRepo) RevokeFamily(familyKsuid string) error {
+	err := repo.db.
+		Where("family_ksuid = ? AND revoked_at IS NULL", familyKsuid).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		log.Errorf("error when RevokeFamily, err: %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (repo *refreshTokenRepo) RevokeUserRefreshTokens(userKsuid string) error {
+	err := repo.db.
+		Where("user_ksuid = ? AND revoked_at IS NULL", userKsuid).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		log.Errorf("error when RevokeUserRefreshTokens, err: %s", err.Error())
+		return err
+	}
+
+	return nil
+}
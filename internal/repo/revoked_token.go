@@ -0,0 +1,84 @@
+package repo
+
+import (
+	"errors"
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/labstack/gommon/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RevokedTokensRepo interface {
+	RevokeToken(entity.RevokedToken) error
+	UseToken(entity.RevokedToken) (bool, error)
+	IsTokenRevoked(string) (bool, error)
+}
+
+type revokedTokenRepo struct {
+	db *gorm.DB
+}
+
+func NewRevokedToken(db *gorm.DB) RevokedTokensRepo {
+	return &revokedTokenRepo{
+		db: db.Table("revoked_tokens").Debug(),
+	}
+}
+
+// RevokeToken add the token to the denylist, and drop the tokens that have expired since
+func (repo *revokedTokenRepo) RevokeToken(revokedToken entity.RevokedToken) error {
+	err := repo.db.
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&revokedToken).Error
+	if err != nil {
+		log.Errorf("error when RevokeToken, err: %s", err.Error())
+		return err
+	}
+
+	repo.deleteExpiredTokens()
+
+	return nil
+}
+
+// UseToken add a single-use token to the denylist, and report whether this call added it.
+// A concurrent use of the same token finds it there and is refused
+func (repo *revokedTokenRepo) UseToken(usedToken entity.RevokedToken) (bool, error) {
+	result := repo.db.
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&usedToken)
+	if result.Error != nil {
+		log.Errorf("error when UseToken, err: %s", result.Error.Error())
+		return false, result.Error
+	}
+
+	repo.deleteExpiredTokens()
+
+	return result.RowsAffected == 1, nil
+}
+
+func (repo *revokedTokenRepo) deleteExpiredTokens() {
+	err := repo.db.
+		Where("expires_at < ?", time.Now()).
+		Delete(&entity.RevokedToken{}).Error
+	if err != nil {
+		log.Errorf("error when delete expired revoked tokens, err: %s", err.Error())
+	}
+}
+
+func (repo *revokedTokenRepo) IsTokenRevoked(jti string) (bool, error) {
+	result := entity.RevokedToken{}
+
+	err := repo.db.
+		Where("jti = ?", jti).
+		First(&result).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		log.Errorf("error when IsTokenRevoked, err: %s", err.Error())
+		return false, err
+	}
+
+	return true, nil
+}
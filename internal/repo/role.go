@@ -0,0 +1,144 @@
+package repo
+
+import (
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/labstack/gommon/log"
+	"gorm.io/gorm"
+)
+
+type RolesRepo interface {
+	GetRoles() ([]entity.Role, error)
+	GetRole(string) (*entity.Role, error)
+	GetRolePermissions(string) ([]string, error)
+	CreateRole(entity.Role) (*entity.Role, error)
+	SetRolePermissions(string, []string) error
+	DeleteRole(string) error
+}
+
+type roleRepo struct {
+	db *gorm.DB
+}
+
+func NewRole(db *gorm.DB) RolesRepo {
+	return &roleRepo{
+		db: db.Table("roles").Debug(),
+	}
+}
+
+func (repo *roleRepo) GetRoles() ([]entity.Role, error) {
+	result := []entity.Role{}
+
+	err := repo.db.
+		Order("name").
+		Find(&result).Error
+	if err != nil {
+		log.Errorf("error when GetRoles, err: %s", err.Error())
+		return nil, err
+	}
+
+	for i := range result {
+		result[i].Permissions, err = repo.GetRolePermissions(result[i].Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func (repo *roleRepo) GetRole(name string) (*entity.Role, error) {
+	result := entity.Role{}
+
+	err := repo.db.
+		Where("name = ?", name).
+		First(&result).Error
+	if err != nil {
+		log.Errorf("error when GetRole, err: %s", err.Error())
+		return nil, err
+	}
+
+	result.Permissions, err = repo.GetRolePermissions(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (repo *roleRepo) GetRolePermissions(name string) ([]string, error) {
+	result := []string{}
+
+	err := repo.db.
+		Table("role_permissions").
+		Where("role = ?", name).
+		Order("permission").
+		Pluck("permission", &result).Error
+	if err != nil {
+		log.Errorf("error when GetRolePermissions, err: %s", err.Error())
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (repo *roleRepo) CreateRole(role entity.Role) (*entity.Role, error) {
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&role).Error; err != nil {
+			return err
+		}
+
+		return setRolePermissions(tx, role.Name, role.Permissions)
+	})
+	if err != nil {
+		log.Errorf("error when CreateRole, err: %s", err.Error())
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+// SetRolePermissions replace every permission of the role
+func (repo *roleRepo) SetRolePermissions(name string, permissions []string) error {
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		return setRolePermissions(tx, name, permissions)
+	})
+	if err != nil {
+		log.Errorf("error when SetRolePermissions, err: %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (repo *roleRepo) DeleteRole(name string) error {
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table("role_permissions").Where("role = ?", name).Delete(&entity.RolePermission{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Table("roles").Where("name = ?", name).Delete(&entity.Role{}).Error
+	})
+	if err != nil {
+		log.Errorf("error when DeleteRole, err: %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func setRolePermissions(tx *gorm.DB, name string, permissions []string) error {
+	if err := tx.Table("role_permissions").Where("role = ?", name).Delete(&entity.RolePermission{}).Error; err != nil {
+		return err
+	}
+
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	rolePermissions := make([]entity.RolePermission, len(permissions))
+	for i, permission := range permissions {
+		rolePermissions[i] = entity.RolePermission{Role: name, Permission: permission}
+	}
+
+	return tx.Table("role_permissions").Create(&rolePermissions).Error
+}
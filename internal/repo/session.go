@@ -0,0 +1,74 @@
+package repo
+
+import (
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/labstack/gommon/log"
+	"gorm.io/gorm"
+)
+
+type SessionsRepo interface {
+	GetSession(string) (*entity.Session, error)
+	CreateSession(entity.Session) (*entity.Session, error)
+	RevokeSession(string) error
+	RevokeUserSessions(string) error
+}
+
+type sessionRepo struct {
+	db *gorm.DB
+}
+
+func NewSession(db *gorm.DB) SessionsRepo {
+	return &sessionRepo{
+		db: db.Table("sessions").Debug(),
+	}
+}
+
+func (repo *sessionRepo) GetSession(ksuid string) (*entity.Session, error) {
+	result := entity.Session{}
+
+	err := repo.db.
+		Where("ksuid = ?", ksuid).
+		First(&result).Error
+	if err != nil {
+		log.Errorf("error when GetSession, err: %s", err.Error())
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (repo *sessionRepo) CreateSession(session entity.Session) (*entity.Session, error) {
+	err := repo.db.Create(&session).Error
+	if err != nil {
+		log.Errorf("error when CreateSession, err: %s", err.Error())
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (repo *sessionRepo) RevokeSession(ksuid string) error {
+	err := repo.db.
+		Where("ksuid = ? AND revoked_at IS NULL", ksuid).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		log.Errorf("error when RevokeSession, err: %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (repo *sessionRepo) RevokeUserSessions(userKsuid string) error {
+	err := repo.db.
+		Where("user_ksuid = ? AND revoked_at IS NULL", userKsuid).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		log.Errorf("error when RevokeUserSessions, err: %s", err.Error())
+		return err
+	}
+
+	return nil
+}
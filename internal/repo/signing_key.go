@@ -0,0 +1,64 @@
+package repo
+
+import (
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/labstack/gommon/log"
+	"gorm.io/gorm"
+)
+
+type SigningKeysRepo interface {
+	GetSigningKeys(string, time.Time) ([]entity.SigningKey, error)
+	CreateSigningKey(entity.SigningKey) (*entity.SigningKey, error)
+	RetireSigningKeys(string, string) error
+}
+
+type signingKeyRepo struct {
+	db *gorm.DB
+}
+
+func NewSigningKey(db *gorm.DB) SigningKeysRepo {
+	return &signingKeyRepo{
+		db: db.Table("signing_keys").Debug(),
+	}
+}
+
+// GetSigningKeys return active keys of the token type and keys retired after retiredSince, newest first
+func (repo *signingKeyRepo) GetSigningKeys(tokenType string, retiredSince time.Time) ([]entity.SigningKey, error) {
+	result := []entity.SigningKey{}
+
+	err := repo.db.
+		Where("token_type = ? AND (retired_at IS NULL OR retired_at > ?)", tokenType, retiredSince).
+		Order("created_at DESC").
+		Find(&result).Error
+	if err != nil {
+		log.Errorf("error when GetSigningKeys, err: %s", err.Error())
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (repo *signingKeyRepo) CreateSigningKey(signingKey entity.SigningKey) (*entity.SigningKey, error) {
+	err := repo.db.Create(&signingKey).Error
+	if err != nil {
+		log.Errorf("error when CreateSigningKey, err: %s", err.Error())
+		return nil, err
+	}
+
+	return &signingKey, nil
+}
+
+// RetireSigningKeys retire every active key of the token type except exceptKid
+func (repo *signingKeyRepo) RetireSigningKeys(tokenType, exceptKid string) error {
+	err := repo.db.
+		Where("token_type = ? AND kid <> ? AND retired_at IS NULL", tokenType, exceptKid).
+		Update("retired_at", time.Now()).Error
+	if err != nil {
+		log.Errorf("error when RetireSigningKeys, err: %s", err.Error())
+		return err
+	}
+
+	return nil
+}
@@ -1,6 +1,8 @@
 package repo
 
 import (
+	"time"
+
 	"github.com/adesupraptolaia/user_login/internal/entity"
 	"github.com/labstack/gommon/log"
 	"gorm.io/gorm"
@@ -9,9 +11,20 @@ import (
 type UsersRepo interface {
 	GetUserByKsuid(string) (*entity.User, error)
 	GetUserByUsername(string) (*entity.User, error)
+	GetUserByEmail(string) (*entity.User, error)
+	GetUsersByKsuids(ksuids []string, includeDeleted bool) ([]entity.User, error)
+	CountUsersByRole(string) (int64, error)
 	CreateUser(entity.User) (*entity.User, error)
 	UpdateUser(string, entity.User) (*entity.User, error)
+	RehashPassword(ksuid, oldHash, newHash string) error
+	VerifyEmail(ksuid, email string) error
+	GetInvitedUsers() ([]entity.User, error)
+	AcceptInvitation(ksuid, passwordHash string) error
+	DeleteInvitedUser(ksuid string) (*entity.User, error)
 	DeleteUser(string) (*entity.User, error)
+	RestoreUser(ksuid string, deletedSince time.Time) error
+	GetDeletedUsers(deletedBefore time.Time, limit int) ([]entity.User, error)
+	PurgeUser(ksuid string, deletedBefore time.Time) error
 }
 
 type userRepo struct {
@@ -24,11 +37,12 @@ func NewUser(db *gorm.DB) UsersRepo {
 	}
 }
 
+// GetUserByKsuid get the user, unless it is deleted
 func (repo *userRepo) GetUserByKsuid(ksuid string) (*entity.User, error) {
 	result := entity.User{}
 
 	err := repo.db.
-		Where("ksuid = ?", ksuid).
+		Where("ksuid = ? AND deleted_at IS NULL", ksuid).
 		First(&result).Error
 	if err != nil {
 		log.Errorf("error when GetUserByKsuid, err: %s", err.Error())
@@ -38,6 +52,7 @@ func (repo *userRepo) GetUserByKsuid(ksuid string) (*entity.User, error) {
 	return &result, err
 }
 
+// GetUserByUsername get the user, a deleted one included since its username is taken until it is purged
 func (repo *userRepo) GetUserByUsername(username string) (*entity.User, error) {
 	result := entity.User{}
 
@@ -52,6 +67,54 @@ func (repo *userRepo) GetUserByUsername(username string) (*entity.User, error) {
 	return &result, err
 }
 
+// GetUserByEmail get the user, a deleted one included since its email is taken until it is purged
+func (repo *userRepo) GetUserByEmail(email string) (*entity.User, error) {
+	result := entity.User{}
+
+	err := repo.db.
+		Where("email = ?", email).
+		First(&result).Error
+	if err != nil {
+		log.Errorf("error when GetUserByEmail, err: %s", err.Error())
+		return nil, err
+	}
+
+	return &result, err
+}
+
+// GetUsersByKsuids get the users among the ksuids, the deleted ones not purged yet only with includeDeleted
+func (repo *userRepo) GetUsersByKsuids(ksuids []string, includeDeleted bool) ([]entity.User, error) {
+	result := []entity.User{}
+
+	query := repo.db.Where("ksuid IN ?", ksuids)
+	if !includeDeleted {
+		query = query.Where("deleted_at IS NULL")
+	}
+
+	err := query.Find(&result).Error
+	if err != nil {
+		log.Errorf("error when GetUsersByKsuids, err: %s", err.Error())
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// CountUsersByRole count the users of the role, a deleted one included since it can be restored until it is purged
+func (repo *userRepo) CountUsersByRole(role string) (int64, error) {
+	var count int64
+
+	err := repo.db.
+		Where("role = ?", role).
+		Count(&count).Error
+	if err != nil {
+		log.Errorf("error when CountUsersByRole, err: %s", err.Error())
+		return 0, err
+	}
+
+	return count, nil
+}
+
 func (repo *userRepo) CreateUser(user entity.User) (*entity.User, error) {
 	err := repo.db.Create(&user).Error
 	if err != nil {
@@ -74,16 +137,187 @@ func (repo *userRepo) UpdateUser(ksuid string, user entity.User) (*entity.User,
 	return &user, nil
 }
 
+// RehashPassword replace the password hash unless the password was changed meanwhile
+func (repo *userRepo) RehashPassword(ksuid, oldHash, newHash string) error {
+	result := repo.db.
+		Where("ksuid = ? AND password = ?", ksuid, oldHash).
+		Update("password", newHash)
+	if result.Error != nil {
+		log.Errorf("error when RehashPassword, err: %s", result.Error.Error())
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// VerifyEmail mark the email address verified, unless the user has another address meanwhile
+func (repo *userRepo) VerifyEmail(ksuid, email string) error {
+	result := repo.db.
+		Where("ksuid = ? AND email = ?", ksuid, email).
+		Update("email_verified_at", time.Now())
+	if result.Error != nil {
+		log.Errorf("error when VerifyEmail, err: %s", result.Error.Error())
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (repo *userRepo) GetInvitedUsers() ([]entity.User, error) {
+	result := []entity.User{}
+
+	err := repo.db.
+		Where("invited_at IS NOT NULL AND deleted_at IS NULL").
+		Order("invited_at").
+		Find(&result).Error
+	if err != nil {
+		log.Errorf("error when GetInvitedUsers, err: %s", err.Error())
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// AcceptInvitation set the first password of the invited user, only once
+func (repo *userRepo) AcceptInvitation(ksuid, passwordHash string) error {
+	result := repo.db.
+		Where("ksuid = ? AND invited_at IS NOT NULL", ksuid).
+		Updates(map[string]interface{}{
+			"password":            passwordHash,
+			"password_changed_at": time.Now().Truncate(time.Second),
+			"invited_at":          nil,
+		})
+	if result.Error != nil {
+		log.Errorf("error when AcceptInvitation, err: %s", result.Error.Error())
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// DeleteInvitedUser delete the user, unless the invitation is accepted meanwhile
+func (repo *userRepo) DeleteInvitedUser(ksuid string) (*entity.User, error) {
+	user := &entity.User{}
+
+	result := repo.db.
+		Where("ksuid = ? AND invited_at IS NOT NULL", ksuid).
+		Delete(user)
+	if result.Error != nil {
+		log.Errorf("error when DeleteInvitedUser, err: %s", result.Error.Error())
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return user, nil
+}
+
+// DeleteUser mark the user deleted, the user can be restored until it is purged
 func (repo *userRepo) DeleteUser(ksuid string) (*entity.User, error) {
 	user := &entity.User{}
 
+	result := repo.db.
+		Where("ksuid = ? AND role <> ? AND deleted_at IS NULL", ksuid, entity.ADMIN).
+		Update("deleted_at", time.Now().Truncate(time.Second))
+	if result.Error != nil {
+		log.Errorf("error when DeleteUser, err: %s", result.Error.Error())
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return user, nil
+}
+
+// RestoreUser undo the deletion of the user, unless it is not deleted or deleted before deletedSince
+func (repo *userRepo) RestoreUser(ksuid string, deletedSince time.Time) error {
+	result := repo.db.
+		Where("ksuid = ? AND deleted_at >= ?", ksuid, deletedSince).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		log.Errorf("error when RestoreUser, err: %s", result.Error.Error())
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// GetDeletedUsers get up to limit users deleted before deletedBefore, the oldest first
+func (repo *userRepo) GetDeletedUsers(deletedBefore time.Time, limit int) ([]entity.User, error) {
+	result := []entity.User{}
+
 	err := repo.db.
-		Where("ksuid = ? AND role = ?", ksuid, entity.USER).
-		Delete(user).Error
+		Where("deleted_at < ?", deletedBefore).
+		Order("deleted_at").
+		Limit(limit).
+		Find(&result).Error
 	if err != nil {
-		log.Errorf("error when DeleteUser, err: %s", err.Error())
+		log.Errorf("error when GetDeletedUsers, err: %s", err.Error())
 		return nil, err
 	}
 
-	return user, nil
+	return result, nil
+}
+
+// PurgeUser permanently delete the user deleted before deletedBefore, with its rows in the other tables
+// in the same transaction
+func (repo *userRepo) PurgeUser(ksuid string, deletedBefore time.Time) error {
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		user := entity.User{}
+		if err := tx.Where("ksuid = ? AND deleted_at < ?", ksuid, deletedBefore).First(&user).Error; err != nil {
+			return err
+		}
+
+		dependents := []struct {
+			table string
+			value interface{}
+		}{
+			{"sessions", &entity.Session{}},
+			{"refresh_tokens", &entity.RefreshToken{}},
+			{"mfa_secrets", &entity.MfaSecret{}},
+			{"recovery_codes", &entity.RecoveryCode{}},
+			{"password_histories", &entity.PasswordHistory{}},
+			{"password_resets", &entity.PasswordReset{}},
+			{"authorization_codes", &entity.AuthorizationCode{}},
+		}
+		for _, dependent := range dependents {
+			if err := tx.Table(dependent.table).Where("user_ksuid = ?", ksuid).Delete(dependent.value).Error; err != nil {
+				return err
+			}
+		}
+
+		// the failed logins are counted by username, see entity.LoginAttempt
+		err := tx.Table("login_attempts").Where("`key` = ?", "username:"+user.Username).Delete(&entity.LoginAttempt{}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("ksuid = ?", ksuid).Delete(&entity.User{}).Error
+	})
+	if err != nil {
+		log.Errorf("error when PurgeUser, err: %s", err.Error())
+		return err
+	}
+
+	return nil
 }
@@ -0,0 +1,67 @@
+package repo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/adesupraptolaia/user_login/config"
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/labstack/gommon/log"
+)
+
+type UserAppRepo interface {
+	GetUserProfile(string, string) (*entity.UserProfile, error)
+}
+
+type userAppRepo struct{}
+
+func NewUserAppRepo() UserAppRepo {
+	return &userAppRepo{}
+}
+
+type UserAppResponse struct {
+	Status       string              `json:"status"`
+	ErrorMessage string              `json:"error_message"`
+	Data         *entity.UserProfile `json:"data"`
+}
+
+// GetUserProfile get the profile from user app on behalf of the user,
+// with the access token of the user, so user app applies its own permissions
+func (repo *userAppRepo) GetUserProfile(userKsuid, accessToken string) (*entity.UserProfile, error) {
+	log.Infof("get user profile with ksuid %s from user service", userKsuid)
+
+	url := fmt.Sprintf("http://%s/user/%s", config.Config.UserServiceUrl, userKsuid)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error when make http request, err: %s", err.Error())
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error when calling to user service, err: %s", err.Error())
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error when read response body, err: %s", err.Error())
+	}
+
+	var response UserAppResponse
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return nil, fmt.Errorf("error when unmarshal response body with err %s", err.Error())
+	}
+
+	if response.Status != "success" || response.Data == nil {
+		return nil, fmt.Errorf(response.ErrorMessage)
+	}
+
+	return response.Data, nil
+}
@@ -1,6 +1,10 @@
 package repo
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/adesupraptolaia/user_login/internal/entity"
 	"github.com/labstack/gommon/log"
 	"gorm.io/gorm"
@@ -8,9 +12,15 @@ import (
 
 type UserProfilesRepo interface {
 	GetUserProfile(string) (*entity.UserProfile, error)
+	GetUserProfiles(entity.UserProfileFilter) ([]entity.UserProfile, error)
 	CreateUserProfile(entity.UserProfile) (*entity.UserProfile, error)
 	UpdateUserProfile(entity.UserProfile) (*entity.UserProfile, error)
+	PatchUserProfile(userKsuid string, version int, columns map[string]interface{}) (*entity.UserProfile, error)
 	DeleteUserProfile(string) (*entity.UserProfile, error)
+	GetDeletedUserProfile(userKsuid string) (*entity.UserProfile, error)
+	GetDeletedUserProfiles(deletedBefore time.Time, limit int) ([]entity.UserProfile, error)
+	RestoreUserProfile(userKsuid string, deletedSince time.Time) error
+	PurgeUserProfile(userKsuid string, deletedBefore time.Time) error
 }
 
 type userProfileRepo struct {
@@ -23,10 +33,13 @@ func NewUserProfile(db *gorm.DB) UserProfilesRepo {
 	}
 }
 
+// GetUserProfile get the profile, unless it is deleted
 func (repo *userProfileRepo) GetUserProfile(userKsuid string) (*entity.UserProfile, error) {
-	result := entity.UserProfile{UserKsuid: userKsuid}
+	result := entity.UserProfile{}
 
-	err := repo.db.First(&result).Error
+	err := repo.db.
+		Where("user_ksuid = ? AND deleted_at IS NULL", userKsuid).
+		First(&result).Error
 	if err != nil {
 		log.Errorf("error when GetUserProfile, err: %s", err.Error())
 		return nil, err
@@ -35,7 +48,69 @@ func (repo *userProfileRepo) GetUserProfile(userKsuid string) (*entity.UserProfi
 	return &result, err
 }
 
+// columns the user_profiles can be sorted by, user_ksuid breaks the ties
+var userProfileSortColumns = map[string]bool{
+	"user_ksuid":    true,
+	"name":          true,
+	"date_of_birth": true,
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (repo *userProfileRepo) GetUserProfiles(filter entity.UserProfileFilter) ([]entity.UserProfile, error) {
+	if !userProfileSortColumns[filter.SortBy] {
+		return nil, fmt.Errorf("unknown sort column %s", filter.SortBy)
+	}
+
+	query := repo.db.Where("deleted_at IS NULL")
+	if filter.Name != "" {
+		query = query.Where("name LIKE ?", "%"+likeEscaper.Replace(filter.Name)+"%")
+	}
+	if filter.Address != "" {
+		query = query.Where("address = ?", filter.Address)
+	}
+	if filter.DateOfBirthFrom != "" {
+		query = query.Where("date_of_birth >= ?", filter.DateOfBirthFrom)
+	}
+	if filter.DateOfBirthTo != "" {
+		query = query.Where("date_of_birth <= ?", filter.DateOfBirthTo)
+	}
+
+	direction, operator := "ASC", ">"
+	if filter.Desc {
+		direction, operator = "DESC", "<"
+	}
+
+	if filter.AfterKsuid != "" {
+		if filter.SortBy == "user_ksuid" {
+			query = query.Where(fmt.Sprintf("user_ksuid %s ?", operator), filter.AfterKsuid)
+		} else {
+			query = query.Where(fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND user_ksuid %[2]s ?))", filter.SortBy, operator),
+				filter.AfterValue, filter.AfterValue, filter.AfterKsuid)
+		}
+	}
+
+	query = query.Order(fmt.Sprintf("%s %s", filter.SortBy, direction))
+	if filter.SortBy != "user_ksuid" {
+		query = query.Order(fmt.Sprintf("user_ksuid %s", direction))
+	}
+
+	result := []entity.UserProfile{}
+
+	err := query.Limit(filter.Limit).Find(&result).Error
+	if err != nil {
+		log.Errorf("error when GetUserProfiles, err: %s", err.Error())
+		return nil, err
+	}
+
+	return result, nil
+}
+
 func (repo *userProfileRepo) CreateUserProfile(userProfile entity.UserProfile) (*entity.UserProfile, error) {
+	userProfile.Version = 1
+	userProfile.UpdatedAt = time.Now().Truncate(time.Second)
+	userProfile.DeletedAt = nil
+
 	err := repo.db.Create(&userProfile).Error
 	if err != nil {
 		log.Errorf("error when CreateUserProfile, err: %s", err.Error())
@@ -45,27 +120,133 @@ func (repo *userProfileRepo) CreateUserProfile(userProfile entity.UserProfile) (
 	return &userProfile, err
 }
 
+// UpdateUserProfile replace the profile, unless it was updated since userProfile.Version
 func (repo *userProfileRepo) UpdateUserProfile(userProfile entity.UserProfile) (*entity.UserProfile, error) {
-	err := repo.db.Save(&userProfile).Error
-	if err != nil {
-		log.Errorf("error when UpdateUserProfile+, err: %s", err.Error())
-		return nil, err
+	return repo.PatchUserProfile(userProfile.UserKsuid, userProfile.Version, map[string]interface{}{
+		"name":          userProfile.Name,
+		"date_of_birth": userProfile.DateOfBirth,
+		"address":       userProfile.Address,
+	})
+}
+
+// PatchUserProfile update only the given columns of the profile, unless it was updated since version,
+// then gorm.ErrRecordNotFound is returned
+func (repo *userProfileRepo) PatchUserProfile(userKsuid string, version int, columns map[string]interface{}) (*entity.UserProfile, error) {
+	values := map[string]interface{}{
+		"version":    gorm.Expr("version + 1"),
+		"updated_at": time.Now().Truncate(time.Second),
+	}
+	for column, value := range columns {
+		values[column] = value
+	}
+
+	result := repo.db.
+		Where("user_ksuid = ? AND version = ? AND deleted_at IS NULL", userKsuid, version).
+		Updates(values)
+	if result.Error != nil {
+		log.Errorf("error when PatchUserProfile, err: %s", result.Error.Error())
+		return nil, result.Error
 	}
 
-	return &userProfile, nil
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return repo.GetUserProfile(userKsuid)
 }
 
+// DeleteUserProfile mark the profile deleted, it can be restored until it is purged
 func (repo *userProfileRepo) DeleteUserProfile(userKsuid string) (*entity.UserProfile, error) {
 	userProfile, err := repo.GetUserProfile(userKsuid)
 	if err != nil {
 		return nil, err
 	}
 
-	err = repo.db.Delete(&entity.UserProfile{UserKsuid: userKsuid}).Error
+	// DATETIME keeps whole seconds only
+	deletedAt := time.Now().Truncate(time.Second)
+
+	result := repo.db.
+		Where("user_ksuid = ? AND deleted_at IS NULL", userKsuid).
+		Update("deleted_at", deletedAt)
+	if result.Error != nil {
+		log.Errorf("error when DeleteUserProfile, err: %s", result.Error.Error())
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	userProfile.DeletedAt = &deletedAt
+
+	return userProfile, nil
+}
+
+func (repo *userProfileRepo) GetDeletedUserProfile(userKsuid string) (*entity.UserProfile, error) {
+	result := entity.UserProfile{}
+
+	err := repo.db.
+		Where("user_ksuid = ? AND deleted_at IS NOT NULL", userKsuid).
+		First(&result).Error
 	if err != nil {
-		log.Errorf("error when DeleteUserProfile, err: %s", err.Error())
+		log.Errorf("error when GetDeletedUserProfile, err: %s", err.Error())
 		return nil, err
 	}
 
-	return userProfile, nil
+	return &result, nil
+}
+
+// GetDeletedUserProfiles get up to limit profiles deleted before deletedBefore, the oldest first
+func (repo *userProfileRepo) GetDeletedUserProfiles(deletedBefore time.Time, limit int) ([]entity.UserProfile, error) {
+	result := []entity.UserProfile{}
+
+	err := repo.db.
+		Where("deleted_at < ?", deletedBefore).
+		Order("deleted_at").
+		Limit(limit).
+		Find(&result).Error
+	if err != nil {
+		log.Errorf("error when GetDeletedUserProfiles, err: %s", err.Error())
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// RestoreUserProfile undo the deletion of the profile, only when it was deleted since deletedSince
+func (repo *userProfileRepo) RestoreUserProfile(userKsuid string, deletedSince time.Time) error {
+	result := repo.db.
+		Where("user_ksuid = ? AND deleted_at >= ?", userKsuid, deletedSince).
+		Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"version":    gorm.Expr("version + 1"),
+			"updated_at": time.Now().Truncate(time.Second),
+		})
+	if result.Error != nil {
+		log.Errorf("error when RestoreUserProfile, err: %s", result.Error.Error())
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// PurgeUserProfile permanently delete the profile, only when it was deleted before deletedBefore
+func (repo *userProfileRepo) PurgeUserProfile(userKsuid string, deletedBefore time.Time) error {
+	result := repo.db.
+		Where("user_ksuid = ? AND deleted_at < ?", userKsuid, deletedBefore).
+		Delete(&entity.UserProfile{})
+	if result.Error != nil {
+		log.Errorf("error when PurgeUserProfile, err: %s", result.Error.Error())
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
 }
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/adesupraptolaia/user_login/internal/repo"
+	"github.com/adesupraptolaia/user_login/pkg/jwt"
+	"github.com/adesupraptolaia/user_login/pkg/notifier"
+)
+
+type EmailVerificationUC interface {
+	SendVerification(*entity.User) error
+	ResendVerification(username string) error
+	VerifyEmail(token string) (*entity.User, error)
+	CheckLogin(*entity.User) error
+}
+
+var (
+	ErrEmailNotVerified         = errors.New("email address is not verified")
+	ErrInvalidVerificationToken = errors.New("invalid or expired email verification token")
+)
+
+type emailVerification struct {
+	userRepo repo.UsersRepo
+	notifier notifier.Notifier
+	// page of the client app verifying the email, the token is added as query parameter
+	verifyURL string
+	// users without a verified email address can't login
+	requiredForLogin bool
+}
+
+func NewEmailVerification(userRepo repo.UsersRepo, notifier notifier.Notifier, verifyURL string, requiredForLogin bool) EmailVerificationUC {
+	return &emailVerification{
+		userRepo:         userRepo,
+		notifier:         notifier,
+		verifyURL:        verifyURL,
+		requiredForLogin: requiredForLogin,
+	}
+}
+
+// SendVerification send a signed link to the email address of the user,
+// nothing is sent when the user has no address or has verified it already
+func (uc *emailVerification) SendVerification(user *entity.User) error {
+	if user.Email == nil || user.IsEmailVerified() {
+		return nil
+	}
+
+	token, err := jwt.CreateEmailVerificationToken(user.Ksuid, *user.Email)
+	if err != nil {
+		return fmt.Errorf("failed when create email verification token")
+	}
+
+	body := fmt.Sprintf("Hi %s,\n\nUse this token to verify your email address, it expires at %s:\n\n%s\n",
+		user.Username, time.Now().Add(jwt.EMAIL_TOKEN_TTL).Format(time.RFC1123), token)
+	if uc.verifyURL != "" {
+		body += fmt.Sprintf("\nor open %s?token=%s\n", uc.verifyURL, url.QueryEscape(token))
+	}
+
+	err = uc.notifier.Notify(notifier.Message{
+		To:      *user.Email,
+		Subject: "Verify your email address",
+		Body:    body,
+	})
+	if err != nil {
+		return fmt.Errorf("failed when send email verification to user with ksuid %s", user.Ksuid)
+	}
+
+	return nil
+}
+
+// ResendVerification is SendVerification by username. An unknown username
+// is not an error, the caller must not learn which usernames exist.
+func (uc *emailVerification) ResendVerification(username string) error {
+	user, err := uc.userRepo.GetUserByUsername(username)
+	if err != nil || user.IsDeleted() {
+		return nil
+	}
+
+	return uc.SendVerification(user)
+}
+
+// VerifyEmail mark the email address of the token verified. The token is
+// no longer valid once the user has another address.
+func (uc *emailVerification) VerifyEmail(token string) (*entity.User, error) {
+	claims, err := jwt.ValidateEmailVerificationToken(token)
+	if err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	user, err := uc.userRepo.GetUserByKsuid(claims.UserKsuid)
+	if err != nil || user.Email == nil || *user.Email != claims.Email {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	if user.IsEmailVerified() {
+		return user, nil
+	}
+
+	if err := uc.userRepo.VerifyEmail(user.Ksuid, claims.Email); err != nil {
+		return nil, fmt.Errorf("failed when verify email of user with ksuid %s", user.Ksuid)
+	}
+
+	verifiedAt := time.Now()
+	user.EmailVerifiedAt = &verifiedAt
+
+	return user, nil
+}
+
+// CheckLogin fails with ErrEmailNotVerified when a verified email address is required to login,
+// for every user or for this one
+func (uc *emailVerification) CheckLogin(user *entity.User) error {
+	if (uc.requiredForLogin || user.EmailVerificationRequired) && !user.IsEmailVerified() {
+		return ErrEmailNotVerified
+	}
+
+	return nil
+}
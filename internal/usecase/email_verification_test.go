@@ -0,0 +1,217 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	repoMocks "github.com/adesupraptolaia/user_login/internal/repo/mocks"
+	"github.com/adesupraptolaia/user_login/pkg/jwt"
+)
+
+func Test_emailVerification_SendVerification(t *testing.T) {
+	email := "user@example.com"
+	verifiedAt := time.Now()
+
+	type fields struct {
+		notifier *fakeNotifier
+	}
+	type args struct {
+		user *entity.User
+	}
+	tests := []struct {
+		name         string
+		fields       fields
+		args         args
+		wantMessages int
+		wantErr      bool
+	}{
+		{
+			name:         "Success Send Verification",
+			fields:       fields{&fakeNotifier{}},
+			args:         args{&entity.User{Ksuid: "ksuid", Username: "user", Email: &email}},
+			wantMessages: 1,
+			wantErr:      false,
+		},
+		{
+			name:         "Success Send Verification, no email address",
+			fields:       fields{&fakeNotifier{}},
+			args:         args{&entity.User{Ksuid: "ksuid", Username: "user"}},
+			wantMessages: 0,
+			wantErr:      false,
+		},
+		{
+			name:         "Success Send Verification, already verified",
+			fields:       fields{&fakeNotifier{}},
+			args:         args{&entity.User{Ksuid: "ksuid", Username: "user", Email: &email, EmailVerifiedAt: &verifiedAt}},
+			wantMessages: 0,
+			wantErr:      false,
+		},
+		{
+			name:         "Failed Send Verification, notifier error",
+			fields:       fields{&fakeNotifier{err: fmt.Errorf("connection refused")}},
+			args:         args{&entity.User{Ksuid: "ksuid", Username: "user", Email: &email}},
+			wantMessages: 0,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &emailVerification{
+				notifier: tt.fields.notifier,
+			}
+			if err := uc.SendVerification(tt.args.user); (err != nil) != tt.wantErr {
+				t.Errorf("emailVerification.SendVerification() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if len(tt.fields.notifier.messages) != tt.wantMessages {
+				t.Errorf("emailVerification.SendVerification() sent %d messages, want %d", len(tt.fields.notifier.messages), tt.wantMessages)
+				return
+			}
+			if tt.wantMessages > 0 && tt.fields.notifier.messages[0].To != email {
+				t.Errorf("emailVerification.SendVerification() sent to %s, want %s", tt.fields.notifier.messages[0].To, email)
+			}
+		})
+	}
+}
+
+func Test_emailVerification_VerifyEmail(t *testing.T) {
+	userRepo := repoMocks.NewUsersRepo(t)
+
+	email := "user@example.com"
+	otherEmail := "other@example.com"
+	verifiedAt := time.Now()
+
+	validToken, _ := jwt.CreateEmailVerificationToken("ksuid", email)
+	verifiedToken, _ := jwt.CreateEmailVerificationToken("verified", email)
+	changedToken, _ := jwt.CreateEmailVerificationToken("changed", email)
+	refreshToken, _ := jwt.CreateRefreshToken("ksuid", entity.USER, "jti")
+
+	userRepo.On("GetUserByKsuid", "ksuid").
+		Return(&entity.User{Ksuid: "ksuid", Username: "user", Email: &email}, nil).
+		Once()
+
+	userRepo.On("GetUserByKsuid", "verified").
+		Return(&entity.User{Ksuid: "verified", Username: "verified", Email: &email, EmailVerifiedAt: &verifiedAt}, nil).
+		Once()
+
+	userRepo.On("GetUserByKsuid", "changed").
+		Return(&entity.User{Ksuid: "changed", Username: "changed", Email: &otherEmail}, nil).
+		Once()
+
+	userRepo.On("VerifyEmail", "ksuid", email).
+		Return(nil).
+		Once()
+
+	type args struct {
+		token string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr error
+	}{
+		{
+			name:    "Success Verify Email",
+			args:    args{validToken},
+			wantErr: nil,
+		},
+		{
+			name:    "Success Verify Email, already verified",
+			args:    args{verifiedToken},
+			wantErr: nil,
+		},
+		{
+			name:    "Failed Verify Email, email address changed",
+			args:    args{changedToken},
+			wantErr: ErrInvalidVerificationToken,
+		},
+		{
+			name:    "Failed Verify Email, not a verification token",
+			args:    args{refreshToken},
+			wantErr: ErrInvalidVerificationToken,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &emailVerification{
+				userRepo: userRepo,
+			}
+			got, err := uc.VerifyEmail(tt.args.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("emailVerification.VerifyEmail() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && !got.IsEmailVerified() {
+				t.Errorf("emailVerification.VerifyEmail() = %v, want verified", got)
+			}
+		})
+	}
+}
+
+func Test_emailVerification_CheckLogin(t *testing.T) {
+	email := "user@example.com"
+	verifiedAt := time.Now()
+
+	type fields struct {
+		requiredForLogin bool
+	}
+	type args struct {
+		user *entity.User
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name:    "Success Login, verification not required",
+			fields:  fields{false},
+			args:    args{&entity.User{Ksuid: "ksuid", Email: &email}},
+			wantErr: nil,
+		},
+		{
+			name:    "Success Login, verified email",
+			fields:  fields{true},
+			args:    args{&entity.User{Ksuid: "ksuid", Email: &email, EmailVerifiedAt: &verifiedAt}},
+			wantErr: nil,
+		},
+		{
+			name:    "Failed Login, unverified email",
+			fields:  fields{true},
+			args:    args{&entity.User{Ksuid: "ksuid", Email: &email}},
+			wantErr: ErrEmailNotVerified,
+		},
+		{
+			name:    "Success Login, verified email required for the user",
+			fields:  fields{false},
+			args:    args{&entity.User{Ksuid: "ksuid", Email: &email, EmailVerifiedAt: &verifiedAt, EmailVerificationRequired: true}},
+			wantErr: nil,
+		},
+		{
+			name:    "Failed Login, unverified email required for the user",
+			fields:  fields{false},
+			args:    args{&entity.User{Ksuid: "ksuid", Email: &email, EmailVerificationRequired: true}},
+			wantErr: ErrEmailNotVerified,
+		},
+		{
+			name:    "Failed Login, no email",
+			fields:  fields{true},
+			args:    args{&entity.User{Ksuid: "ksuid"}},
+			wantErr: ErrEmailNotVerified,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &emailVerification{
+				requiredForLogin: tt.fields.requiredForLogin,
+			}
+			if err := uc.CheckLogin(tt.args.user); !errors.Is(err, tt.wantErr) {
+				t.Errorf("emailVerification.CheckLogin() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
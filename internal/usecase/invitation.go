@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/adesupraptolaia/user_login/internal/repo"
+	"github.com/adesupraptolaia/user_login/pkg/jwt"
+	"gorm.io/gorm"
+)
+
+type InvitationUC interface {
+	InviteUser(entity.InviteUserRequest) (*entity.Invitation, error)
+	GetInvitations() ([]entity.Invitation, error)
+	RevokeInvitation(ksuid string) (*entity.User, error)
+	AcceptInvitation(entity.AcceptInvitationRequest) (*entity.User, error)
+}
+
+var (
+	ErrInvalidInvitationToken = errors.New("invalid or expired invitation token")
+	ErrInvitationNotFound     = errors.New("invitation not found")
+)
+
+type invitation struct {
+	userRepo repo.UsersRepo
+	userUC   UserUC
+}
+
+func NewInvitation(userRepo repo.UsersRepo, userUC UserUC) InvitationUC {
+	return &invitation{
+		userRepo: userRepo,
+		userUC:   userUC,
+	}
+}
+
+// InviteUser create a user without password, and a signed invitation token to choose one
+func (uc *invitation) InviteUser(req entity.InviteUserRequest) (*entity.Invitation, error) {
+	user, err := uc.userUC.InviteUser(req)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := jwt.CreateInvitationToken(user.Ksuid, *user.InvitedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed when create invitation token")
+	}
+
+	invitation := newInvitation(user)
+	invitation.InviteToken = token
+
+	return &invitation, nil
+}
+
+// GetInvitations list the invited users who have not chosen a password yet, the expired ones included
+func (uc *invitation) GetInvitations() ([]entity.Invitation, error) {
+	users, err := uc.userRepo.GetInvitedUsers()
+	if err != nil {
+		return nil, fmt.Errorf("failed when get invited users")
+	}
+
+	invitations := []entity.Invitation{}
+	for i := range users {
+		invitations = append(invitations, newInvitation(&users[i]))
+	}
+
+	return invitations, nil
+}
+
+// RevokeInvitation delete the invited user, the invitation token is of no use afterwards
+func (uc *invitation) RevokeInvitation(ksuid string) (*entity.User, error) {
+	user, err := uc.userRepo.DeleteInvitedUser(ksuid)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvitationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed when delete invited user with ksuid %s", ksuid)
+	}
+
+	return user, nil
+}
+
+// AcceptInvitation set the password of the invited user, the invitation token can be used once
+func (uc *invitation) AcceptInvitation(req entity.AcceptInvitationRequest) (*entity.User, error) {
+	claims, err := jwt.ValidateInvitationToken(req.Token)
+	if err != nil {
+		return nil, ErrInvalidInvitationToken
+	}
+
+	user, err := uc.userUC.AcceptInvitation(claims.UserKsuid, req.Password)
+	if errors.Is(err, ErrUserNotInvited) {
+		return nil, ErrInvalidInvitationToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func newInvitation(user *entity.User) entity.Invitation {
+	return entity.Invitation{
+		UserKsuid: user.Ksuid,
+		Username:  user.Username,
+		Email:     user.Email,
+		InvitedAt: *user.InvitedAt,
+		ExpiresAt: user.InvitedAt.Add(jwt.INVITATION_TOKEN_TTL),
+	}
+}
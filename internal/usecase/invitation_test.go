@@ -0,0 +1,225 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	repoMocks "github.com/adesupraptolaia/user_login/internal/repo/mocks"
+	"github.com/adesupraptolaia/user_login/pkg/jwt"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func Test_invitation_InviteUser(t *testing.T) {
+	userRepo := repoMocks.NewUsersRepo(t)
+
+	userRepo.On("GetUserByUsername", "user").
+		Return(nil, fmt.Errorf("not found")).
+		Once()
+
+	userRepo.On("GetUserByEmail", "user@example.com").
+		Return(nil, fmt.Errorf("not found")).
+		Once()
+
+	userRepo.On("CreateUser", mock.MatchedBy(func(user entity.User) bool {
+		return user.Username == "user" && *user.Email == "user@example.com" && user.Password == "" && user.IsInvitationPending()
+	})).
+		Return(func(user entity.User) *entity.User {
+			return &user
+		}, nil).
+		Once()
+
+	userRepo.On("GetUserByUsername", "admin").
+		Return(&entity.User{Ksuid: "admin", Username: "admin"}, nil).
+		Once()
+
+	type args struct {
+		req entity.InviteUserRequest
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr error
+	}{
+		{
+			name: "Success Invite User",
+			args: args{entity.InviteUserRequest{Username: "user", Email: " User@Example.com"}},
+		},
+		{
+			name:    "Failed Invite User, username taken",
+			args:    args{entity.InviteUserRequest{Username: "admin"}},
+			wantErr: ErrUserAlreadyExists,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &invitation{
+				userRepo: userRepo,
+				userUC:   &user{repo: userRepo, hasher: testHasher},
+			}
+			got, err := uc.InviteUser(tt.args.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("invitation.InviteUser() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+
+			claims, err := jwt.ValidateInvitationToken(got.InviteToken)
+			if err != nil || claims.UserKsuid != got.UserKsuid {
+				t.Errorf("invitation.InviteUser() token of %v, err %v, want of %s", claims, err, got.UserKsuid)
+			}
+			if !got.ExpiresAt.Equal(got.InvitedAt.Add(jwt.INVITATION_TOKEN_TTL)) {
+				t.Errorf("invitation.InviteUser() expires at %v, want %v", got.ExpiresAt, got.InvitedAt.Add(jwt.INVITATION_TOKEN_TTL))
+			}
+		})
+	}
+}
+
+func Test_invitation_RevokeInvitation(t *testing.T) {
+	userRepo := repoMocks.NewUsersRepo(t)
+
+	userRepo.On("DeleteInvitedUser", "invited").
+		Return(&entity.User{}, nil).
+		Once()
+
+	userRepo.On("DeleteInvitedUser", "accepted").
+		Return(nil, gorm.ErrRecordNotFound).
+		Once()
+
+	tests := []struct {
+		name    string
+		ksuid   string
+		wantErr error
+	}{
+		{
+			name:  "Success Revoke Invitation",
+			ksuid: "invited",
+		},
+		{
+			name:    "Failed Revoke Invitation, already accepted",
+			ksuid:   "accepted",
+			wantErr: ErrInvitationNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &invitation{userRepo: userRepo}
+			_, err := uc.RevokeInvitation(tt.ksuid)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("invitation.RevokeInvitation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_invitation_AcceptInvitation(t *testing.T) {
+	userRepo := repoMocks.NewUsersRepo(t)
+	breachedRepo := repoMocks.NewBreachedPasswordsRepo(t)
+
+	invitedAt := time.Now().Truncate(time.Second)
+	invited := &entity.User{Ksuid: "invited", Username: "invited", Role: entity.USER, InvitedAt: &invitedAt}
+	accepted := &entity.User{Ksuid: "accepted", Username: "accepted", Password: hashPassword("password"), Role: entity.USER}
+
+	for _, data := range []*entity.User{invited, accepted} {
+		data := data
+		userRepo.On("GetUserByKsuid", data.Ksuid).
+			Return(func(string) *entity.User {
+				user := *data
+				return &user
+			}, nil)
+	}
+
+	userRepo.On("GetUserByKsuid", "revoked").
+		Return(nil, gorm.ErrRecordNotFound).
+		Once()
+
+	breachedRepo.On("GetHashSuffixes", mock.AnythingOfType("string")).
+		Return(nil, nil)
+
+	userRepo.On("AcceptInvitation", "invited", mock.MatchedBy(func(hash string) bool {
+		return isPasswordMatch(hash, "newPassword")
+	})).
+		Return(nil).
+		Once()
+
+	// accepted meanwhile with the same token
+	userRepo.On("AcceptInvitation", "invited", mock.MatchedBy(func(hash string) bool {
+		return isPasswordMatch(hash, "otherPassword")
+	})).
+		Return(gorm.ErrRecordNotFound).
+		Once()
+
+	newToken := func(userKsuid string) string {
+		token, _ := jwt.CreateInvitationToken(userKsuid, time.Now())
+		return token
+	}
+	expiredToken, _ := jwt.CreateInvitationToken("invited", time.Now().Add(-jwt.INVITATION_TOKEN_TTL-time.Minute))
+	emailToken, _ := jwt.CreateEmailVerificationToken("invited", "user@example.com")
+
+	tests := []struct {
+		name    string
+		req     entity.AcceptInvitationRequest
+		wantErr error
+	}{
+		{
+			name: "Success Accept Invitation",
+			req:  entity.AcceptInvitationRequest{Token: newToken("invited"), Password: "newPassword"},
+		},
+		{
+			name:    "Failed Accept Invitation, accepted meanwhile",
+			req:     entity.AcceptInvitationRequest{Token: newToken("invited"), Password: "otherPassword"},
+			wantErr: ErrInvalidInvitationToken,
+		},
+		{
+			name:    "Failed Accept Invitation, weak password",
+			req:     entity.AcceptInvitationRequest{Token: newToken("invited"), Password: "short"},
+			wantErr: ErrWeakPassword,
+		},
+		{
+			name:    "Failed Accept Invitation, already accepted",
+			req:     entity.AcceptInvitationRequest{Token: newToken("accepted"), Password: "newPassword"},
+			wantErr: ErrInvalidInvitationToken,
+		},
+		{
+			name:    "Failed Accept Invitation, revoked",
+			req:     entity.AcceptInvitationRequest{Token: newToken("revoked"), Password: "newPassword"},
+			wantErr: ErrInvalidInvitationToken,
+		},
+		{
+			name:    "Failed Accept Invitation, expired token",
+			req:     entity.AcceptInvitationRequest{Token: expiredToken, Password: "newPassword"},
+			wantErr: ErrInvalidInvitationToken,
+		},
+		{
+			name:    "Failed Accept Invitation, email verification token",
+			req:     entity.AcceptInvitationRequest{Token: emailToken, Password: "newPassword"},
+			wantErr: ErrInvalidInvitationToken,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &invitation{
+				userRepo: userRepo,
+				userUC: &user{
+					repo:         userRepo,
+					breachedRepo: breachedRepo,
+					policy:       PasswordPolicy{MinLength: 8},
+					hasher:       testHasher,
+				},
+			}
+			got, err := uc.AcceptInvitation(tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("invitation.AcceptInvitation() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.IsInvitationPending() {
+				t.Errorf("invitation.AcceptInvitation() = %v, want the invitation accepted", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/repo"
+	"gorm.io/gorm"
+)
+
+type LoginAttemptUC interface {
+	CheckLogin(username, ip string) error
+	RecordFailedLogin(username, ip string) error
+	RecordSuccessLogin(username string) error
+	Unlock(username string) error
+	PurgeStaleAttempts() (int64, error)
+}
+
+var (
+	ErrAccountLocked   = errors.New("account is locked")
+	ErrTooManyAttempts = errors.New("too many failed login attempts")
+)
+
+// LockedError is returned while a username or an ip address is locked
+type LockedError struct {
+	Err   error
+	Until time.Time
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s until %s", e.Err.Error(), e.Until.Format(time.RFC3339))
+}
+
+func (e *LockedError) Unwrap() error {
+	return e.Err
+}
+
+// LoginThrottlePolicy lock a username or an ip address after too many failed logins
+// within Window. The lockout starts at LockoutDuration and doubles on every
+// consecutive lockout, up to MaxLockoutDuration.
+type LoginThrottlePolicy struct {
+	MaxFailedPerUser   int
+	MaxFailedPerIP     int
+	Window             time.Duration
+	LockoutDuration    time.Duration
+	MaxLockoutDuration time.Duration
+}
+
+type loginAttempt struct {
+	repo   repo.LoginAttemptsRepo
+	policy LoginThrottlePolicy
+}
+
+func NewLoginAttempt(repo repo.LoginAttemptsRepo, policy LoginThrottlePolicy) LoginAttemptUC {
+	return &loginAttempt{
+		repo:   repo,
+		policy: policy,
+	}
+}
+
+// CheckLogin return a LockedError when the username or the ip address is locked
+func (uc *loginAttempt) CheckLogin(username, ip string) error {
+	if err := uc.check(usernameKey(username), ErrAccountLocked); err != nil {
+		return err
+	}
+
+	return uc.check(ipKey(ip), ErrTooManyAttempts)
+}
+
+// RecordFailedLogin count the failure for both username and ip address,
+// and return a LockedError when the failure locks one of them
+func (uc *loginAttempt) RecordFailedLogin(username, ip string) error {
+	if err := uc.recordFailure(usernameKey(username), uc.policy.MaxFailedPerUser, ErrAccountLocked); err != nil {
+		return err
+	}
+
+	return uc.recordFailure(ipKey(ip), uc.policy.MaxFailedPerIP, ErrTooManyAttempts)
+}
+
+// RecordSuccessLogin reset the counter of the username, the ip address keeps
+// its counter so one valid account can't be used to unlock guessing on others
+func (uc *loginAttempt) RecordSuccessLogin(username string) error {
+	if err := uc.repo.DeleteLoginAttempt(usernameKey(username)); err != nil {
+		return fmt.Errorf("failed when reset login attempts of username %s", username)
+	}
+
+	return nil
+}
+
+func (uc *loginAttempt) Unlock(username string) error {
+	if err := uc.repo.DeleteLoginAttempt(usernameKey(username)); err != nil {
+		return fmt.Errorf("failed when unlock username %s", username)
+	}
+
+	return nil
+}
+
+// PurgeStaleAttempts delete the counters without failure for longer than the window and the
+// longest lockout, a later lockout is not consecutive to theirs and starts again at LockoutDuration
+func (uc *loginAttempt) PurgeStaleAttempts() (int64, error) {
+	purged, err := uc.repo.DeleteStaleLoginAttempts(time.Now().Add(-uc.policy.Window - uc.policy.MaxLockoutDuration))
+	if err != nil {
+		return 0, fmt.Errorf("failed when purge stale login attempts")
+	}
+
+	return purged, nil
+}
+
+func (uc *loginAttempt) check(key string, lockedErr error) error {
+	attempt, err := uc.repo.GetLoginAttempt(key)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed when get login attempts")
+	}
+
+	if attempt.LockedUntil != nil && time.Now().Before(*attempt.LockedUntil) {
+		return &LockedError{Err: lockedErr, Until: *attempt.LockedUntil}
+	}
+
+	return nil
+}
+
+func (uc *loginAttempt) recordFailure(key string, maxFailed int, lockedErr error) error {
+	attempt, err := uc.repo.AddFailedAttempt(key, time.Now().Add(-uc.policy.Window))
+	if err != nil {
+		return fmt.Errorf("failed when record failed login")
+	}
+
+	if maxFailed <= 0 || attempt.FailedCount < maxFailed {
+		return nil
+	}
+
+	lockedUntil := time.Now().Add(uc.lockoutDuration(attempt.LockCount))
+	if err := uc.repo.LockLoginAttempt(key, lockedUntil); err != nil {
+		return fmt.Errorf("failed when lock login")
+	}
+
+	return &LockedError{Err: lockedErr, Until: lockedUntil}
+}
+
+func (uc *loginAttempt) lockoutDuration(lockCount int) time.Duration {
+	duration := uc.policy.LockoutDuration
+	for i := 0; i < lockCount && duration < uc.policy.MaxLockoutDuration; i++ {
+		duration *= 2
+	}
+
+	if duration > uc.policy.MaxLockoutDuration {
+		return uc.policy.MaxLockoutDuration
+	}
+
+	return duration
+}
+
+func usernameKey(username string) string {
+	return "username:" + username
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
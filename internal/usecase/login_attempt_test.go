@@ -0,0 +1,207 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	repoMocks "github.com/adesupraptolaia/user_login/internal/repo/mocks"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+var testLoginThrottlePolicy = LoginThrottlePolicy{
+	MaxFailedPerUser:   5,
+	MaxFailedPerIP:     20,
+	Window:             15 * time.Minute,
+	LockoutDuration:    time.Minute,
+	MaxLockoutDuration: time.Hour,
+}
+
+func Test_loginAttempt_CheckLogin(t *testing.T) {
+	repo := repoMocks.NewLoginAttemptsRepo(t)
+
+	lockedUntil := time.Now().Add(time.Minute)
+	expiredLock := time.Now().Add(-time.Minute)
+
+	repo.On("GetLoginAttempt", "username:locked").
+		Return(&entity.LoginAttempt{Key: "username:locked", LockedUntil: &lockedUntil}, nil).
+		Once()
+
+	repo.On("GetLoginAttempt", "username:expired").
+		Return(&entity.LoginAttempt{Key: "username:expired", LockedUntil: &expiredLock}, nil).
+		Once()
+
+	repo.On("GetLoginAttempt", "username:user").
+		Return(nil, gorm.ErrRecordNotFound).
+		Twice()
+
+	repo.On("GetLoginAttempt", "ip:1.1.1.1").
+		Return(nil, gorm.ErrRecordNotFound).
+		Twice()
+
+	repo.On("GetLoginAttempt", "ip:2.2.2.2").
+		Return(&entity.LoginAttempt{Key: "ip:2.2.2.2", LockedUntil: &lockedUntil}, nil).
+		Once()
+
+	type fields struct {
+		repo *repoMocks.LoginAttemptsRepo
+	}
+	type args struct {
+		username string
+		ip       string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name:    "Success Check Login",
+			fields:  fields{repo: repo},
+			args:    args{"user", "1.1.1.1"},
+			wantErr: nil,
+		},
+		{
+			name:    "Success Check Login After Lockout Expired",
+			fields:  fields{repo: repo},
+			args:    args{"expired", "1.1.1.1"},
+			wantErr: nil,
+		},
+		{
+			name:    "Failed Account Locked",
+			fields:  fields{repo: repo},
+			args:    args{"locked", "1.1.1.1"},
+			wantErr: ErrAccountLocked,
+		},
+		{
+			name:    "Failed IP Locked",
+			fields:  fields{repo: repo},
+			args:    args{"user", "2.2.2.2"},
+			wantErr: ErrTooManyAttempts,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &loginAttempt{
+				repo:   tt.fields.repo,
+				policy: testLoginThrottlePolicy,
+			}
+			if err := uc.CheckLogin(tt.args.username, tt.args.ip); !errors.Is(err, tt.wantErr) {
+				t.Errorf("loginAttempt.CheckLogin() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_loginAttempt_RecordFailedLogin(t *testing.T) {
+	repo := repoMocks.NewLoginAttemptsRepo(t)
+
+	repo.On("AddFailedAttempt", "username:user", mock.AnythingOfType("time.Time")).
+		Return(&entity.LoginAttempt{Key: "username:user", FailedCount: 1}, nil).
+		Once()
+
+	repo.On("AddFailedAttempt", "ip:1.1.1.1", mock.AnythingOfType("time.Time")).
+		Return(&entity.LoginAttempt{Key: "ip:1.1.1.1", FailedCount: 1}, nil).
+		Once()
+
+	repo.On("AddFailedAttempt", "username:victim", mock.AnythingOfType("time.Time")).
+		Return(&entity.LoginAttempt{Key: "username:victim", FailedCount: 5, LockCount: 2}, nil).
+		Once()
+
+	// third lockout in a row lasts 4 minutes
+	repo.On("LockLoginAttempt", "username:victim", mock.MatchedBy(func(lockedUntil time.Time) bool {
+		return time.Until(lockedUntil) > 3*time.Minute && time.Until(lockedUntil) <= 4*time.Minute
+	})).
+		Return(nil).
+		Once()
+
+	type fields struct {
+		repo *repoMocks.LoginAttemptsRepo
+	}
+	type args struct {
+		username string
+		ip       string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name:    "Success Record Failed Login",
+			fields:  fields{repo: repo},
+			args:    args{"user", "1.1.1.1"},
+			wantErr: nil,
+		},
+		{
+			name:    "Success Lock Account",
+			fields:  fields{repo: repo},
+			args:    args{"victim", "1.1.1.1"},
+			wantErr: ErrAccountLocked,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &loginAttempt{
+				repo:   tt.fields.repo,
+				policy: testLoginThrottlePolicy,
+			}
+			if err := uc.RecordFailedLogin(tt.args.username, tt.args.ip); !errors.Is(err, tt.wantErr) {
+				t.Errorf("loginAttempt.RecordFailedLogin() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_loginAttempt_PurgeStaleAttempts(t *testing.T) {
+	// stale after the window and the longest lockout
+	staleBefore := mock.MatchedBy(func(lastFailedBefore time.Time) bool {
+		return time.Until(lastFailedBefore).Round(time.Minute) == -75*time.Minute
+	})
+
+	tests := []struct {
+		name    string
+		purged  int64
+		repoErr error
+		want    int64
+		wantErr bool
+	}{
+		{
+			name:    "Success Purge Stale Attempts",
+			purged:  3,
+			want:    3,
+			wantErr: false,
+		},
+		{
+			name:    "Failed Purge Stale Attempts",
+			repoErr: gorm.ErrInvalidDB,
+			want:    0,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repoMocks.NewLoginAttemptsRepo(t)
+			repo.On("DeleteStaleLoginAttempts", staleBefore).
+				Return(tt.purged, tt.repoErr).
+				Once()
+
+			uc := &loginAttempt{
+				repo:   repo,
+				policy: testLoginThrottlePolicy,
+			}
+			got, err := uc.PurgeStaleAttempts()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loginAttempt.PurgeStaleAttempts() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("loginAttempt.PurgeStaleAttempts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"log"
+	"os"
+	"testing"
+
+	"github.com/adesupraptolaia/user_login/pkg/jwt"
+)
+
+func TestMain(m *testing.M) {
+	cleanup, err := jwt.LoadTestSigningKeys()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	code := m.Run()
+	cleanup()
+	os.Exit(code)
+}
@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/adesupraptolaia/user_login/internal/repo"
+	"github.com/adesupraptolaia/user_login/pkg/totp"
+	"gorm.io/gorm"
+)
+
+type MfaUC interface {
+	Enroll(*entity.User) (*entity.MfaEnrollment, error)
+	Confirm(string, string) ([]string, error)
+	IsEnrolled(string) (bool, error)
+	Verify(string, string) error
+}
+
+const RECOVERY_CODE_COUNT = 10
+
+var (
+	ErrMfaAlreadyEnrolled = errors.New("mfa is already enrolled")
+	ErrMfaNotEnrolled     = errors.New("mfa is not enrolled")
+	ErrInvalidMfaCode     = errors.New("invalid mfa code")
+)
+
+type mfa struct {
+	repo             repo.MfaSecretsRepo
+	recoveryCodeRepo repo.RecoveryCodesRepo
+	issuer           string
+}
+
+func NewMfa(mfaSecretRepo repo.MfaSecretsRepo, recoveryCodeRepo repo.RecoveryCodesRepo, issuer string) MfaUC {
+	return &mfa{
+		repo:             mfaSecretRepo,
+		recoveryCodeRepo: recoveryCodeRepo,
+		issuer:           issuer,
+	}
+}
+
+// Enroll create a new TOTP secret for the user, the secret is not required
+// on login until it is confirmed. Enrolling again replace a pending secret.
+func (uc *mfa) Enroll(user *entity.User) (*entity.MfaEnrollment, error) {
+	enrolled, err := uc.IsEnrolled(user.Ksuid)
+	if err != nil {
+		return nil, err
+	}
+	if enrolled {
+		return nil, ErrMfaAlreadyEnrolled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed when generate mfa secret")
+	}
+
+	_, err = uc.repo.SaveMfaSecret(entity.MfaSecret{
+		UserKsuid: user.Ksuid,
+		Secret:    secret,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed when save mfa secret of user with ksuid %s", user.Ksuid)
+	}
+
+	return &entity.MfaEnrollment{
+		Secret: secret,
+		URI:    totp.URI(uc.issuer, user.Username, secret),
+	}, nil
+}
+
+// Confirm the pending secret with a first code, and return the recovery codes.
+// The recovery codes are only stored hashed, they can't be shown again.
+func (uc *mfa) Confirm(userKsuid, code string) ([]string, error) {
+	mfaSecret, err := uc.repo.GetMfaSecret(userKsuid)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMfaNotEnrolled
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed when get mfa secret of user with ksuid %s", userKsuid)
+	}
+
+	if mfaSecret.ConfirmedAt != nil {
+		return nil, ErrMfaAlreadyEnrolled
+	}
+
+	step, ok := totp.Validate(mfaSecret.Secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidMfaCode
+	}
+
+	codes := make([]string, RECOVERY_CODE_COUNT)
+	recoveryCodes := make([]entity.RecoveryCode, RECOVERY_CODE_COUNT)
+	for i := range codes {
+		codes[i], err = generateRecoveryCode()
+		if err != nil {
+			return nil, fmt.Errorf("failed when generate recovery code")
+		}
+
+		recoveryCodes[i] = entity.RecoveryCode{
+			CodeHash:  hashRecoveryCode(codes[i]),
+			UserKsuid: userKsuid,
+		}
+	}
+
+	if err := uc.recoveryCodeRepo.ReplaceRecoveryCodes(userKsuid, recoveryCodes); err != nil {
+		return nil, fmt.Errorf("failed when save recovery codes of user with ksuid %s", userKsuid)
+	}
+
+	if err := uc.repo.ConfirmMfaSecret(userKsuid, step); err != nil {
+		return nil, fmt.Errorf("failed when confirm mfa secret of user with ksuid %s", userKsuid)
+	}
+
+	return codes, nil
+}
+
+func (uc *mfa) IsEnrolled(userKsuid string) (bool, error) {
+	mfaSecret, err := uc.repo.GetMfaSecret(userKsuid)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed when get mfa secret of user with ksuid %s", userKsuid)
+	}
+
+	return mfaSecret.ConfirmedAt != nil, nil
+}
+
+// Verify accept a TOTP code, each code only once, or an unused recovery code
+func (uc *mfa) Verify(userKsuid, code string) error {
+	mfaSecret, err := uc.repo.GetMfaSecret(userKsuid)
+	if err != nil || mfaSecret.ConfirmedAt == nil {
+		return ErrMfaNotEnrolled
+	}
+
+	if step, ok := totp.Validate(mfaSecret.Secret, code, time.Now()); ok {
+		if err := uc.repo.UseMfaStep(userKsuid, step); err != nil {
+			return ErrInvalidMfaCode
+		}
+		return nil
+	}
+
+	if err := uc.recoveryCodeRepo.UseRecoveryCode(userKsuid, hashRecoveryCode(code)); err != nil {
+		return ErrInvalidMfaCode
+	}
+
+	return nil
+}
+
+// generateRecoveryCode create a code like "ABCDE-FGHIJ"
+func generateRecoveryCode() (string, error) {
+	random := make([]byte, 10)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	code := base32.StdEncoding.EncodeToString(random)[:10]
+
+	return code[:5] + "-" + code[5:], nil
+}
+
+// recovery codes have enough entropy to be hashed without salt,
+// so a code can be looked up by its hash
+func hashRecoveryCode(code string) string {
+	code = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(code))
+
+	return hex.EncodeToString(sum[:])
+}
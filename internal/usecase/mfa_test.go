@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	repoMocks "github.com/adesupraptolaia/user_login/internal/repo/mocks"
+	"github.com/adesupraptolaia/user_login/pkg/totp"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func Test_mfa_Confirm(t *testing.T) {
+	repo := repoMocks.NewMfaSecretsRepo(t)
+	recoveryCodeRepo := repoMocks.NewRecoveryCodesRepo(t)
+
+	secret, _ := totp.GenerateSecret()
+	code, _ := totp.Code(secret, time.Now())
+	confirmedAt := time.Now()
+
+	repo.On("GetMfaSecret", "pending").
+		Return(&entity.MfaSecret{UserKsuid: "pending", Secret: secret}, nil).
+		Twice()
+
+	repo.On("GetMfaSecret", "confirmed").
+		Return(&entity.MfaSecret{UserKsuid: "confirmed", Secret: secret, ConfirmedAt: &confirmedAt}, nil).
+		Once()
+
+	repo.On("GetMfaSecret", "unknown").
+		Return(nil, gorm.ErrRecordNotFound).
+		Once()
+
+	recoveryCodeRepo.On("ReplaceRecoveryCodes", "pending", mock.MatchedBy(func(codes []entity.RecoveryCode) bool {
+		return len(codes) == RECOVERY_CODE_COUNT && codes[0].CodeHash != ""
+	})).
+		Return(nil).
+		Once()
+
+	repo.On("ConfirmMfaSecret", "pending", totp.Step(time.Now())).
+		Return(nil).
+		Once()
+
+	type fields struct {
+		repo             *repoMocks.MfaSecretsRepo
+		recoveryCodeRepo *repoMocks.RecoveryCodesRepo
+	}
+	type args struct {
+		userKsuid string
+		code      string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name:    "Success Confirm MFA",
+			fields:  fields{repo: repo, recoveryCodeRepo: recoveryCodeRepo},
+			args:    args{"pending", code},
+			wantErr: nil,
+		},
+		{
+			name:    "Failed Wrong Code",
+			fields:  fields{repo: repo, recoveryCodeRepo: recoveryCodeRepo},
+			args:    args{"pending", "000000x"},
+			wantErr: ErrInvalidMfaCode,
+		},
+		{
+			name:    "Failed Already Confirmed",
+			fields:  fields{repo: repo, recoveryCodeRepo: recoveryCodeRepo},
+			args:    args{"confirmed", code},
+			wantErr: ErrMfaAlreadyEnrolled,
+		},
+		{
+			name:    "Failed Not Enrolled",
+			fields:  fields{repo: repo, recoveryCodeRepo: recoveryCodeRepo},
+			args:    args{"unknown", code},
+			wantErr: ErrMfaNotEnrolled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &mfa{
+				repo:             tt.fields.repo,
+				recoveryCodeRepo: tt.fields.recoveryCodeRepo,
+			}
+			got, err := uc.Confirm(tt.args.userKsuid, tt.args.code)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("mfa.Confirm() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && len(got) != RECOVERY_CODE_COUNT {
+				t.Errorf("mfa.Confirm() = %v, want %d recovery codes", got, RECOVERY_CODE_COUNT)
+			}
+		})
+	}
+}
+
+func Test_mfa_Verify(t *testing.T) {
+	repo := repoMocks.NewMfaSecretsRepo(t)
+	recoveryCodeRepo := repoMocks.NewRecoveryCodesRepo(t)
+
+	secret, _ := totp.GenerateSecret()
+	code, _ := totp.Code(secret, time.Now())
+	confirmedAt := time.Now()
+
+	repo.On("GetMfaSecret", "ksuid").
+		Return(&entity.MfaSecret{UserKsuid: "ksuid", Secret: secret, ConfirmedAt: &confirmedAt}, nil).
+		Times(4)
+
+	repo.On("UseMfaStep", "ksuid", totp.Step(time.Now())).
+		Return(nil).
+		Once()
+
+	// the same code again
+	repo.On("UseMfaStep", "ksuid", totp.Step(time.Now())).
+		Return(gorm.ErrRecordNotFound).
+		Once()
+
+	recoveryCodeRepo.On("UseRecoveryCode", "ksuid", hashRecoveryCode("ABCDE-FGHIJ")).
+		Return(nil).
+		Once()
+
+	recoveryCodeRepo.On("UseRecoveryCode", "ksuid", hashRecoveryCode("wrong")).
+		Return(gorm.ErrRecordNotFound).
+		Once()
+
+	type fields struct {
+		repo             *repoMocks.MfaSecretsRepo
+		recoveryCodeRepo *repoMocks.RecoveryCodesRepo
+	}
+	type args struct {
+		userKsuid string
+		code      string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name:    "Success Verify TOTP Code",
+			fields:  fields{repo: repo, recoveryCodeRepo: recoveryCodeRepo},
+			args:    args{"ksuid", code},
+			wantErr: nil,
+		},
+		{
+			name:    "Failed Reuse TOTP Code",
+			fields:  fields{repo: repo, recoveryCodeRepo: recoveryCodeRepo},
+			args:    args{"ksuid", code},
+			wantErr: ErrInvalidMfaCode,
+		},
+		{
+			name:    "Success Verify Recovery Code",
+			fields:  fields{repo: repo, recoveryCodeRepo: recoveryCodeRepo},
+			args:    args{"ksuid", "abcde-fghij"},
+			wantErr: nil,
+		},
+		{
+			name:    "Failed Wrong Code",
+			fields:  fields{repo: repo, recoveryCodeRepo: recoveryCodeRepo},
+			args:    args{"ksuid", "wrong"},
+			wantErr: ErrInvalidMfaCode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &mfa{
+				repo:             tt.fields.repo,
+				recoveryCodeRepo: tt.fields.recoveryCodeRepo,
+			}
+			if err := uc.Verify(tt.args.userKsuid, tt.args.code); !errors.Is(err, tt.wantErr) {
+				t.Errorf("mfa.Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
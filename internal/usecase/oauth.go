@@ -0,0 +1,327 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/adesupraptolaia/user_login/internal/repo"
+	"github.com/adesupraptolaia/user_login/pkg/jwt"
+	"github.com/segmentio/ksuid"
+	"gorm.io/gorm"
+)
+
+type OAuthUC interface {
+	GetClients() ([]entity.OAuthClient, error)
+	CreateClient(entity.OAuthClientRequest) (*entity.OAuthClientCredentials, error)
+	DeleteClient(string) error
+	AuthenticateClient(string, string) (*entity.OAuthClient, error)
+	ValidateAuthorizeRequest(*entity.AuthorizeRequest) (*entity.OAuthClient, error)
+	CreateAuthorizationCode(entity.AuthorizeRequest, string) (string, error)
+	ExchangeAuthorizationCode(*entity.OAuthClient, string, string, string) (*entity.AuthorizationCode, error)
+	CreateClientToken(*entity.OAuthClient, string) (*entity.OAuthToken, error)
+}
+
+const AUTHORIZATION_CODE_TTL = 1 * time.Minute
+
+// OAuthError is an error returned to the client as the error parameter of RFC 6749
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	return e.Description
+}
+
+var (
+	ErrOAuthClientNotFound   = errors.New("oauth client not found")
+	ErrInvalidClientMetadata = errors.New("invalid client metadata")
+	// the user can't be sent back to an unknown redirect_uri, it is shown the error instead
+	ErrInvalidRedirectURI = errors.New("redirect_uri is not registered for the client")
+
+	ErrUnsupportedResponseType = &OAuthError{"unsupported_response_type", "only response_type code is supported"}
+	ErrUnauthorizedClient      = &OAuthError{"unauthorized_client", "client is not allowed to use this grant type"}
+	ErrInvalidScope            = &OAuthError{"invalid_scope", "scope is not registered for the client"}
+	ErrPKCERequired            = &OAuthError{"invalid_request", "code_challenge with code_challenge_method S256 is required"}
+	ErrInvalidGrant            = &OAuthError{"invalid_grant", "authorization code is invalid, expired or used"}
+)
+
+type oauth struct {
+	clientRepo repo.OAuthClientsRepo
+	codeRepo   repo.AuthorizationCodesRepo
+}
+
+func NewOAuth(clientRepo repo.OAuthClientsRepo, codeRepo repo.AuthorizationCodesRepo) OAuthUC {
+	return &oauth{
+		clientRepo: clientRepo,
+		codeRepo:   codeRepo,
+	}
+}
+
+func (uc *oauth) GetClients() ([]entity.OAuthClient, error) {
+	clients, err := uc.clientRepo.GetOAuthClients()
+	if err != nil {
+		return nil, fmt.Errorf("failed when get oauth clients")
+	}
+
+	return clients, nil
+}
+
+// CreateClient register a client, the secret of a confidential client
+// is only returned here, it is stored hashed
+func (uc *oauth) CreateClient(req entity.OAuthClientRequest) (*entity.OAuthClientCredentials, error) {
+	if err := checkClientMetadata(req); err != nil {
+		return nil, err
+	}
+
+	client := entity.OAuthClient{
+		ClientID:     ksuid.New().String(),
+		Name:         req.Name,
+		RedirectURIs: req.RedirectURIs,
+		GrantTypes:   req.GrantTypes,
+		Scopes:       req.Scopes,
+	}
+
+	if client.RedirectURIs == nil {
+		client.RedirectURIs = []string{}
+	}
+	if client.Scopes == nil {
+		client.Scopes = []string{}
+	}
+
+	secret := ""
+	if !req.Public {
+		var err error
+		secret, err = generateSecret()
+		if err != nil {
+			return nil, fmt.Errorf("failed when generate client secret")
+		}
+		client.ClientSecretHash = hashSecret(secret)
+	}
+
+	created, err := uc.clientRepo.CreateOAuthClient(client)
+	if err != nil {
+		return nil, fmt.Errorf("failed when create oauth client %s", req.Name)
+	}
+
+	return &entity.OAuthClientCredentials{
+		OAuthClient:  created,
+		ClientSecret: secret,
+	}, nil
+}
+
+func (uc *oauth) DeleteClient(clientID string) error {
+	err := uc.clientRepo.DeleteOAuthClient(clientID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrOAuthClientNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed when delete oauth client %s", clientID)
+	}
+
+	return nil
+}
+
+// AuthenticateClient check the secret of a confidential client,
+// a public client is only identified by its id
+func (uc *oauth) AuthenticateClient(clientID, clientSecret string) (*entity.OAuthClient, error) {
+	client, err := uc.clientRepo.GetOAuthClient(clientID)
+	if err != nil {
+		return nil, ErrInvalidClient
+	}
+
+	if client.IsPublic() {
+		if clientSecret != "" {
+			return nil, ErrInvalidClient
+		}
+		return client, nil
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashSecret(clientSecret)), []byte(client.ClientSecretHash)) != 1 {
+		return nil, ErrInvalidClient
+	}
+
+	return client, nil
+}
+
+// ValidateAuthorizeRequest check the request of /oauth/authorize, redirect_uri
+// defaults to the only one registered. Errors other than OAuthError must not redirect.
+func (uc *oauth) ValidateAuthorizeRequest(req *entity.AuthorizeRequest) (*entity.OAuthClient, error) {
+	client, err := uc.clientRepo.GetOAuthClient(req.ClientID)
+	if err != nil {
+		return nil, ErrOAuthClientNotFound
+	}
+
+	if req.RedirectURI == "" && len(client.RedirectURIs) == 1 {
+		req.RedirectURI = client.RedirectURIs[0]
+	}
+
+	if !client.HasRedirectURI(req.RedirectURI) {
+		return nil, ErrInvalidRedirectURI
+	}
+
+	if req.ResponseType != "code" {
+		return nil, ErrUnsupportedResponseType
+	}
+
+	if !client.HasGrantType(entity.GRANT_AUTHORIZATION_CODE) {
+		return nil, ErrUnauthorizedClient
+	}
+
+	// PKCE is required from every client, plain method gives no protection
+	if req.CodeChallenge == "" || req.CodeChallengeMethod != "S256" {
+		return nil, ErrPKCERequired
+	}
+
+	if !client.HasScopes(strings.Fields(req.Scope)) {
+		return nil, ErrInvalidScope
+	}
+
+	return client, nil
+}
+
+// CreateAuthorizationCode issue a code for the user who approved the validated request
+func (uc *oauth) CreateAuthorizationCode(req entity.AuthorizeRequest, userKsuid string) (string, error) {
+	code, err := generateSecret()
+	if err != nil {
+		return "", fmt.Errorf("failed when generate authorization code")
+	}
+
+	_, err = uc.codeRepo.CreateAuthorizationCode(entity.AuthorizationCode{
+		CodeHash:      hashSecret(code),
+		ClientID:      req.ClientID,
+		UserKsuid:     userKsuid,
+		RedirectURI:   req.RedirectURI,
+		CodeChallenge: req.CodeChallenge,
+		Scope:         req.Scope,
+		Nonce:         req.Nonce,
+		ExpiresAt:     time.Now().Add(AUTHORIZATION_CODE_TTL),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed when save authorization code")
+	}
+
+	return code, nil
+}
+
+// ExchangeAuthorizationCode redeem the code once, it has to be presented by the client
+// it was issued to, with the same redirect_uri and the verifier of the PKCE challenge
+func (uc *oauth) ExchangeAuthorizationCode(client *entity.OAuthClient, code, redirectURI, codeVerifier string) (*entity.AuthorizationCode, error) {
+	if !client.HasGrantType(entity.GRANT_AUTHORIZATION_CODE) {
+		return nil, ErrUnauthorizedClient
+	}
+
+	codeHash := hashSecret(code)
+
+	storedCode, err := uc.codeRepo.GetAuthorizationCode(codeHash)
+	if err != nil {
+		return nil, ErrInvalidGrant
+	}
+
+	if storedCode.ClientID != client.ClientID || storedCode.RedirectURI != redirectURI {
+		return nil, ErrInvalidGrant
+	}
+
+	if !verifyCodeChallenge(codeVerifier, storedCode.CodeChallenge) {
+		return nil, ErrInvalidGrant
+	}
+
+	if err := uc.codeRepo.UseAuthorizationCode(codeHash); err != nil {
+		return nil, ErrInvalidGrant
+	}
+
+	return storedCode, nil
+}
+
+// CreateClientToken issue an access token for user app to a confidential client on its
+// own behalf, the permissions of the token are the requested scope, or every scope of the client
+func (uc *oauth) CreateClientToken(client *entity.OAuthClient, scope string) (*entity.OAuthToken, error) {
+	if client.IsPublic() || !client.HasGrantType(entity.GRANT_CLIENT_CREDENTIALS) {
+		return nil, ErrUnauthorizedClient
+	}
+
+	scopes := strings.Fields(scope)
+	if len(scopes) == 0 {
+		scopes = client.Scopes
+	}
+
+	if !client.HasScopes(scopes) {
+		return nil, ErrInvalidScope
+	}
+
+	accessToken, err := jwt.CreateClientToken(client.ClientID, scopes, []string{jwt.USER_APP_AUDIENCE})
+	if err != nil {
+		return nil, fmt.Errorf("failed when create client token, err: %s", err.Error())
+	}
+
+	return &entity.OAuthToken{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(jwt.SERVICE_TOKEN_TTL.Seconds()),
+		Scope:       strings.Join(scopes, " "),
+	}, nil
+}
+
+func checkClientMetadata(req entity.OAuthClientRequest) error {
+	for _, grantType := range req.GrantTypes {
+		switch grantType {
+		case entity.GRANT_AUTHORIZATION_CODE, entity.GRANT_REFRESH_TOKEN:
+		case entity.GRANT_CLIENT_CREDENTIALS:
+			if req.Public {
+				return fmt.Errorf("%w: public client can't use grant type %s", ErrInvalidClientMetadata, grantType)
+			}
+		default:
+			return fmt.Errorf("%w: unknown grant type %s", ErrInvalidClientMetadata, grantType)
+		}
+	}
+
+	if contains(req.GrantTypes, entity.GRANT_AUTHORIZATION_CODE) && len(req.RedirectURIs) == 0 {
+		return fmt.Errorf("%w: grant type %s requires a redirect uri", ErrInvalidClientMetadata, entity.GRANT_AUTHORIZATION_CODE)
+	}
+
+	for _, redirectURI := range req.RedirectURIs {
+		u, err := url.Parse(redirectURI)
+		if err != nil || u.Scheme == "" || u.Host == "" || u.Fragment != "" {
+			return fmt.Errorf("%w: invalid redirect uri %s", ErrInvalidClientMetadata, redirectURI)
+		}
+	}
+
+	return nil
+}
+
+// verifyCodeChallenge check the PKCE verifier against the S256 challenge, RFC 7636
+func verifyCodeChallenge(codeVerifier, codeChallenge string) bool {
+	if len(codeVerifier) < 43 || len(codeVerifier) > 128 {
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(codeVerifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(codeChallenge)) == 1
+}
+
+func generateSecret() (string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(random), nil
+}
+
+// client secrets and authorization codes are random enough to be hashed without salt
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(sum[:])
+}
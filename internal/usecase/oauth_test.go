@@ -0,0 +1,199 @@
+package usecase
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	repoMocks "github.com/adesupraptolaia/user_login/internal/repo/mocks"
+)
+
+func Test_oauth_ValidateAuthorizeRequest(t *testing.T) {
+	clientRepo := repoMocks.NewOAuthClientsRepo(t)
+
+	client := &entity.OAuthClient{
+		ClientID:     "client",
+		RedirectURIs: []string{"https://app.example.com/callback"},
+		GrantTypes:   []string{entity.GRANT_AUTHORIZATION_CODE},
+		Scopes:       []string{"profile"},
+	}
+
+	clientRepo.On("GetOAuthClient", "client").
+		Return(client, nil).
+		Times(4)
+
+	clientRepo.On("GetOAuthClient", "unknown").
+		Return(nil, fmt.Errorf("not found")).
+		Once()
+
+	valid := entity.AuthorizeRequest{
+		ResponseType:        "code",
+		ClientID:            "client",
+		Scope:               "profile",
+		CodeChallenge:       "challenge",
+		CodeChallengeMethod: "S256",
+	}
+
+	withoutPKCE := valid
+	withoutPKCE.CodeChallenge = ""
+
+	otherRedirectURI := valid
+	otherRedirectURI.RedirectURI = "https://evil.example.com/callback"
+
+	unknownScope := valid
+	unknownScope.Scope = "profile admin"
+
+	unknownClient := valid
+	unknownClient.ClientID = "unknown"
+
+	type fields struct {
+		clientRepo *repoMocks.OAuthClientsRepo
+	}
+	type args struct {
+		req entity.AuthorizeRequest
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name:    "Success Validate Authorize Request",
+			fields:  fields{clientRepo: clientRepo},
+			args:    args{valid},
+			wantErr: nil,
+		},
+		{
+			name:    "Failed Without PKCE",
+			fields:  fields{clientRepo: clientRepo},
+			args:    args{withoutPKCE},
+			wantErr: ErrPKCERequired,
+		},
+		{
+			name:    "Failed Unregistered Redirect URI",
+			fields:  fields{clientRepo: clientRepo},
+			args:    args{otherRedirectURI},
+			wantErr: ErrInvalidRedirectURI,
+		},
+		{
+			name:    "Failed Unregistered Scope",
+			fields:  fields{clientRepo: clientRepo},
+			args:    args{unknownScope},
+			wantErr: ErrInvalidScope,
+		},
+		{
+			name:    "Failed Unknown Client",
+			fields:  fields{clientRepo: clientRepo},
+			args:    args{unknownClient},
+			wantErr: ErrOAuthClientNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &oauth{
+				clientRepo: tt.fields.clientRepo,
+			}
+			_, err := uc.ValidateAuthorizeRequest(&tt.args.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("oauth.ValidateAuthorizeRequest() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && tt.args.req.RedirectURI != client.RedirectURIs[0] {
+				t.Errorf("oauth.ValidateAuthorizeRequest() redirect_uri = %s, want the registered one", tt.args.req.RedirectURI)
+			}
+		})
+	}
+}
+
+func Test_oauth_ExchangeAuthorizationCode(t *testing.T) {
+	codeRepo := repoMocks.NewAuthorizationCodesRepo(t)
+
+	client := &entity.OAuthClient{ClientID: "client", GrantTypes: []string{entity.GRANT_AUTHORIZATION_CODE}}
+	otherClient := &entity.OAuthClient{ClientID: "other", GrantTypes: []string{entity.GRANT_AUTHORIZATION_CODE}}
+
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	sum := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	storedCode := &entity.AuthorizationCode{
+		CodeHash:      hashSecret("code"),
+		ClientID:      "client",
+		UserKsuid:     "ksuid",
+		RedirectURI:   "https://app.example.com/callback",
+		CodeChallenge: challenge,
+		ExpiresAt:     time.Now().Add(AUTHORIZATION_CODE_TTL),
+	}
+
+	codeRepo.On("GetAuthorizationCode", hashSecret("code")).
+		Return(storedCode, nil).
+		Times(4)
+
+	codeRepo.On("UseAuthorizationCode", hashSecret("code")).
+		Return(nil).
+		Once()
+
+	codeRepo.On("UseAuthorizationCode", hashSecret("code")).
+		Return(fmt.Errorf("used")).
+		Once()
+
+	type fields struct {
+		codeRepo *repoMocks.AuthorizationCodesRepo
+	}
+	type args struct {
+		client       *entity.OAuthClient
+		code         string
+		redirectURI  string
+		codeVerifier string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name:    "Success Exchange Authorization Code",
+			fields:  fields{codeRepo: codeRepo},
+			args:    args{client, "code", "https://app.example.com/callback", verifier},
+			wantErr: nil,
+		},
+		{
+			name:    "Failed Reuse Authorization Code",
+			fields:  fields{codeRepo: codeRepo},
+			args:    args{client, "code", "https://app.example.com/callback", verifier},
+			wantErr: ErrInvalidGrant,
+		},
+		{
+			name:    "Failed Wrong Code Verifier",
+			fields:  fields{codeRepo: codeRepo},
+			args:    args{client, "code", "https://app.example.com/callback", verifier + "x"},
+			wantErr: ErrInvalidGrant,
+		},
+		{
+			name:    "Failed Code Issued To Other Client",
+			fields:  fields{codeRepo: codeRepo},
+			args:    args{otherClient, "code", "https://app.example.com/callback", verifier},
+			wantErr: ErrInvalidGrant,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &oauth{
+				codeRepo: tt.fields.codeRepo,
+			}
+			got, err := uc.ExchangeAuthorizationCode(tt.args.client, tt.args.code, tt.args.redirectURI, tt.args.codeVerifier)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("oauth.ExchangeAuthorizationCode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.UserKsuid != "ksuid" {
+				t.Errorf("oauth.ExchangeAuthorizationCode() = %v, want code of user ksuid", got)
+			}
+		})
+	}
+}
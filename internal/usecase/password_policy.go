@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+)
+
+// PasswordPolicy is checked on every new password, a zero value disables the rule
+type PasswordPolicy struct {
+	MinLength int
+	// in bytes, bcrypt ignores everything after the first 72
+	MaxLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	// the password must not contain the username, case insensitive
+	DisallowUsername bool
+	// number of latest passwords, the current one included, that can not be reused
+	HistorySize int
+}
+
+var ErrWeakPassword = errors.New("password does not satisfy the password policy")
+
+// PasswordPolicyError list every rule of the password policy the password fails
+type PasswordPolicyError struct {
+	Violations []entity.PasswordViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	messages := []string{}
+	for _, violation := range e.Violations {
+		messages = append(messages, violation.Message)
+	}
+
+	return fmt.Sprintf("%s: %s", ErrWeakPassword.Error(), strings.Join(messages, ", "))
+}
+
+func (e *PasswordPolicyError) Unwrap() error {
+	return ErrWeakPassword
+}
+
+// checkPassword return a *PasswordPolicyError when the password fails the policy,
+// previousHashes are the hashes of the passwords it must not reuse
+func (uc *user) checkPassword(username, password string, previousHashes []string) error {
+	violations := []entity.PasswordViolation{}
+	violate := func(rule, message string) {
+		violations = append(violations, entity.PasswordViolation{Rule: rule, Message: message})
+	}
+
+	policy := uc.policy
+
+	if policy.MinLength > 0 && utf8.RuneCountInString(password) < policy.MinLength {
+		violate(entity.PASSWORD_MIN_LENGTH, fmt.Sprintf("must be at least %d characters", policy.MinLength))
+	}
+
+	if policy.MaxLength > 0 && len(password) > policy.MaxLength {
+		violate(entity.PASSWORD_MAX_LENGTH, fmt.Sprintf("must be at most %d bytes", policy.MaxLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case !unicode.IsLetter(r):
+			hasSymbol = true
+		}
+	}
+
+	if policy.RequireUppercase && !hasUpper {
+		violate(entity.PASSWORD_UPPERCASE, "must contain an uppercase letter")
+	}
+
+	if policy.RequireLowercase && !hasLower {
+		violate(entity.PASSWORD_LOWERCASE, "must contain a lowercase letter")
+	}
+
+	if policy.RequireDigit && !hasDigit {
+		violate(entity.PASSWORD_DIGIT, "must contain a digit")
+	}
+
+	if policy.RequireSymbol && !hasSymbol {
+		violate(entity.PASSWORD_SYMBOL, "must contain a symbol")
+	}
+
+	if policy.DisallowUsername && username != "" && strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		violate(entity.PASSWORD_USERNAME, "must not contain the username")
+	}
+
+	for _, hash := range previousHashes {
+		if match, _ := uc.hasher.Verify(hash, password); match {
+			violate(entity.PASSWORD_HISTORY, fmt.Sprintf("must not be one of the last %d passwords", policy.HistorySize))
+			break
+		}
+	}
+
+	breached, err := uc.isPasswordBreached(password)
+	if err != nil {
+		return err
+	}
+	if breached {
+		violate(entity.PASSWORD_BREACHED, "has appeared in a data breach")
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+
+	return nil
+}
+
+// isPasswordBreached look the password up by k-anonymity, only the first 5 hex
+// of its SHA-1 select the range of breached hashes to compare with
+func (uc *user) isPasswordBreached(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	suffixes, err := uc.breachedRepo.GetHashSuffixes(hash[:5])
+	if err != nil {
+		return false, fmt.Errorf("failed when check breached password")
+	}
+
+	for _, suffix := range suffixes {
+		if suffix == hash[5:] {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
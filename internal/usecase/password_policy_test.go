@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	repoMocks "github.com/adesupraptolaia/user_login/internal/repo/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_user_checkPassword(t *testing.T) {
+	breachedRepo := repoMocks.NewBreachedPasswordsRepo(t)
+
+	// SHA-1 of "password" is 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+	breachedRepo.On("GetHashSuffixes", "5BAA6").
+		Return([]string{"1E4C9B93F3F0682250B6CF8331B7EE68FD8"}, nil)
+
+	// SHA-1 prefix of "Unreadable-Passw0rd"
+	breachedRepo.On("GetHashSuffixes", "1FB30").
+		Return(nil, fmt.Errorf("error read"))
+
+	breachedRepo.On("GetHashSuffixes", mock.AnythingOfType("string")).
+		Return(nil, nil)
+
+	policy := PasswordPolicy{
+		MinLength:        8,
+		MaxLength:        72,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireDigit:     true,
+		RequireSymbol:    true,
+		DisallowUsername: true,
+		HistorySize:      2,
+	}
+
+	type args struct {
+		username       string
+		password       string
+		previousHashes []string
+	}
+	tests := []struct {
+		name      string
+		args      args
+		wantRules []string
+		wantErr   bool
+	}{
+		{
+			name:    "Success Strong Password",
+			args:    args{"user", "Str0ng-Passw0rd", []string{hashPassword("Old-Passw0rd")}},
+			wantErr: false,
+		},
+		{
+			name: "Failed Short Password",
+			args: args{"user", "Ab1-", nil},
+			wantRules: []string{
+				entity.PASSWORD_MIN_LENGTH,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Failed Breached Password",
+			args: args{"user", "password", nil},
+			wantRules: []string{
+				entity.PASSWORD_UPPERCASE,
+				entity.PASSWORD_DIGIT,
+				entity.PASSWORD_SYMBOL,
+				entity.PASSWORD_BREACHED,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Failed Password With Username And Reused",
+			args: args{"user", "My-User-2023", []string{hashPassword("My-User-2023")}},
+			wantRules: []string{
+				entity.PASSWORD_USERNAME,
+				entity.PASSWORD_HISTORY,
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Failed Breached Password Check",
+			args:    args{"user", "Unreadable-Passw0rd", nil},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &user{
+				breachedRepo: breachedRepo,
+				policy:       policy,
+				hasher:       testHasher,
+			}
+			err := uc.checkPassword(tt.args.username, tt.args.password, tt.args.previousHashes)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("user.checkPassword() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantRules == nil {
+				return
+			}
+
+			policyErr := &PasswordPolicyError{}
+			if !errors.As(err, &policyErr) || !errors.Is(err, ErrWeakPassword) {
+				t.Errorf("user.checkPassword() error = %v, want PasswordPolicyError", err)
+				return
+			}
+
+			rules := []string{}
+			for _, violation := range policyErr.Violations {
+				rules = append(rules, violation.Rule)
+			}
+			if !reflect.DeepEqual(rules, tt.wantRules) {
+				t.Errorf("user.checkPassword() rules = %v, want %v", rules, tt.wantRules)
+			}
+		})
+	}
+}
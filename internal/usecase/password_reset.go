@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/adesupraptolaia/user_login/internal/repo"
+	"github.com/adesupraptolaia/user_login/pkg/notifier"
+	"gorm.io/gorm"
+)
+
+type PasswordResetUC interface {
+	ForgotPassword(username string) error
+	ResetPassword(token, newPassword string) (*entity.User, error)
+}
+
+var ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+
+type passwordReset struct {
+	repo     repo.PasswordResetsRepo
+	userUC   UserUC
+	notifier notifier.Notifier
+	ttl      time.Duration
+	// page of the client app to set the new password, the token is added as query parameter
+	resetURL string
+}
+
+func NewPasswordReset(repo repo.PasswordResetsRepo, userUC UserUC, notifier notifier.Notifier, ttl time.Duration, resetURL string) PasswordResetUC {
+	return &passwordReset{
+		repo:     repo,
+		userUC:   userUC,
+		notifier: notifier,
+		ttl:      ttl,
+		resetURL: resetURL,
+	}
+}
+
+// ForgotPassword send a password reset token to the verified email address of the user.
+// An unknown username, or a user without a verified email address, is not an error,
+// the caller must not learn which usernames exist.
+func (uc *passwordReset) ForgotPassword(username string) error {
+	user, err := uc.userUC.GetUserByUsername(username)
+	if err != nil {
+		return nil
+	}
+
+	// an invited user chooses the first password through the invitation
+	if user.IsInvitationPending() || !user.IsEmailVerified() {
+		return nil
+	}
+
+	token, err := generateSecret()
+	if err != nil {
+		return fmt.Errorf("failed when generate password reset token")
+	}
+
+	expiresAt := time.Now().Add(uc.ttl)
+	_, err = uc.repo.CreatePasswordReset(entity.PasswordReset{
+		TokenHash: hashSecret(token),
+		UserKsuid: user.Ksuid,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed when create password reset of user with ksuid %s", user.Ksuid)
+	}
+
+	body := fmt.Sprintf("Hi %s,\n\nUse this token to reset your password, it expires at %s:\n\n%s\n",
+		user.Username, expiresAt.Format(time.RFC1123), token)
+	if uc.resetURL != "" {
+		body += fmt.Sprintf("\nor open %s?token=%s\n", uc.resetURL, url.QueryEscape(token))
+	}
+	body += "\nIf you did not ask to reset your password, ignore this message.\n"
+
+	err = uc.notifier.Notify(notifier.Message{
+		To:      *user.Email,
+		Subject: "Reset your password",
+		Body:    body,
+	})
+	if err != nil {
+		return fmt.Errorf("failed when send password reset to user with ksuid %s", user.Ksuid)
+	}
+
+	return nil
+}
+
+// ResetPassword set the new password with a token from ForgotPassword. The token
+// is only used up once the new password satisfies the policy, then every other
+// pending token of the user is invalidated.
+func (uc *passwordReset) ResetPassword(token, newPassword string) (*entity.User, error) {
+	tokenHash := hashSecret(token)
+
+	reset, err := uc.repo.GetPasswordReset(tokenHash)
+	if err != nil || reset.UsedAt != nil || time.Now().After(reset.ExpiresAt) {
+		return nil, ErrInvalidResetToken
+	}
+
+	if err := uc.userUC.ValidateNewPassword(reset.UserKsuid, newPassword); err != nil {
+		return nil, err
+	}
+
+	// another request may have used the token meanwhile
+	err = uc.repo.UsePasswordReset(tokenHash)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidResetToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed when use password reset of user with ksuid %s", reset.UserKsuid)
+	}
+
+	user, err := uc.userUC.SetPassword(reset.UserKsuid, newPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.repo.UseUserPasswordResets(user.Ksuid); err != nil {
+		return nil, fmt.Errorf("failed when invalidate password resets of user with ksuid %s", user.Ksuid)
+	}
+
+	return user, nil
+}
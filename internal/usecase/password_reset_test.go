@@ -0,0 +1,254 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	repoMocks "github.com/adesupraptolaia/user_login/internal/repo/mocks"
+	"github.com/adesupraptolaia/user_login/pkg/notifier"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type fakeNotifier struct {
+	messages []notifier.Message
+	err      error
+}
+
+func (n *fakeNotifier) Notify(message notifier.Message) error {
+	if n.err != nil {
+		return n.err
+	}
+
+	n.messages = append(n.messages, message)
+	return nil
+}
+
+func Test_passwordReset_ForgotPassword(t *testing.T) {
+	repo := repoMocks.NewPasswordResetsRepo(t)
+	userRepo := repoMocks.NewUsersRepo(t)
+
+	email := "user@example.com"
+	verifiedAt := time.Now()
+	data := &entity.User{Ksuid: "ksuid", Username: "user", Password: hashPassword("user"), Role: entity.USER, Email: &email, EmailVerifiedAt: &verifiedAt}
+	unverified := &entity.User{Ksuid: "unverified", Username: "unverified", Password: hashPassword("user"), Role: entity.USER, Email: &email}
+
+	userRepo.On("GetUserByUsername", "user").
+		Return(data, nil).
+		Twice()
+
+	userRepo.On("GetUserByUsername", "unverified").
+		Return(unverified, nil).
+		Once()
+
+	userRepo.On("GetUserByUsername", "toni").
+		Return(nil, fmt.Errorf("not found")).
+		Once()
+
+	var tokenHash string
+	repo.On("CreatePasswordReset", mock.MatchedBy(func(reset entity.PasswordReset) bool {
+		return reset.UserKsuid == "ksuid" && reset.TokenHash != "" && reset.ExpiresAt.After(time.Now())
+	})).
+		Return(func(reset entity.PasswordReset) *entity.PasswordReset {
+			tokenHash = reset.TokenHash
+			return &reset
+		}, nil).
+		Twice()
+
+	type fields struct {
+		notifier *fakeNotifier
+	}
+	type args struct {
+		username string
+	}
+	tests := []struct {
+		name         string
+		fields       fields
+		args         args
+		wantMessages int
+		wantErr      bool
+	}{
+		{
+			name:         "Success Forgot Password",
+			fields:       fields{&fakeNotifier{}},
+			args:         args{"user"},
+			wantMessages: 1,
+			wantErr:      false,
+		},
+		{
+			name:         "Success Forgot Password, unknown username is not told",
+			fields:       fields{&fakeNotifier{}},
+			args:         args{"toni"},
+			wantMessages: 0,
+			wantErr:      false,
+		},
+		{
+			name:         "Success Forgot Password, no message without a verified email address",
+			fields:       fields{&fakeNotifier{}},
+			args:         args{"unverified"},
+			wantMessages: 0,
+			wantErr:      false,
+		},
+		{
+			name:         "Failed Forgot Password, notifier error",
+			fields:       fields{&fakeNotifier{err: fmt.Errorf("connection refused")}},
+			args:         args{"user"},
+			wantMessages: 0,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &passwordReset{
+				repo:     repo,
+				userUC:   &user{repo: userRepo, hasher: testHasher},
+				notifier: tt.fields.notifier,
+				ttl:      time.Minute,
+				resetURL: "http://localhost/reset",
+			}
+			err := uc.ForgotPassword(tt.args.username)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("passwordReset.ForgotPassword() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if len(tt.fields.notifier.messages) != tt.wantMessages {
+				t.Errorf("passwordReset.ForgotPassword() sent %d messages, want %d", len(tt.fields.notifier.messages), tt.wantMessages)
+				return
+			}
+			if tt.wantMessages == 0 {
+				return
+			}
+
+			if to := tt.fields.notifier.messages[0].To; to != email {
+				t.Errorf("passwordReset.ForgotPassword() sent to %q, want the verified email address", to)
+			}
+
+			// only the hash of the token sent is stored
+			token := regexp.MustCompile(`token=(\S+)`).FindStringSubmatch(tt.fields.notifier.messages[0].Body)
+			if token == nil || hashSecret(token[1]) != tokenHash {
+				t.Errorf("passwordReset.ForgotPassword() body = %q, want the token of hash %s", tt.fields.notifier.messages[0].Body, tokenHash)
+			}
+		})
+	}
+}
+
+func Test_passwordReset_ResetPassword(t *testing.T) {
+	repo := repoMocks.NewPasswordResetsRepo(t)
+	userRepo := repoMocks.NewUsersRepo(t)
+	breachedRepo := repoMocks.NewBreachedPasswordsRepo(t)
+
+	data := &entity.User{Ksuid: "ksuid", Username: "user", Password: hashPassword("user"), Role: entity.USER}
+	usedAt := time.Now()
+
+	repo.On("GetPasswordReset", hashSecret("valid")).
+		Return(&entity.PasswordReset{TokenHash: hashSecret("valid"), UserKsuid: "ksuid", ExpiresAt: time.Now().Add(time.Minute)}, nil).
+		Twice()
+
+	repo.On("GetPasswordReset", hashSecret("raced")).
+		Return(&entity.PasswordReset{TokenHash: hashSecret("raced"), UserKsuid: "ksuid", ExpiresAt: time.Now().Add(time.Minute)}, nil).
+		Once()
+
+	repo.On("GetPasswordReset", hashSecret("expired")).
+		Return(&entity.PasswordReset{TokenHash: hashSecret("expired"), UserKsuid: "ksuid", ExpiresAt: time.Now().Add(-time.Minute)}, nil).
+		Once()
+
+	repo.On("GetPasswordReset", hashSecret("used")).
+		Return(&entity.PasswordReset{TokenHash: hashSecret("used"), UserKsuid: "ksuid", ExpiresAt: time.Now().Add(time.Minute), UsedAt: &usedAt}, nil).
+		Once()
+
+	repo.On("GetPasswordReset", hashSecret("unknown")).
+		Return(nil, fmt.Errorf("not found")).
+		Once()
+
+	repo.On("UsePasswordReset", hashSecret("valid")).
+		Return(nil).
+		Once()
+
+	repo.On("UsePasswordReset", hashSecret("raced")).
+		Return(gorm.ErrRecordNotFound).
+		Once()
+
+	repo.On("UseUserPasswordResets", "ksuid").
+		Return(nil).
+		Once()
+
+	userRepo.On("GetUserByKsuid", "ksuid").
+		Return(func(string) *entity.User {
+			user := *data
+			return &user
+		}, nil).
+		Times(4)
+
+	breachedRepo.On("GetHashSuffixes", mock.AnythingOfType("string")).
+		Return(nil, nil).
+		Times(4)
+
+	userRepo.On("UpdateUser", "ksuid", mock.MatchedBy(func(user entity.User) bool {
+		return isPasswordMatch(user.Password, "newPassword") && user.PasswordChangedAt != nil
+	})).
+		Return(data, nil).
+		Once()
+
+	type args struct {
+		token       string
+		newPassword string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr error
+	}{
+		{
+			name:    "Failed Reset Password, weak password",
+			args:    args{"valid", "short"},
+			wantErr: ErrWeakPassword,
+		},
+		{
+			name:    "Success Reset Password",
+			args:    args{"valid", "newPassword"},
+			wantErr: nil,
+		},
+		{
+			name:    "Failed Reset Password, token used meanwhile",
+			args:    args{"raced", "newPassword"},
+			wantErr: ErrInvalidResetToken,
+		},
+		{
+			name:    "Failed Reset Password, expired token",
+			args:    args{"expired", "newPassword"},
+			wantErr: ErrInvalidResetToken,
+		},
+		{
+			name:    "Failed Reset Password, used token",
+			args:    args{"used", "newPassword"},
+			wantErr: ErrInvalidResetToken,
+		},
+		{
+			name:    "Failed Reset Password, unknown token",
+			args:    args{"unknown", "newPassword"},
+			wantErr: ErrInvalidResetToken,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &passwordReset{
+				repo: repo,
+				userUC: &user{
+					repo:         userRepo,
+					breachedRepo: breachedRepo,
+					policy:       PasswordPolicy{MinLength: 8},
+					hasher:       testHasher,
+				},
+				notifier: &fakeNotifier{},
+				ttl:      time.Minute,
+			}
+			if _, err := uc.ResetPassword(tt.args.token, tt.args.newPassword); !errors.Is(err, tt.wantErr) {
+				t.Errorf("passwordReset.ResetPassword() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
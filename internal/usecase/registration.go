@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/adesupraptolaia/user_login/internal/repo"
+)
+
+type RegistrationUC interface {
+	Register(entity.RegisterRequest) (*entity.UserProfile, error)
+}
+
+var (
+	ErrRegistrationDisabled  = errors.New("registration is disabled")
+	ErrInvalidInviteCode     = errors.New("invalid invite code")
+	ErrEmailDomainNotAllowed = errors.New("email domain is not allowed")
+	// the request is refused by auth service, e.g. a username already taken or a weak password
+	ErrRegistrationRejected = errors.New("registration rejected")
+)
+
+// RegistrationPolicy restrict who can register without an admin,
+// every restriction configured has to be met
+type RegistrationPolicy struct {
+	Enabled bool
+	// empty allows registering without an invite code
+	InviteCodes []string
+	// empty allows every email address, or none at all
+	AllowedEmailDomains []string
+}
+
+type registration struct {
+	userProfileUC UserProfileUC
+	policy        RegistrationPolicy
+}
+
+func NewRegistration(userProfileUC UserProfileUC, policy RegistrationPolicy) RegistrationUC {
+	return &registration{
+		userProfileUC: userProfileUC,
+		policy:        policy,
+	}
+}
+
+func (uc *registration) Register(req entity.RegisterRequest) (*entity.UserProfile, error) {
+	if !uc.policy.Enabled {
+		return nil, ErrRegistrationDisabled
+	}
+
+	if len(uc.policy.InviteCodes) > 0 && !uc.isValidInviteCode(req.InviteCode) {
+		return nil, ErrInvalidInviteCode
+	}
+
+	if len(uc.policy.AllowedEmailDomains) > 0 {
+		if !uc.isAllowedEmail(req.Email) {
+			return nil, ErrEmailDomainNotAllowed
+		}
+
+		// anyone can type an address of the domain, the account waits for its verification
+		req.EmailVerificationRequired = true
+	}
+
+	userProfile, err := uc.userProfileUC.CreateUserProfile(req.CreateUserRequest)
+	authErr := &repo.AuthError{}
+	if errors.As(err, &authErr) && (authErr.StatusCode == http.StatusBadRequest || authErr.StatusCode == http.StatusConflict) {
+		return nil, fmt.Errorf("%w, %s", ErrRegistrationRejected, authErr.Message)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return userProfile, nil
+}
+
+func (uc *registration) isValidInviteCode(code string) bool {
+	if code == "" {
+		return false
+	}
+
+	valid := false
+	for _, inviteCode := range uc.policy.InviteCodes {
+		// compare with every code, so the time taken doesn't tell which one almost matched
+		if subtle.ConstantTimeCompare([]byte(code), []byte(inviteCode)) == 1 {
+			valid = true
+		}
+	}
+
+	return valid
+}
+
+func (uc *registration) isAllowedEmail(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+
+	domain := strings.ToLower(strings.TrimSpace(email[at+1:]))
+	for _, allowed := range uc.policy.AllowedEmailDomains {
+		if domain == strings.ToLower(allowed) {
+			return true
+		}
+	}
+
+	return false
+}
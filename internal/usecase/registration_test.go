@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/adesupraptolaia/user_login/internal/repo"
+	repoMocks "github.com/adesupraptolaia/user_login/internal/repo/mocks"
+)
+
+func Test_registration_Register(t *testing.T) {
+	profileRepo := repoMocks.NewUserProfilesRepo(t)
+	authRepo := repoMocks.NewAuthRepo(t)
+
+	profile := entity.UserProfile{Name: "user", DateOfBirth: "2019-01-01", Address: "Perawang"}
+	created := entity.UserProfile{UserKsuid: "ksuid", Name: "user", DateOfBirth: "2019-01-01", Address: "Perawang"}
+
+	newRequest := func(username, email, inviteCode string) entity.RegisterRequest {
+		return entity.RegisterRequest{
+			CreateUserRequest: entity.CreateUserRequest{
+				UserProfile: profile, Username: username, Password: "Password123", Email: email,
+			},
+			InviteCode: inviteCode,
+		}
+	}
+
+	email := "user@example.com"
+	authRepo.On("CreateUser", entity.User{Username: "user", Password: "Password123", Email: &email}).
+		Return(&entity.User{Ksuid: "ksuid", Username: "user"}, nil)
+	profileRepo.On("CreateUserProfile", created).
+		Return(&created, nil)
+
+	// an account registered through an allowed email domain waits for the verification of the email
+	authRepo.On("CreateUser", entity.User{Username: "user", Password: "Password123", Email: &email, EmailVerificationRequired: true}).
+		Return(&entity.User{Ksuid: "ksuid", Username: "user", EmailVerificationRequired: true}, nil)
+
+	takenEmail := "taken@example.com"
+	authRepo.On("CreateUser", entity.User{Username: "taken", Password: "Password123", Email: &takenEmail}).
+		Return(nil, &repo.AuthError{StatusCode: http.StatusConflict, Message: "user with username taken already exist"})
+
+	downEmail := "down@example.com"
+	authRepo.On("CreateUser", entity.User{Username: "down", Password: "Password123", Email: &downEmail}).
+		Return(nil, &repo.AuthError{StatusCode: http.StatusInternalServerError, Message: "database is down"})
+
+	type args struct {
+		policy RegistrationPolicy
+		req    entity.RegisterRequest
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *entity.UserProfile
+		wantErr error
+	}{
+		{
+			name: "Success Register",
+			args: args{RegistrationPolicy{Enabled: true}, newRequest("user", "user@example.com", "")},
+			want: &created,
+		},
+		{
+			name: "Success Register With Invite Code",
+			args: args{
+				RegistrationPolicy{Enabled: true, InviteCodes: []string{"first", "second"}},
+				newRequest("user", "user@example.com", "second"),
+			},
+			want: &created,
+		},
+		{
+			name: "Success Register With Allowed Email Domain",
+			args: args{
+				RegistrationPolicy{Enabled: true, AllowedEmailDomains: []string{"Example.com"}},
+				newRequest("user", "user@example.com", ""),
+			},
+			want: &created,
+		},
+		{
+			name:    "Failed Registration Disabled",
+			args:    args{RegistrationPolicy{}, newRequest("user", "user@example.com", "")},
+			wantErr: ErrRegistrationDisabled,
+		},
+		{
+			name: "Failed Wrong Invite Code",
+			args: args{
+				RegistrationPolicy{Enabled: true, InviteCodes: []string{"first"}},
+				newRequest("user", "user@example.com", "second"),
+			},
+			wantErr: ErrInvalidInviteCode,
+		},
+		{
+			name: "Failed Without Invite Code",
+			args: args{
+				RegistrationPolicy{Enabled: true, InviteCodes: []string{"first"}},
+				newRequest("user", "user@example.com", ""),
+			},
+			wantErr: ErrInvalidInviteCode,
+		},
+		{
+			name: "Failed Email Domain Not Allowed",
+			args: args{
+				RegistrationPolicy{Enabled: true, AllowedEmailDomains: []string{"example.com"}},
+				newRequest("user", "user@other.com", ""),
+			},
+			wantErr: ErrEmailDomainNotAllowed,
+		},
+		{
+			name: "Failed Without Email When Domains Allowed",
+			args: args{
+				RegistrationPolicy{Enabled: true, AllowedEmailDomains: []string{"example.com"}},
+				newRequest("user", "", ""),
+			},
+			wantErr: ErrEmailDomainNotAllowed,
+		},
+		{
+			name:    "Failed Username Taken",
+			args:    args{RegistrationPolicy{Enabled: true}, newRequest("taken", "taken@example.com", "")},
+			wantErr: ErrRegistrationRejected,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &registration{
+				userProfileUC: &userProfile{repo: profileRepo, auth: authRepo},
+				policy:        tt.args.policy,
+			}
+			got, err := uc.Register(tt.args.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("registration.Register() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("registration.Register() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("Failed Auth Service Error", func(t *testing.T) {
+		uc := &registration{
+			userProfileUC: &userProfile{repo: profileRepo, auth: authRepo},
+			policy:        RegistrationPolicy{Enabled: true},
+		}
+		_, err := uc.Register(newRequest("down", "down@example.com", ""))
+		if err == nil || errors.Is(err, ErrRegistrationRejected) {
+			t.Errorf("registration.Register() error = %v, want an internal error", err)
+		}
+	})
+}
@@ -0,0 +1,208 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/adesupraptolaia/user_login/internal/repo"
+)
+
+type RoleUC interface {
+	GetRoles() ([]entity.Role, error)
+	CreateRole(entity.RoleRequest) (*entity.Role, error)
+	SetRolePermissions(string, []string) (*entity.Role, error)
+	DeleteRole(string) error
+	GetPermissions() ([]entity.Permission, error)
+	CreatePermission(entity.PermissionRequest) (*entity.Permission, error)
+	AssignRole(string, string) (*entity.User, error)
+}
+
+var (
+	ErrRoleNotFound       = errors.New("role not found")
+	ErrRoleAlreadyExists  = errors.New("role already exist")
+	ErrRoleInUse          = errors.New("role is assigned to users")
+	ErrUnknownPermission  = errors.New("unknown permission")
+	ErrPermissionExists   = errors.New("permission already exist")
+	ErrBuiltInRole        = errors.New("built-in role can't be deleted")
+	ErrServiceRole        = errors.New("role " + entity.SERVICE + " is only for service clients")
+	ErrAdminRoleLockedOut = errors.New("admin role must keep permission " + entity.PERM_ROLE_MANAGE)
+)
+
+type role struct {
+	repo           repo.RolesRepo
+	permissionRepo repo.PermissionsRepo
+	userRepo       repo.UsersRepo
+}
+
+func NewRole(roleRepo repo.RolesRepo, permissionRepo repo.PermissionsRepo, userRepo repo.UsersRepo) RoleUC {
+	return &role{
+		repo:           roleRepo,
+		permissionRepo: permissionRepo,
+		userRepo:       userRepo,
+	}
+}
+
+func (uc *role) GetRoles() ([]entity.Role, error) {
+	roles, err := uc.repo.GetRoles()
+	if err != nil {
+		return nil, fmt.Errorf("failed when get roles")
+	}
+
+	return roles, nil
+}
+
+func (uc *role) CreateRole(req entity.RoleRequest) (*entity.Role, error) {
+	if role, _ := uc.repo.GetRole(req.Name); role != nil {
+		return nil, fmt.Errorf("%w %s", ErrRoleAlreadyExists, req.Name)
+	}
+
+	if err := uc.checkPermissions(req.Permissions); err != nil {
+		return nil, err
+	}
+
+	role, err := uc.repo.CreateRole(entity.Role{
+		Name:        req.Name,
+		Permissions: req.Permissions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed when create role %s", req.Name)
+	}
+
+	return role, nil
+}
+
+// SetRolePermissions replace every permission of the role, users get
+// the new permissions on their next login or token refresh
+func (uc *role) SetRolePermissions(name string, permissions []string) (*entity.Role, error) {
+	if _, err := uc.repo.GetRole(name); err != nil {
+		return nil, ErrRoleNotFound
+	}
+
+	if err := uc.checkPermissions(permissions); err != nil {
+		return nil, err
+	}
+
+	if name == entity.ADMIN && !contains(permissions, entity.PERM_ROLE_MANAGE) {
+		return nil, ErrAdminRoleLockedOut
+	}
+
+	if err := uc.repo.SetRolePermissions(name, permissions); err != nil {
+		return nil, fmt.Errorf("failed when set permissions of role %s", name)
+	}
+
+	return uc.repo.GetRole(name)
+}
+
+func (uc *role) DeleteRole(name string) error {
+	if name == entity.ADMIN || name == entity.USER || name == entity.SERVICE {
+		return ErrBuiltInRole
+	}
+
+	if _, err := uc.repo.GetRole(name); err != nil {
+		return ErrRoleNotFound
+	}
+
+	// the users of a deleted role could not login anymore, they have to be assigned another role first
+	count, err := uc.userRepo.CountUsersByRole(name)
+	if err != nil {
+		return fmt.Errorf("failed when count users of role %s", name)
+	}
+	if count > 0 {
+		return fmt.Errorf("%w %s, %d users", ErrRoleInUse, name, count)
+	}
+
+	if err := uc.repo.DeleteRole(name); err != nil {
+		return fmt.Errorf("failed when delete role %s", name)
+	}
+
+	return nil
+}
+
+func (uc *role) GetPermissions() ([]entity.Permission, error) {
+	permissions, err := uc.permissionRepo.GetPermissions()
+	if err != nil {
+		return nil, fmt.Errorf("failed when get permissions")
+	}
+
+	return permissions, nil
+}
+
+func (uc *role) CreatePermission(req entity.PermissionRequest) (*entity.Permission, error) {
+	known, err := uc.permissionRepo.GetPermissions()
+	if err != nil {
+		return nil, fmt.Errorf("failed when get permissions")
+	}
+
+	for _, permission := range known {
+		if permission.Name == req.Name {
+			return nil, fmt.Errorf("%w %s", ErrPermissionExists, req.Name)
+		}
+	}
+
+	permission, err := uc.permissionRepo.CreatePermission(entity.Permission{
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed when create permission %s", req.Name)
+	}
+
+	return permission, nil
+}
+
+// AssignRole change the role of the user, the refresh tokens issued
+// for the previous role are rejected
+func (uc *role) AssignRole(userKsuid, name string) (*entity.User, error) {
+	if name == entity.SERVICE {
+		return nil, ErrServiceRole
+	}
+
+	user, err := uc.userRepo.GetUserByKsuid(userKsuid)
+	if err != nil {
+		return nil, fmt.Errorf("user with ksuid %s not exist", userKsuid)
+	}
+
+	if _, err := uc.repo.GetRole(name); err != nil {
+		return nil, ErrRoleNotFound
+	}
+
+	user.Role = name
+
+	userResp, err := uc.userRepo.UpdateUser(userKsuid, *user)
+	if err != nil {
+		return nil, fmt.Errorf("failed when assign role %s to user with ksuid %s", name, userKsuid)
+	}
+
+	return userResp, nil
+}
+
+func (uc *role) checkPermissions(permissions []string) error {
+	known, err := uc.permissionRepo.GetPermissions()
+	if err != nil {
+		return fmt.Errorf("failed when get permissions")
+	}
+
+	names := make([]string, len(known))
+	for i, permission := range known {
+		names[i] = permission.Name
+	}
+
+	for _, permission := range permissions {
+		if !contains(names, permission) {
+			return fmt.Errorf("%w %s", ErrUnknownPermission, permission)
+		}
+	}
+
+	return nil
+}
+
+func contains(list []string, item string) bool {
+	for _, i := range list {
+		if i == item {
+			return true
+		}
+	}
+
+	return false
+}
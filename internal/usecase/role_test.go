@@ -0,0 +1,208 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	repoMocks "github.com/adesupraptolaia/user_login/internal/repo/mocks"
+)
+
+func Test_role_SetRolePermissions(t *testing.T) {
+	repo := repoMocks.NewRolesRepo(t)
+	permissionRepo := repoMocks.NewPermissionsRepo(t)
+
+	support := &entity.Role{Name: "support", Permissions: []string{entity.PERM_PROFILE_READ_ANY}}
+	admin := &entity.Role{Name: entity.ADMIN, Permissions: []string{entity.PERM_ROLE_MANAGE}}
+	permissions := []entity.Permission{{Name: entity.PERM_PROFILE_READ_ANY}, {Name: entity.PERM_ROLE_MANAGE}}
+
+	repo.On("GetRole", "support").
+		Return(support, nil).
+		Times(3)
+
+	repo.On("GetRole", entity.ADMIN).
+		Return(admin, nil).
+		Once()
+
+	repo.On("GetRole", "unknown").
+		Return(nil, fmt.Errorf("not found")).
+		Once()
+
+	permissionRepo.On("GetPermissions").
+		Return(permissions, nil).
+		Times(3)
+
+	repo.On("SetRolePermissions", "support", []string{entity.PERM_PROFILE_READ_ANY}).
+		Return(nil).
+		Once()
+
+	type fields struct {
+		repo           *repoMocks.RolesRepo
+		permissionRepo *repoMocks.PermissionsRepo
+	}
+	type args struct {
+		name        string
+		permissions []string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name:    "Success Set Role Permissions",
+			fields:  fields{repo: repo, permissionRepo: permissionRepo},
+			args:    args{"support", []string{entity.PERM_PROFILE_READ_ANY}},
+			wantErr: nil,
+		},
+		{
+			name:    "Failed Unknown Permission",
+			fields:  fields{repo: repo, permissionRepo: permissionRepo},
+			args:    args{"support", []string{"profile:fly"}},
+			wantErr: ErrUnknownPermission,
+		},
+		{
+			name:    "Failed Remove Role Manage From Admin",
+			fields:  fields{repo: repo, permissionRepo: permissionRepo},
+			args:    args{entity.ADMIN, []string{entity.PERM_PROFILE_READ_ANY}},
+			wantErr: ErrAdminRoleLockedOut,
+		},
+		{
+			name:    "Failed Unknown Role",
+			fields:  fields{repo: repo, permissionRepo: permissionRepo},
+			args:    args{"unknown", []string{entity.PERM_PROFILE_READ_ANY}},
+			wantErr: ErrRoleNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &role{
+				repo:           tt.fields.repo,
+				permissionRepo: tt.fields.permissionRepo,
+			}
+			if _, err := uc.SetRolePermissions(tt.args.name, tt.args.permissions); !errors.Is(err, tt.wantErr) {
+				t.Errorf("role.SetRolePermissions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_role_DeleteRole(t *testing.T) {
+	repo := repoMocks.NewRolesRepo(t)
+	userRepo := repoMocks.NewUsersRepo(t)
+
+	repo.On("GetRole", "support").
+		Return(&entity.Role{Name: "support"}, nil).
+		Once()
+
+	repo.On("GetRole", "auditor").
+		Return(&entity.Role{Name: "auditor"}, nil).
+		Once()
+
+	repo.On("GetRole", "unknown").
+		Return(nil, fmt.Errorf("not found")).
+		Once()
+
+	userRepo.On("CountUsersByRole", "support").
+		Return(int64(0), nil).
+		Once()
+
+	userRepo.On("CountUsersByRole", "auditor").
+		Return(int64(2), nil).
+		Once()
+
+	repo.On("DeleteRole", "support").
+		Return(nil).
+		Once()
+
+	type fields struct {
+		repo     *repoMocks.RolesRepo
+		userRepo *repoMocks.UsersRepo
+	}
+	type args struct {
+		name string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name:    "Success Delete Role",
+			fields:  fields{repo: repo, userRepo: userRepo},
+			args:    args{"support"},
+			wantErr: nil,
+		},
+		{
+			name:    "Failed Delete Built-in Role",
+			fields:  fields{repo: repo, userRepo: userRepo},
+			args:    args{entity.USER},
+			wantErr: ErrBuiltInRole,
+		},
+		{
+			name:    "Failed Delete Role Assigned To Users",
+			fields:  fields{repo: repo, userRepo: userRepo},
+			args:    args{"auditor"},
+			wantErr: ErrRoleInUse,
+		},
+		{
+			name:    "Failed Unknown Role",
+			fields:  fields{repo: repo, userRepo: userRepo},
+			args:    args{"unknown"},
+			wantErr: ErrRoleNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &role{
+				repo:     tt.fields.repo,
+				userRepo: tt.fields.userRepo,
+			}
+			if err := uc.DeleteRole(tt.args.name); !errors.Is(err, tt.wantErr) {
+				t.Errorf("role.DeleteRole() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_role_CreatePermission(t *testing.T) {
+	permissionRepo := repoMocks.NewPermissionsRepo(t)
+
+	permissionRepo.On("GetPermissions").
+		Return([]entity.Permission{{Name: entity.PERM_PROFILE_READ_ANY}}, nil).
+		Twice()
+
+	permissionRepo.On("CreatePermission", entity.Permission{Name: "report:read", Description: "read reports"}).
+		Return(&entity.Permission{Name: "report:read", Description: "read reports"}, nil).
+		Once()
+
+	tests := []struct {
+		name    string
+		req     entity.PermissionRequest
+		wantErr error
+	}{
+		{
+			name:    "Success Create Permission",
+			req:     entity.PermissionRequest{Name: "report:read", Description: "read reports"},
+			wantErr: nil,
+		},
+		{
+			name:    "Failed Permission Already Exist",
+			req:     entity.PermissionRequest{Name: entity.PERM_PROFILE_READ_ANY, Description: "read any profile"},
+			wantErr: ErrPermissionExists,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &role{
+				permissionRepo: permissionRepo,
+			}
+			if _, err := uc.CreatePermission(tt.req); !errors.Is(err, tt.wantErr) {
+				t.Errorf("role.CreatePermission() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/adesupraptolaia/user_login/internal/repo"
+	"github.com/adesupraptolaia/user_login/pkg/jwt"
+)
+
+type ServiceClientUC interface {
+	CreateServiceToken(string, string) (*entity.ServiceToken, error)
+}
+
+var ErrInvalidClient = errors.New("invalid client credentials")
+
+type serviceClient struct {
+	clients  []entity.ServiceClient
+	roleRepo repo.RolesRepo
+}
+
+func NewServiceClient(clients []entity.ServiceClient, roleRepo repo.RolesRepo) ServiceClientUC {
+	return &serviceClient{
+		clients:  clients,
+		roleRepo: roleRepo,
+	}
+}
+
+// CreateServiceToken issue an access token with the permissions of role SERVICE
+// for the client identified by its id and secret (client credentials grant)
+func (uc *serviceClient) CreateServiceToken(clientID, clientSecret string) (*entity.ServiceToken, error) {
+	if !uc.authenticate(clientID, clientSecret) {
+		return nil, ErrInvalidClient
+	}
+
+	permissions, err := uc.roleRepo.GetRolePermissions(entity.SERVICE)
+	if err != nil {
+		return nil, fmt.Errorf("failed when get permissions of role %s", entity.SERVICE)
+	}
+
+	accessToken, err := jwt.CreateServiceToken(clientID, permissions)
+	if err != nil {
+		return nil, fmt.Errorf("failed when create service token, err: %s", err.Error())
+	}
+
+	return &entity.ServiceToken{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(jwt.SERVICE_TOKEN_TTL.Seconds()),
+	}, nil
+}
+
+func (uc *serviceClient) authenticate(clientID, clientSecret string) bool {
+	if clientID == "" || clientSecret == "" {
+		return false
+	}
+
+	for _, client := range uc.clients {
+		if client.ClientID != clientID || client.ClientSecret == "" {
+			continue
+		}
+
+		return subtle.ConstantTimeCompare([]byte(client.ClientSecret), []byte(clientSecret)) == 1
+	}
+
+	return false
+}
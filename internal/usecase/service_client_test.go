@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	repoMocks "github.com/adesupraptolaia/user_login/internal/repo/mocks"
+	"github.com/adesupraptolaia/user_login/pkg/jwt"
+)
+
+func Test_serviceClient_CreateServiceToken(t *testing.T) {
+	roleRepo := repoMocks.NewRolesRepo(t)
+
+	clients := []entity.ServiceClient{{ClientID: "user-app", ClientSecret: "secret"}}
+
+	roleRepo.On("GetRolePermissions", entity.SERVICE).
+		Return(entity.SERVICE_PERMISSIONS, nil).
+		Once()
+
+	type fields struct {
+		clients  []entity.ServiceClient
+		roleRepo *repoMocks.RolesRepo
+	}
+	type args struct {
+		clientID     string
+		clientSecret string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name:    "Success Create Service Token",
+			fields:  fields{clients: clients, roleRepo: roleRepo},
+			args:    args{"user-app", "secret"},
+			wantErr: nil,
+		},
+		{
+			name:    "Failed Wrong Client Secret",
+			fields:  fields{clients: clients, roleRepo: roleRepo},
+			args:    args{"user-app", "wrong"},
+			wantErr: ErrInvalidClient,
+		},
+		{
+			name:    "Failed Unknown Client",
+			fields:  fields{clients: clients, roleRepo: roleRepo},
+			args:    args{"unknown", "secret"},
+			wantErr: ErrInvalidClient,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &serviceClient{
+				clients:  tt.fields.clients,
+				roleRepo: tt.fields.roleRepo,
+			}
+			got, err := uc.CreateServiceToken(tt.args.clientID, tt.args.clientSecret)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("serviceClient.CreateServiceToken() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+
+			claims, err := jwt.GetAccessTokenClaims(got.AccessToken, jwt.AUTH_PRIVATE_AUDIENCE)
+			if err != nil {
+				t.Errorf("serviceClient.CreateServiceToken() invalid token, err = %v", err)
+				return
+			}
+			if claims.Role != entity.SERVICE || claims.UserKsuid != "user-app" || !claims.HasPermission(entity.PERM_USER_CREATE) {
+				t.Errorf("serviceClient.CreateServiceToken() claims = %+v", claims)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/adesupraptolaia/user_login/internal/repo"
+	"github.com/adesupraptolaia/user_login/pkg/jwt"
+)
+
+type SigningKeyUC interface {
+	LoadKeys() error
+	RotateKey(string) (*entity.SigningKey, error)
+}
+
+type signingKey struct {
+	repo repo.SigningKeysRepo
+}
+
+func NewSigningKey(repo repo.SigningKeysRepo) SigningKeyUC {
+	return &signingKey{
+		repo: repo,
+	}
+}
+
+// LoadKeys load the rotated keys into the key ring of every token type
+func (uc *signingKey) LoadKeys() error {
+	for _, tokenType := range []string{entity.ACCESS_TOKEN, entity.REFRESH_TOKEN} {
+		retiredSince := time.Now().Add(-jwt.KeyVerificationWindow(tokenType))
+
+		keys, err := uc.repo.GetSigningKeys(tokenType, retiredSince)
+		if err != nil {
+			return fmt.Errorf("failed when get %s token signing keys", tokenType)
+		}
+
+		if err := jwt.SetKeys(tokenType, keys); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RotateKey add a new signing key for the token type, the previous keys keep
+// verifying tokens until every token they have signed is expired
+func (uc *signingKey) RotateKey(tokenType string) (*entity.SigningKey, error) {
+	key, err := jwt.GenerateKey(tokenType)
+	if err != nil {
+		return nil, fmt.Errorf("failed when generate %s token signing key, err: %s", tokenType, err.Error())
+	}
+
+	newKey, err := uc.repo.CreateSigningKey(*key)
+	if err != nil {
+		return nil, fmt.Errorf("failed when save %s token signing key", tokenType)
+	}
+
+	if err := uc.repo.RetireSigningKeys(tokenType, newKey.Kid); err != nil {
+		return nil, fmt.Errorf("failed when retire %s token signing keys", tokenType)
+	}
+
+	if err := uc.LoadKeys(); err != nil {
+		return nil, err
+	}
+
+	return newKey, nil
+}
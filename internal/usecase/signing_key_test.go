@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	repoMocks "github.com/adesupraptolaia/user_login/internal/repo/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_signingKey_RotateKey(t *testing.T) {
+	repo := repoMocks.NewSigningKeysRepo(t)
+
+	repo.On("CreateSigningKey", mock.MatchedBy(func(key entity.SigningKey) bool {
+		return key.TokenType == entity.REFRESH_TOKEN && key.Kid != "" && key.PrivateKey != ""
+	})).
+		Return(func(key entity.SigningKey) *entity.SigningKey {
+			return &key
+		}, nil).
+		Once()
+
+	repo.On("CreateSigningKey", mock.AnythingOfType("entity.SigningKey")).
+		Return(nil, fmt.Errorf("error insert")).
+		Once()
+
+	repo.On("RetireSigningKeys", entity.REFRESH_TOKEN, mock.AnythingOfType("string")).
+		Return(nil).
+		Once()
+
+	repo.On("GetSigningKeys", mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).
+		Return([]entity.SigningKey{}, nil).
+		Twice()
+
+	type fields struct {
+		repo *repoMocks.SigningKeysRepo
+	}
+	type args struct {
+		tokenType string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr bool
+	}{
+		{
+			name:    "Success Rotate Key",
+			fields:  fields{repo: repo},
+			args:    args{entity.REFRESH_TOKEN},
+			wantErr: false,
+		},
+		{
+			name:    "Failed Rotate Key",
+			fields:  fields{repo: repo},
+			args:    args{entity.REFRESH_TOKEN},
+			wantErr: true,
+		},
+		{
+			name:    "Failed Rotate Key Unknown Token Type",
+			fields:  fields{repo: repo},
+			args:    args{"unknown"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &signingKey{
+				repo: tt.fields.repo,
+			}
+			got, err := uc.RotateKey(tt.args.tokenType)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("signingKey.RotateKey() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.TokenType != tt.args.tokenType {
+				t.Errorf("signingKey.RotateKey() = %v, want token type %v", got, tt.args.tokenType)
+			}
+		})
+	}
+}
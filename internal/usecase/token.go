@@ -0,0 +1,424 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/adesupraptolaia/user_login/internal/repo"
+	"github.com/adesupraptolaia/user_login/pkg/jwt"
+	"github.com/segmentio/ksuid"
+)
+
+type TokenUC interface {
+	CreateToken(*entity.User) (*entity.Token, error)
+	CreateClientSessionToken(*entity.User, string, string) (*entity.Token, error)
+	RefreshToken(string, string) (*entity.Token, error)
+	Logout(string) error
+	RevokeUserTokens(string) error
+	Introspect(string) (*entity.Introspection, error)
+	IsAccessTokenActive(*jwt.Claims) (bool, error)
+	Revoke(string) error
+	ValidateMfaToken(string) (*jwt.Claims, error)
+	UseMfaToken(*jwt.Claims) error
+}
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrMfaTokenUsed        = errors.New("mfa token is already used")
+)
+
+type token struct {
+	repo        repo.RefreshTokensRepo
+	sessionRepo repo.SessionsRepo
+	userRepo    repo.UsersRepo
+	roleRepo    repo.RolesRepo
+	revokedRepo repo.RevokedTokensRepo
+}
+
+func NewToken(refreshTokenRepo repo.RefreshTokensRepo, sessionRepo repo.SessionsRepo, userRepo repo.UsersRepo, roleRepo repo.RolesRepo, revokedTokenRepo repo.RevokedTokensRepo) TokenUC {
+	return &token{
+		repo:        refreshTokenRepo,
+		sessionRepo: sessionRepo,
+		userRepo:    userRepo,
+		roleRepo:    roleRepo,
+		revokedRepo: revokedTokenRepo,
+	}
+}
+
+// CreateToken start a new session and issue an access token and the first refresh token of the session
+func (uc *token) CreateToken(user *entity.User) (*entity.Token, error) {
+	session, err := uc.sessionRepo.CreateSession(entity.Session{
+		Ksuid:     ksuid.New().String(),
+		UserKsuid: user.Ksuid,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed when create session")
+	}
+
+	return uc.createToken(user, session)
+}
+
+// CreateClientSessionToken start a session of the user for the OAuth client, its tokens
+// are only for user app and carry the permissions of the role within the granted scope
+func (uc *token) CreateClientSessionToken(user *entity.User, clientID, scope string) (*entity.Token, error) {
+	session, err := uc.sessionRepo.CreateSession(entity.Session{
+		Ksuid:     ksuid.New().String(),
+		UserKsuid: user.Ksuid,
+		ClientID:  clientID,
+		Scope:     scope,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed when create session")
+	}
+
+	return uc.createToken(user, session)
+}
+
+// RefreshToken rotate the given refresh token, the old one can't be used anymore.
+// Presenting an already rotated token revokes the whole family, because either
+// the legitimate user or an attacker is holding a stolen copy.
+// The token is only accepted from the client its session was started by, none for /login.
+func (uc *token) RefreshToken(refreshToken, clientID string) (*entity.Token, error) {
+	claims, err := jwt.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	storedToken, err := uc.repo.GetRefreshToken(claims.Id)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if storedToken.RevokedAt != nil {
+		uc.revokeFamily(storedToken.FamilyKsuid)
+		return nil, ErrInvalidRefreshToken
+	}
+
+	session, err := uc.sessionRepo.GetSession(storedToken.FamilyKsuid)
+	if err != nil || session.RevokedAt != nil || session.ClientID != clientID {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := uc.userRepo.GetUserByKsuid(claims.UserKsuid)
+	if err != nil || user.Role != claims.Role {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// refresh token issued before the last password change is no longer valid
+	if user.PasswordChangedAt != nil && claims.IssuedAt < user.PasswordChangedAt.Unix() {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if err := uc.repo.RevokeRefreshToken(storedToken.Jti); err != nil {
+		// the token has been rotated concurrently, treat it as reuse
+		uc.revokeFamily(storedToken.FamilyKsuid)
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return uc.createToken(user, session)
+}
+
+// Logout end the session of the given refresh token
+func (uc *token) Logout(refreshToken string) error {
+	claims, err := jwt.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return ErrInvalidRefreshToken
+	}
+
+	storedToken, err := uc.repo.GetRefreshToken(claims.Id)
+	if err != nil {
+		return ErrInvalidRefreshToken
+	}
+
+	if err := uc.sessionRepo.RevokeSession(storedToken.FamilyKsuid); err != nil {
+		return fmt.Errorf("failed when revoke session with ksuid %s", storedToken.FamilyKsuid)
+	}
+
+	if err := uc.repo.RevokeFamily(storedToken.FamilyKsuid); err != nil {
+		return fmt.Errorf("failed when revoke refresh tokens of session with ksuid %s", storedToken.FamilyKsuid)
+	}
+
+	return nil
+}
+
+// RevokeUserTokens end every session of the user
+func (uc *token) RevokeUserTokens(userKsuid string) error {
+	if err := uc.sessionRepo.RevokeUserSessions(userKsuid); err != nil {
+		return fmt.Errorf("failed when revoke sessions of user with ksuid %s", userKsuid)
+	}
+
+	if err := uc.repo.RevokeUserRefreshTokens(userKsuid); err != nil {
+		return fmt.Errorf("failed when revoke refresh tokens of user with ksuid %s", userKsuid)
+	}
+
+	return nil
+}
+
+// Introspect report whether an access or refresh token is active, RFC 7662.
+// Besides the signature and expiry, the token must not be revoked, its session
+// not ended, and its user not deleted, nor changed password or role since.
+func (uc *token) Introspect(tokenString string) (*entity.Introspection, error) {
+	// access and refresh tokens are signed by different keys, at most one of them verifies
+	introspection, err := uc.introspectAccessToken(tokenString)
+	if err != nil || introspection != nil {
+		return introspection, err
+	}
+
+	introspection, err = uc.introspectRefreshToken(tokenString)
+	if err != nil || introspection != nil {
+		return introspection, err
+	}
+
+	return &entity.Introspection{Active: false}, nil
+}
+
+// Revoke an access or refresh token, RFC 7009. Revoking a refresh token ends
+// its session, an access token is denied until it expires.
+// An invalid token is not an error, there is nothing left to revoke.
+func (uc *token) Revoke(tokenString string) error {
+	if claims, err := jwt.IntrospectAccessToken(tokenString); err == nil {
+		return uc.revokeAccessToken(claims)
+	}
+
+	err := uc.Logout(tokenString)
+	if errors.Is(err, ErrInvalidRefreshToken) {
+		return nil
+	}
+
+	return err
+}
+
+// ValidateMfaToken validate the MFA pending token, until its code is verified
+// it can be sent again with another code, but it can't login twice
+func (uc *token) ValidateMfaToken(tokenString string) (*jwt.Claims, error) {
+	claims, err := jwt.ValidateMfaToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	used, err := uc.revokedRepo.IsTokenRevoked(claims.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed when check used mfa token with jti %s", claims.Id)
+	}
+	if used {
+		return nil, ErrMfaTokenUsed
+	}
+
+	return claims, nil
+}
+
+// UseMfaToken record the MFA pending token once its code is verified, only the first use logs in
+func (uc *token) UseMfaToken(claims *jwt.Claims) error {
+	used, err := uc.revokedRepo.UseToken(entity.RevokedToken{
+		Jti:       claims.Id,
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0),
+	})
+	if err != nil {
+		return fmt.Errorf("failed when use mfa token with jti %s", claims.Id)
+	}
+	if !used {
+		return ErrMfaTokenUsed
+	}
+
+	return nil
+}
+
+func (uc *token) revokeAccessToken(claims *jwt.Claims) error {
+	err := uc.revokedRepo.RevokeToken(entity.RevokedToken{
+		Jti:       claims.Id,
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0),
+	})
+	if err != nil {
+		return fmt.Errorf("failed when revoke access token with jti %s", claims.Id)
+	}
+
+	return nil
+}
+
+// IsAccessTokenActive report whether the valid access token is not revoked, its session
+// not ended, and its user not deleted, nor changed password or role since
+func (uc *token) IsAccessTokenActive(claims *jwt.Claims) (bool, error) {
+	active, _, err := uc.checkAccessToken(claims)
+	return active, err
+}
+
+// introspectAccessToken return nil when the token is not an active access token
+func (uc *token) introspectAccessToken(tokenString string) (*entity.Introspection, error) {
+	claims, err := jwt.IntrospectAccessToken(tokenString)
+	if err != nil {
+		return nil, nil
+	}
+
+	active, user, err := uc.checkAccessToken(claims)
+	if err != nil || !active {
+		return nil, err
+	}
+
+	// a service or client token has no user nor session
+	if user == nil {
+		return introspection(claims, entity.ACCESS_TOKEN_HINT, "", claims.SessionKsuid), nil
+	}
+
+	return introspection(claims, entity.ACCESS_TOKEN_HINT, user.Username, claims.SessionKsuid), nil
+}
+
+// checkAccessToken report whether the access token is active, with its user unless it is a service token
+func (uc *token) checkAccessToken(claims *jwt.Claims) (bool, *entity.User, error) {
+	revoked, err := uc.revokedRepo.IsTokenRevoked(claims.Id)
+	if err != nil {
+		return false, nil, fmt.Errorf("failed when check revoked access token with jti %s", claims.Id)
+	}
+	if revoked {
+		return false, nil, nil
+	}
+
+	if claims.Role == entity.SERVICE {
+		return true, nil, nil
+	}
+
+	user := uc.activeUser(claims)
+	if user == nil || !uc.isSessionActive(claims.SessionKsuid) {
+		return false, nil, nil
+	}
+
+	return true, user, nil
+}
+
+// introspectRefreshToken return nil when the token is not an active refresh token
+func (uc *token) introspectRefreshToken(tokenString string) (*entity.Introspection, error) {
+	claims, err := jwt.ValidateRefreshToken(tokenString)
+	if err != nil {
+		return nil, nil
+	}
+
+	storedToken, err := uc.repo.GetRefreshToken(claims.Id)
+	if err != nil || storedToken.RevokedAt != nil {
+		return nil, nil
+	}
+
+	user := uc.activeUser(claims)
+	if user == nil || !uc.isSessionActive(storedToken.FamilyKsuid) {
+		return nil, nil
+	}
+
+	return introspection(claims, entity.REFRESH_TOKEN_HINT, user.Username, storedToken.FamilyKsuid), nil
+}
+
+// activeUser return the user of the token, nil when the user has been deleted,
+// or has changed password or role after the token was issued
+func (uc *token) activeUser(claims *jwt.Claims) *entity.User {
+	user, err := uc.userRepo.GetUserByKsuid(claims.UserKsuid)
+	if err != nil || user.Role != claims.Role {
+		return nil
+	}
+
+	if user.PasswordChangedAt != nil && claims.IssuedAt < user.PasswordChangedAt.Unix() {
+		return nil
+	}
+
+	return user
+}
+
+// isSessionActive report whether the session has not been ended,
+// tokens issued before sessions existed have no session
+func (uc *token) isSessionActive(sessionKsuid string) bool {
+	if sessionKsuid == "" {
+		return true
+	}
+
+	session, err := uc.sessionRepo.GetSession(sessionKsuid)
+
+	return err == nil && session.RevokedAt == nil
+}
+
+func introspection(claims *jwt.Claims, tokenType, username, sessionKsuid string) *entity.Introspection {
+	return &entity.Introspection{
+		Active:      true,
+		TokenType:   tokenType,
+		Sub:         claims.Subject,
+		Username:    username,
+		Role:        claims.Role,
+		Permissions: claims.Permissions,
+		Sid:         sessionKsuid,
+		Iss:         claims.Issuer,
+		Aud:         claims.Audience,
+		Jti:         claims.Id,
+		Exp:         claims.ExpiresAt,
+		Iat:         claims.IssuedAt,
+		Nbf:         claims.NotBefore,
+	}
+}
+
+func (uc *token) createToken(user *entity.User, session *entity.Session) (*entity.Token, error) {
+	familyKsuid := session.Ksuid
+
+	// permissions are resolved on every refresh, so a change of the role applies within ACCESS_TOKEN_TTL
+	permissions, err := uc.roleRepo.GetRolePermissions(user.Role)
+	if err != nil {
+		return nil, fmt.Errorf("failed when get permissions of role %s", user.Role)
+	}
+
+	audience := jwt.LoginAudience(permissions)
+	if session.ClientID != "" {
+		permissions = grantedPermissions(permissions, strings.Fields(session.Scope))
+		audience = []string{jwt.USER_APP_AUDIENCE}
+	}
+
+	accessToken, err := jwt.CreateAccessToken(user.Ksuid, user.Role, familyKsuid, permissions, audience)
+	if err != nil {
+		return nil, fmt.Errorf("failed when create access token, err: %s", err.Error())
+	}
+
+	jti := ksuid.New().String()
+
+	refreshToken, err := jwt.CreateRefreshToken(user.Ksuid, user.Role, jti)
+	if err != nil {
+		return nil, fmt.Errorf("failed when create refresh token, err: %s", err.Error())
+	}
+
+	_, err = uc.repo.CreateRefreshToken(entity.RefreshToken{
+		Jti:         jti,
+		FamilyKsuid: familyKsuid,
+		UserKsuid:   user.Ksuid,
+		ExpiresAt:   time.Now().Add(jwt.REFRESH_TOKEN_TTL),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed when save refresh token")
+	}
+
+	return &entity.Token{
+		UserKsuid:    user.Ksuid,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		Scope:        session.Scope,
+	}, nil
+}
+
+// grantedPermissions keep the permissions of the role the client has been granted
+func grantedPermissions(permissions, scopes []string) []string {
+	granted := []string{}
+	for _, permission := range permissions {
+		if contains(scopes, permission) {
+			granted = append(granted, permission)
+		}
+	}
+
+	return granted
+}
+
+func (uc *token) revokeFamily(familyKsuid string) {
+	for maxRetry := 5; maxRetry > 0; maxRetry-- {
+		if err := uc.sessionRepo.RevokeSession(familyKsuid); err == nil {
+			break
+		}
+	}
+
+	for maxRetry := 5; maxRetry > 0; maxRetry-- {
+		if err := uc.repo.RevokeFamily(familyKsuid); err == nil {
+			return
+		}
+	}
+}
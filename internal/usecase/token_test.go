@@ -0,0 +1,607 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	repoMocks "github.com/adesupraptolaia/user_login/internal/repo/mocks"
+	"github.com/adesupraptolaia/user_login/pkg/jwt"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_token_CreateToken(t *testing.T) {
+	repo := repoMocks.NewRefreshTokensRepo(t)
+	sessionRepo := repoMocks.NewSessionsRepo(t)
+	roleRepo := repoMocks.NewRolesRepo(t)
+
+	data := &entity.User{Ksuid: "ksuid", Username: "user", Role: entity.USER}
+
+	sessionRepo.On("CreateSession", mock.AnythingOfType("entity.Session")).
+		Return(func(session entity.Session) *entity.Session {
+			return &session
+		}, nil).
+		Twice()
+
+	roleRepo.On("GetRolePermissions", entity.USER).
+		Return([]string{entity.PERM_PROFILE_READ_SELF}, nil).
+		Twice()
+
+	repo.On("CreateRefreshToken", mock.MatchedBy(func(refreshToken entity.RefreshToken) bool {
+		return refreshToken.UserKsuid == "ksuid" && refreshToken.FamilyKsuid != ""
+	})).
+		Return(&entity.RefreshToken{}, nil).
+		Once()
+
+	repo.On("CreateRefreshToken", mock.AnythingOfType("entity.RefreshToken")).
+		Return(nil, fmt.Errorf("error insert")).
+		Once()
+
+	type fields struct {
+		repo        *repoMocks.RefreshTokensRepo
+		sessionRepo *repoMocks.SessionsRepo
+		roleRepo    *repoMocks.RolesRepo
+	}
+	type args struct {
+		user *entity.User
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr bool
+	}{
+		{
+			name:    "Success Create Token",
+			fields:  fields{repo: repo, sessionRepo: sessionRepo, roleRepo: roleRepo},
+			args:    args{data},
+			wantErr: false,
+		},
+		{
+			name:    "Failed Create Token",
+			fields:  fields{repo: repo, sessionRepo: sessionRepo, roleRepo: roleRepo},
+			args:    args{data},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &token{
+				repo:        tt.fields.repo,
+				sessionRepo: tt.fields.sessionRepo,
+				roleRepo:    tt.fields.roleRepo,
+			}
+			got, err := uc.CreateToken(tt.args.user)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("token.CreateToken() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && (got.AccessToken == "" || got.RefreshToken == "") {
+				t.Errorf("token.CreateToken() = %v, want access and refresh token", got)
+			}
+		})
+	}
+}
+
+func Test_token_CreateClientSessionToken(t *testing.T) {
+	repo := repoMocks.NewRefreshTokensRepo(t)
+	sessionRepo := repoMocks.NewSessionsRepo(t)
+	roleRepo := repoMocks.NewRolesRepo(t)
+
+	data := &entity.User{Ksuid: "ksuid", Username: "admin", Role: entity.ADMIN}
+
+	sessionRepo.On("CreateSession", mock.MatchedBy(func(session entity.Session) bool {
+		return session.ClientID == "client" && session.Scope == "openid profile:read:self"
+	})).
+		Return(func(session entity.Session) *entity.Session {
+			return &session
+		}, nil).
+		Once()
+
+	roleRepo.On("GetRolePermissions", entity.ADMIN).
+		Return([]string{entity.PERM_PROFILE_READ_SELF, entity.PERM_USER_DELETE}, nil).
+		Once()
+
+	repo.On("CreateRefreshToken", mock.AnythingOfType("entity.RefreshToken")).
+		Return(&entity.RefreshToken{}, nil).
+		Once()
+
+	uc := &token{
+		repo:        repo,
+		sessionRepo: sessionRepo,
+		roleRepo:    roleRepo,
+	}
+
+	got, err := uc.CreateClientSessionToken(data, "client", "openid profile:read:self")
+	if err != nil {
+		t.Fatalf("token.CreateClientSessionToken() error = %v", err)
+	}
+
+	claims, err := jwt.IntrospectAccessToken(got.AccessToken)
+	if err != nil {
+		t.Fatalf("token.CreateClientSessionToken() access token error = %v", err)
+	}
+
+	if !reflect.DeepEqual(claims.Permissions, []string{entity.PERM_PROFILE_READ_SELF}) {
+		t.Errorf("token.CreateClientSessionToken() permissions = %v, want granted scope only", claims.Permissions)
+	}
+
+	if !reflect.DeepEqual(claims.Audience, []string{jwt.USER_APP_AUDIENCE}) {
+		t.Errorf("token.CreateClientSessionToken() audience = %v, want user app only", claims.Audience)
+	}
+}
+
+func Test_token_RefreshToken(t *testing.T) {
+	repo := repoMocks.NewRefreshTokensRepo(t)
+	sessionRepo := repoMocks.NewSessionsRepo(t)
+	userRepo := repoMocks.NewUsersRepo(t)
+	roleRepo := repoMocks.NewRolesRepo(t)
+
+	revokedAt := time.Now()
+	data := &entity.User{Ksuid: "ksuid", Username: "user", Role: entity.USER}
+
+	validToken, _ := jwt.CreateRefreshToken("ksuid", entity.USER, "validJti")
+	reusedToken, _ := jwt.CreateRefreshToken("ksuid", entity.USER, "reusedJti")
+	clientToken, _ := jwt.CreateRefreshToken("ksuid", entity.USER, "clientJti")
+
+	repo.On("GetRefreshToken", "validJti").
+		Return(&entity.RefreshToken{Jti: "validJti", FamilyKsuid: "family", UserKsuid: "ksuid"}, nil).
+		Once()
+
+	repo.On("GetRefreshToken", "reusedJti").
+		Return(&entity.RefreshToken{Jti: "reusedJti", FamilyKsuid: "family", UserKsuid: "ksuid", RevokedAt: &revokedAt}, nil).
+		Once()
+
+	repo.On("GetRefreshToken", "clientJti").
+		Return(&entity.RefreshToken{Jti: "clientJti", FamilyKsuid: "clientFamily", UserKsuid: "ksuid"}, nil).
+		Once()
+
+	repo.On("RevokeRefreshToken", "validJti").
+		Return(nil).
+		Once()
+
+	repo.On("RevokeFamily", "family").
+		Return(nil).
+		Once()
+
+	sessionRepo.On("GetSession", "family").
+		Return(&entity.Session{Ksuid: "family", UserKsuid: "ksuid"}, nil).
+		Once()
+
+	sessionRepo.On("GetSession", "clientFamily").
+		Return(&entity.Session{Ksuid: "clientFamily", UserKsuid: "ksuid", ClientID: "client"}, nil).
+		Once()
+
+	sessionRepo.On("RevokeSession", "family").
+		Return(nil).
+		Once()
+
+	repo.On("CreateRefreshToken", mock.MatchedBy(func(refreshToken entity.RefreshToken) bool {
+		return refreshToken.FamilyKsuid == "family" && refreshToken.Jti != "validJti"
+	})).
+		Return(&entity.RefreshToken{}, nil).
+		Once()
+
+	userRepo.On("GetUserByKsuid", "ksuid").
+		Return(data, nil).
+		Once()
+
+	roleRepo.On("GetRolePermissions", entity.USER).
+		Return([]string{entity.PERM_PROFILE_READ_SELF}, nil).
+		Once()
+
+	type fields struct {
+		repo        *repoMocks.RefreshTokensRepo
+		sessionRepo *repoMocks.SessionsRepo
+		userRepo    *repoMocks.UsersRepo
+		roleRepo    *repoMocks.RolesRepo
+	}
+	type args struct {
+		refreshToken string
+		clientID     string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr bool
+	}{
+		{
+			name:    "Success Rotate Refresh Token",
+			fields:  fields{repo: repo, sessionRepo: sessionRepo, userRepo: userRepo, roleRepo: roleRepo},
+			args:    args{validToken, ""},
+			wantErr: false,
+		},
+		{
+			name:    "Failed Reuse Rotated Refresh Token",
+			fields:  fields{repo: repo, sessionRepo: sessionRepo, userRepo: userRepo, roleRepo: roleRepo},
+			args:    args{reusedToken, ""},
+			wantErr: true,
+		},
+		{
+			name:    "Failed Refresh Token Of Another Client",
+			fields:  fields{repo: repo, sessionRepo: sessionRepo, userRepo: userRepo, roleRepo: roleRepo},
+			args:    args{clientToken, "other"},
+			wantErr: true,
+		},
+		{
+			name:    "Failed Invalid Refresh Token",
+			fields:  fields{repo: repo, sessionRepo: sessionRepo, userRepo: userRepo, roleRepo: roleRepo},
+			args:    args{"invalidToken", ""},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &token{
+				repo:        tt.fields.repo,
+				sessionRepo: tt.fields.sessionRepo,
+				userRepo:    tt.fields.userRepo,
+				roleRepo:    tt.fields.roleRepo,
+			}
+			got, err := uc.RefreshToken(tt.args.refreshToken, tt.args.clientID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("token.RefreshToken() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.RefreshToken == tt.args.refreshToken {
+				t.Errorf("token.RefreshToken() should rotate the refresh token")
+			}
+		})
+	}
+}
+
+func Test_token_Logout(t *testing.T) {
+	repo := repoMocks.NewRefreshTokensRepo(t)
+	sessionRepo := repoMocks.NewSessionsRepo(t)
+
+	validToken, _ := jwt.CreateRefreshToken("ksuid", entity.USER, "validJti")
+	unknownToken, _ := jwt.CreateRefreshToken("ksuid", entity.USER, "unknownJti")
+
+	repo.On("GetRefreshToken", "validJti").
+		Return(&entity.RefreshToken{Jti: "validJti", FamilyKsuid: "family", UserKsuid: "ksuid"}, nil).
+		Once()
+
+	repo.On("GetRefreshToken", "unknownJti").
+		Return(nil, fmt.Errorf("not found")).
+		Once()
+
+	repo.On("RevokeFamily", "family").
+		Return(nil).
+		Once()
+
+	sessionRepo.On("RevokeSession", "family").
+		Return(nil).
+		Once()
+
+	type fields struct {
+		repo        *repoMocks.RefreshTokensRepo
+		sessionRepo *repoMocks.SessionsRepo
+	}
+	type args struct {
+		refreshToken string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr bool
+	}{
+		{
+			name:    "Success Logout",
+			fields:  fields{repo: repo, sessionRepo: sessionRepo},
+			args:    args{validToken},
+			wantErr: false,
+		},
+		{
+			name:    "Failed Logout Unknown Refresh Token",
+			fields:  fields{repo: repo, sessionRepo: sessionRepo},
+			args:    args{unknownToken},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &token{
+				repo:        tt.fields.repo,
+				sessionRepo: tt.fields.sessionRepo,
+			}
+			if err := uc.Logout(tt.args.refreshToken); (err != nil) != tt.wantErr {
+				t.Errorf("token.Logout() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_token_Introspect(t *testing.T) {
+	repo := repoMocks.NewRefreshTokensRepo(t)
+	sessionRepo := repoMocks.NewSessionsRepo(t)
+	userRepo := repoMocks.NewUsersRepo(t)
+	revokedRepo := repoMocks.NewRevokedTokensRepo(t)
+
+	perms := []string{entity.PERM_PROFILE_READ_SELF}
+	aud := []string{jwt.USER_APP_AUDIENCE}
+	activeToken, _ := jwt.CreateAccessToken("ksuid", entity.USER, "session", perms, aud)
+	revokedToken, _ := jwt.CreateAccessToken("ksuid", entity.USER, "session", perms, aud)
+	endedToken, _ := jwt.CreateAccessToken("ksuid", entity.USER, "ended", perms, aud)
+	deletedUserToken, _ := jwt.CreateAccessToken("deleted", entity.USER, "session", perms, aud)
+	serviceToken, _ := jwt.CreateServiceToken("user-app", entity.SERVICE_PERMISSIONS)
+	refreshToken, _ := jwt.CreateRefreshToken("ksuid", entity.USER, "refreshJti")
+	mfaToken, _ := jwt.CreateMfaToken("ksuid", entity.USER)
+
+	revokedClaims, _ := jwt.IntrospectAccessToken(revokedToken)
+
+	revokedRepo.On("IsTokenRevoked", revokedClaims.Id).
+		Return(true, nil)
+
+	revokedRepo.On("IsTokenRevoked", mock.AnythingOfType("string")).
+		Return(false, nil)
+
+	userRepo.On("GetUserByKsuid", "ksuid").
+		Return(&entity.User{Ksuid: "ksuid", Username: "user", Role: entity.USER}, nil)
+
+	userRepo.On("GetUserByKsuid", "deleted").
+		Return(nil, fmt.Errorf("not found"))
+
+	sessionRepo.On("GetSession", "session").
+		Return(&entity.Session{Ksuid: "session"}, nil)
+
+	sessionRepo.On("GetSession", "ended").
+		Return(&entity.Session{Ksuid: "ended", RevokedAt: &time.Time{}}, nil)
+
+	sessionRepo.On("GetSession", "family").
+		Return(&entity.Session{Ksuid: "family"}, nil)
+
+	repo.On("GetRefreshToken", "refreshJti").
+		Return(&entity.RefreshToken{Jti: "refreshJti", FamilyKsuid: "family", UserKsuid: "ksuid"}, nil)
+
+	type args struct {
+		token string
+	}
+	tests := []struct {
+		name          string
+		args          args
+		wantActive    bool
+		wantTokenType string
+		wantSid       string
+	}{
+		{
+			name:          "Active Access Token",
+			args:          args{activeToken},
+			wantActive:    true,
+			wantTokenType: entity.ACCESS_TOKEN_HINT,
+			wantSid:       "session",
+		},
+		{
+			name:          "Active Service Token",
+			args:          args{serviceToken},
+			wantActive:    true,
+			wantTokenType: entity.ACCESS_TOKEN_HINT,
+		},
+		{
+			name:          "Active Refresh Token",
+			args:          args{refreshToken},
+			wantActive:    true,
+			wantTokenType: entity.REFRESH_TOKEN_HINT,
+			wantSid:       "family",
+		},
+		{
+			name: "Inactive Revoked Access Token",
+			args: args{revokedToken},
+		},
+		{
+			name: "Inactive Ended Session",
+			args: args{endedToken},
+		},
+		{
+			name: "Inactive Deleted User",
+			args: args{deletedUserToken},
+		},
+		{
+			name: "Inactive Mfa Token",
+			args: args{mfaToken},
+		},
+		{
+			name: "Inactive Malformed Token",
+			args: args{"token"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &token{
+				repo:        repo,
+				sessionRepo: sessionRepo,
+				userRepo:    userRepo,
+				revokedRepo: revokedRepo,
+			}
+			got, err := uc.Introspect(tt.args.token)
+			if err != nil {
+				t.Errorf("token.Introspect() error = %v", err)
+				return
+			}
+			if got.Active != tt.wantActive || got.TokenType != tt.wantTokenType || got.Sid != tt.wantSid {
+				t.Errorf("token.Introspect() = %+v, want active %v, token type %q, sid %q", got, tt.wantActive, tt.wantTokenType, tt.wantSid)
+			}
+		})
+	}
+}
+
+func Test_token_Revoke(t *testing.T) {
+	repo := repoMocks.NewRefreshTokensRepo(t)
+	sessionRepo := repoMocks.NewSessionsRepo(t)
+	revokedRepo := repoMocks.NewRevokedTokensRepo(t)
+
+	accessToken, _ := jwt.CreateAccessToken("ksuid", entity.USER, "session", nil, []string{jwt.USER_APP_AUDIENCE})
+	failedToken, _ := jwt.CreateAccessToken("ksuid", entity.USER, "session", nil, []string{jwt.USER_APP_AUDIENCE})
+	refreshToken, _ := jwt.CreateRefreshToken("ksuid", entity.USER, "refreshJti")
+	unknownToken, _ := jwt.CreateRefreshToken("ksuid", entity.USER, "unknownJti")
+
+	accessClaims, _ := jwt.IntrospectAccessToken(accessToken)
+	failedClaims, _ := jwt.IntrospectAccessToken(failedToken)
+
+	revokedRepo.On("RevokeToken", mock.MatchedBy(func(revokedToken entity.RevokedToken) bool {
+		return revokedToken.Jti == accessClaims.Id && revokedToken.ExpiresAt.Unix() == accessClaims.ExpiresAt
+	})).
+		Return(nil).
+		Once()
+
+	revokedRepo.On("RevokeToken", mock.MatchedBy(func(revokedToken entity.RevokedToken) bool {
+		return revokedToken.Jti == failedClaims.Id
+	})).
+		Return(fmt.Errorf("error insert")).
+		Once()
+
+	repo.On("GetRefreshToken", "refreshJti").
+		Return(&entity.RefreshToken{Jti: "refreshJti", FamilyKsuid: "family", UserKsuid: "ksuid"}, nil).
+		Once()
+
+	repo.On("GetRefreshToken", "unknownJti").
+		Return(nil, fmt.Errorf("not found")).
+		Once()
+
+	repo.On("RevokeFamily", "family").
+		Return(nil).
+		Once()
+
+	sessionRepo.On("RevokeSession", "family").
+		Return(nil).
+		Once()
+
+	type args struct {
+		token string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name:    "Success Revoke Access Token",
+			args:    args{accessToken},
+			wantErr: false,
+		},
+		{
+			name:    "Success Revoke Refresh Token",
+			args:    args{refreshToken},
+			wantErr: false,
+		},
+		{
+			name:    "Success Revoke Unknown Refresh Token",
+			args:    args{unknownToken},
+			wantErr: false,
+		},
+		{
+			name:    "Success Revoke Malformed Token",
+			args:    args{"token"},
+			wantErr: false,
+		},
+		{
+			name:    "Failed Revoke Access Token",
+			args:    args{failedToken},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &token{
+				repo:        repo,
+				sessionRepo: sessionRepo,
+				revokedRepo: revokedRepo,
+			}
+			if err := uc.Revoke(tt.args.token); (err != nil) != tt.wantErr {
+				t.Errorf("token.Revoke() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_token_ValidateMfaToken(t *testing.T) {
+	revokedRepo := repoMocks.NewRevokedTokensRepo(t)
+
+	mfaToken, _ := jwt.CreateMfaToken("ksuid", entity.USER)
+	usedToken, _ := jwt.CreateMfaToken("ksuid", entity.USER)
+	refreshToken, _ := jwt.CreateRefreshToken("ksuid", entity.USER, "jti")
+
+	mfaClaims, _ := jwt.ValidateMfaToken(mfaToken)
+	usedClaims, _ := jwt.ValidateMfaToken(usedToken)
+
+	revokedRepo.On("IsTokenRevoked", mfaClaims.Id).
+		Return(false, nil).
+		Once()
+
+	revokedRepo.On("IsTokenRevoked", usedClaims.Id).
+		Return(true, nil).
+		Once()
+
+	tests := []struct {
+		name        string
+		tokenString string
+		wantErr     bool
+	}{
+		{
+			name:        "Success MFA Token",
+			tokenString: mfaToken,
+			wantErr:     false,
+		},
+		{
+			name:        "Failed Used MFA Token",
+			tokenString: usedToken,
+			wantErr:     true,
+		},
+		{
+			name:        "Failed Refresh Token",
+			tokenString: refreshToken,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &token{
+				revokedRepo: revokedRepo,
+			}
+			got, err := uc.ValidateMfaToken(tt.tokenString)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("token.ValidateMfaToken() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.Id != mfaClaims.Id {
+				t.Errorf("token.ValidateMfaToken() jti = %v, want %v", got.Id, mfaClaims.Id)
+			}
+		})
+	}
+}
+
+func Test_token_UseMfaToken(t *testing.T) {
+	revokedRepo := repoMocks.NewRevokedTokensRepo(t)
+
+	mfaToken, _ := jwt.CreateMfaToken("ksuid", entity.USER)
+	claims, _ := jwt.ValidateMfaToken(mfaToken)
+
+	// kept until the token expires, a second use finds it
+	revokedRepo.On("UseToken", mock.MatchedBy(func(usedToken entity.RevokedToken) bool {
+		return usedToken.Jti == claims.Id && usedToken.ExpiresAt.Unix() == claims.ExpiresAt
+	})).
+		Return(true, nil).
+		Once()
+
+	revokedRepo.On("UseToken", mock.MatchedBy(func(usedToken entity.RevokedToken) bool {
+		return usedToken.Jti == claims.Id
+	})).
+		Return(false, nil).
+		Once()
+
+	uc := &token{
+		revokedRepo: revokedRepo,
+	}
+
+	if err := uc.UseMfaToken(claims); err != nil {
+		t.Errorf("token.UseMfaToken() error = %v, want nil", err)
+	}
+
+	if err := uc.UseMfaToken(claims); !errors.Is(err, ErrMfaTokenUsed) {
+		t.Errorf("token.UseMfaToken() second use error = %v, want %v", err, ErrMfaTokenUsed)
+	}
+}
@@ -1,35 +1,99 @@
 package usecase
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/adesupraptolaia/user_login/internal/entity"
 	"github.com/adesupraptolaia/user_login/internal/repo"
-	"github.com/adesupraptolaia/user_login/internal/utils"
+	"github.com/adesupraptolaia/user_login/pkg/hasher"
 	"github.com/segmentio/ksuid"
+	"gorm.io/gorm"
 )
 
 type UserUC interface {
+	Login(username, password string) (*entity.User, error)
 	GetUserByUsername(string) (*entity.User, error)
 	GetUserByKsuid(string) (*entity.User, error)
+	GetUsersByKsuids(ksuids []string, includeDeleted bool) ([]entity.User, error)
 	CreateUser(entity.UserRequest) (*entity.User, error)
+	InviteUser(entity.InviteUserRequest) (*entity.User, error)
+	AcceptInvitation(ksuid, password string) (*entity.User, error)
 	UpdateUser(string, entity.User) (*entity.User, error)
+	ChangePassword(string, entity.ChangePasswordRequest) (*entity.User, error)
+	ValidateNewPassword(ksuid, password string) error
+	SetPassword(ksuid, password string) (*entity.User, error)
 	DeleteUser(string) (*entity.User, error)
+	RestoreUser(ksuid string) (*entity.User, error)
+	PurgeUser(ksuid string) error
+	PurgeDeletedUsers() (int, error)
 }
 
+var (
+	ErrWrongPassword           = errors.New("wrong password")
+	ErrWrongUsernameOrPassword = errors.New("wrong username or password")
+	ErrUserAlreadyExists       = errors.New("already exist")
+	ErrUserNotInvited          = errors.New("user has no pending invitation")
+	ErrUserNotDeleted          = errors.New("user is not deleted")
+	ErrUserNotFound            = errors.New("not found")
+	ErrUserNotDeletable        = errors.New("can not be deleted")
+)
+
 type user struct {
-	repo repo.UsersRepo
+	repo         repo.UsersRepo
+	historyRepo  repo.PasswordHistoriesRepo
+	breachedRepo repo.BreachedPasswordsRepo
+	policy       PasswordPolicy
+	hasher       hasher.Hasher
+	tokenUC      TokenUC
+	// a deleted user can be restored during the grace period, and purged after
+	gracePeriod time.Duration
 }
 
-func NewUser(repo repo.UsersRepo) UserUC {
+func NewUser(repo repo.UsersRepo, passwordHistoryRepo repo.PasswordHistoriesRepo, breachedPasswordRepo repo.BreachedPasswordsRepo, policy PasswordPolicy, passwordHasher hasher.Hasher, tokenUC TokenUC, gracePeriod time.Duration) UserUC {
 	return &user{
-		repo: repo,
+		repo:         repo,
+		historyRepo:  passwordHistoryRepo,
+		breachedRepo: breachedPasswordRepo,
+		policy:       policy,
+		hasher:       passwordHasher,
+		tokenUC:      tokenUC,
+		gracePeriod:  gracePeriod,
 	}
 }
 
-func (uc *user) GetUserByUsername(username string) (*entity.User, error) {
+// Login check the password of the user. A password hash made by an outdated
+// algorithm or parameters is replaced while the password is at hand.
+func (uc *user) Login(username, password string) (*entity.User, error) {
 	user, err := uc.repo.GetUserByUsername(username)
 	if err != nil {
+		return nil, ErrWrongUsernameOrPassword
+	}
+
+	// an invited user has no password until the invitation is accepted
+	if user.IsInvitationPending() || user.IsDeleted() {
+		return nil, ErrWrongUsernameOrPassword
+	}
+
+	if match, _ := uc.hasher.Verify(user.Password, password); !match {
+		return nil, ErrWrongUsernameOrPassword
+	}
+
+	if uc.hasher.NeedsRehash(user.Password) {
+		// the login does not fail on it, the rehash is tried again on the next login
+		if hash, err := uc.hasher.Hash(password); err == nil && uc.repo.RehashPassword(user.Ksuid, user.Password, hash) == nil {
+			user.Password = hash
+		}
+	}
+
+	return user, nil
+}
+
+func (uc *user) GetUserByUsername(username string) (*entity.User, error) {
+	user, err := uc.repo.GetUserByUsername(username)
+	if err != nil || user.IsDeleted() {
 		return nil, fmt.Errorf("user with username %s not found", username)
 	}
 
@@ -38,24 +102,49 @@ func (uc *user) GetUserByUsername(username string) (*entity.User, error) {
 
 func (uc *user) GetUserByKsuid(ksuid string) (*entity.User, error) {
 	user, err := uc.repo.GetUserByKsuid(ksuid)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("user with ksuid %s %w", ksuid, ErrUserNotFound)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("user with ksuid %s not found", ksuid)
+		return nil, fmt.Errorf("failed when get user with ksuid %s", ksuid)
 	}
 
 	return user, nil
 }
 
+// GetUsersByKsuids get the users found among the ksuids, in no particular order
+func (uc *user) GetUsersByKsuids(ksuids []string, includeDeleted bool) ([]entity.User, error) {
+	users, err := uc.repo.GetUsersByKsuids(ksuids, includeDeleted)
+	if err != nil {
+		return nil, fmt.Errorf("failed when get users by ksuids")
+	}
+
+	return users, nil
+}
+
 func (uc *user) CreateUser(userReq entity.UserRequest) (*entity.User, error) {
-	user, _ := uc.repo.GetUserByUsername(userReq.Username)
-	if user != nil {
-		return nil, fmt.Errorf("user with username %s already exist", user.Username)
+	email, err := uc.checkNewUser(userReq.Username, userReq.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.checkPassword(userReq.Username, userReq.Password, nil); err != nil {
+		return nil, err
+	}
+
+	hash, err := uc.hasher.Hash(userReq.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed when hash password")
 	}
 
 	data := entity.User{
 		Ksuid:    ksuid.New().String(),
 		Username: userReq.Username,
-		Password: utils.HashPassword(userReq.Password),
+		Password: hash,
 		Role:     entity.USER,
+		Email:    email,
+
+		EmailVerificationRequired: userReq.EmailVerificationRequired,
 	}
 
 	user, err := uc.repo.CreateUser(data)
@@ -66,6 +155,80 @@ func (uc *user) CreateUser(userReq entity.UserRequest) (*entity.User, error) {
 	return user, nil
 }
 
+// InviteUser create a user without password, who can't login until the invitation is accepted
+func (uc *user) InviteUser(req entity.InviteUserRequest) (*entity.User, error) {
+	email, err := uc.checkNewUser(req.Username, req.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	// DATETIME keeps whole seconds only
+	invitedAt := time.Now().Truncate(time.Second)
+	data := entity.User{
+		Ksuid:     ksuid.New().String(),
+		Username:  req.Username,
+		Role:      entity.USER,
+		Email:     email,
+		InvitedAt: &invitedAt,
+	}
+
+	user, err := uc.repo.CreateUser(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed when create invited user")
+	}
+
+	return user, nil
+}
+
+// AcceptInvitation set the first password of an invited user, who can login from then on
+func (uc *user) AcceptInvitation(ksuid, password string) (*entity.User, error) {
+	// the invitation may be revoked meanwhile, along with the user
+	user, err := uc.repo.GetUserByKsuid(ksuid)
+	if err != nil || !user.IsInvitationPending() {
+		return nil, ErrUserNotInvited
+	}
+
+	if err := uc.checkPassword(user.Username, password, nil); err != nil {
+		return nil, err
+	}
+
+	hash, err := uc.hasher.Hash(password)
+	if err != nil {
+		return nil, fmt.Errorf("failed when hash password")
+	}
+
+	err = uc.repo.AcceptInvitation(user.Ksuid, hash)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotInvited
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed when accept invitation of user with ksuid %s", user.Ksuid)
+	}
+
+	user.Password = hash
+	user.InvitedAt = nil
+
+	return user, nil
+}
+
+// checkNewUser make sure the username and the email address are not taken yet, and return the normalized email address
+func (uc *user) checkNewUser(username, email string) (*string, error) {
+	if user, _ := uc.repo.GetUserByUsername(username); user != nil {
+		return nil, fmt.Errorf("user with username %s %w", user.Username, ErrUserAlreadyExists)
+	}
+
+	if email == "" {
+		return nil, nil
+	}
+
+	normalized := strings.ToLower(strings.TrimSpace(email))
+	if user, _ := uc.repo.GetUserByEmail(normalized); user != nil {
+		return nil, fmt.Errorf("user with email %s %w", normalized, ErrUserAlreadyExists)
+	}
+
+	return &normalized, nil
+}
+
 func (uc *user) UpdateUser(ksuid string, user entity.User) (*entity.User, error) {
 	if _, err := uc.repo.GetUserByKsuid(ksuid); err != nil {
 		return nil, fmt.Errorf("user with ksuid %s not exist", ksuid)
@@ -81,16 +244,208 @@ func (uc *user) UpdateUser(ksuid string, user entity.User) (*entity.User, error)
 	return userResp, nil
 }
 
-func (uc *user) DeleteUser(ksuid string) (*entity.User, error) {
+func (uc *user) ChangePassword(ksuid string, req entity.ChangePasswordRequest) (*entity.User, error) {
+	user, err := uc.repo.GetUserByKsuid(ksuid)
+	if err != nil {
+		return nil, fmt.Errorf("user with ksuid %s not exist", ksuid)
+	}
+
+	if match, _ := uc.hasher.Verify(user.Password, req.OldPassword); !match {
+		return nil, ErrWrongPassword
+	}
+
+	return uc.setPassword(user, req.NewPassword)
+}
+
+// ValidateNewPassword check the password against the policy and the password history of the user
+func (uc *user) ValidateNewPassword(ksuid, password string) error {
+	user, err := uc.repo.GetUserByKsuid(ksuid)
+	if err != nil {
+		return fmt.Errorf("user with ksuid %s not exist", ksuid)
+	}
+
+	previousHashes, err := uc.previousPasswordHashes(user)
+	if err != nil {
+		return err
+	}
+
+	return uc.checkPassword(user.Username, password, previousHashes)
+}
+
+// SetPassword replace the password without knowing the current one, the caller has proven the identity otherwise
+func (uc *user) SetPassword(ksuid, password string) (*entity.User, error) {
 	user, err := uc.repo.GetUserByKsuid(ksuid)
 	if err != nil {
 		return nil, fmt.Errorf("user with ksuid %s not exist", ksuid)
 	}
 
+	return uc.setPassword(user, password)
+}
+
+func (uc *user) setPassword(user *entity.User, password string) (*entity.User, error) {
+	previousHashes, err := uc.previousPasswordHashes(user)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.checkPassword(user.Username, password, previousHashes); err != nil {
+		return nil, err
+	}
+
+	hash, err := uc.hasher.Hash(password)
+	if err != nil {
+		return nil, fmt.Errorf("failed when hash password")
+	}
+
+	if err := uc.recordPasswordHistory(user); err != nil {
+		return nil, err
+	}
+
+	// refresh tokens issued before this time are no longer valid, the column has no fraction
+	// of second and rounding it up would reject the tokens issued in the same second too
+	changedAt := time.Now().Truncate(time.Second)
+	user.Password = hash
+	user.PasswordChangedAt = &changedAt
+
+	userResp, err := uc.repo.UpdateUser(user.Ksuid, *user)
+	if err != nil {
+		return nil, fmt.Errorf("failed when change password of user with ksuid %s", user.Ksuid)
+	}
+
+	return userResp, nil
+}
+
+func (uc *user) DeleteUser(ksuid string) (*entity.User, error) {
+	user, err := uc.GetUserByKsuid(ksuid)
+	if err != nil {
+		return nil, err
+	}
+
+	// the repo never delete an admin, tell it apart from an unknown user
+	if user.Role == entity.ADMIN {
+		return nil, fmt.Errorf("user with ksuid %s %w", ksuid, ErrUserNotDeletable)
+	}
+
 	_, err = uc.repo.DeleteUser(ksuid)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// deleted meanwhile
+		return nil, fmt.Errorf("user with ksuid %s %w", ksuid, ErrUserNotFound)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed when delete user_profiles with ksuid %s", ksuid)
 	}
 
+	// introspection already reports the tokens of a deleted user inactive, end the sessions too
+	if err := uc.tokenUC.RevokeUserTokens(ksuid); err != nil {
+		return nil, err
+	}
+
 	return user, nil
 }
+
+// RestoreUser undo the deletion of the user, within the grace period after the deletion
+func (uc *user) RestoreUser(ksuid string) (*entity.User, error) {
+	err := uc.repo.RestoreUser(ksuid, time.Now().Add(-uc.gracePeriod))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotDeleted
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed when restore user with ksuid %s", ksuid)
+	}
+
+	return uc.GetUserByKsuid(ksuid)
+}
+
+// PurgeUser permanently delete a user deleted for longer than the grace period
+func (uc *user) PurgeUser(ksuid string) error {
+	err := uc.repo.PurgeUser(ksuid, time.Now().Add(-uc.gracePeriod))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrUserNotDeleted
+	}
+	if err != nil {
+		return fmt.Errorf("failed when purge user with ksuid %s", ksuid)
+	}
+
+	return nil
+}
+
+// PurgeDeletedUsers permanently delete up to USER_PURGE_BATCH users deleted for longer than the grace period.
+// user-app purges the users it deleted with their profile, this also purges the users left deleted
+// without a profile, e.g. when user-app failed to create the profile of a new user.
+// A user failing is purged on a later run, the returned error counts them
+func (uc *user) PurgeDeletedUsers() (int, error) {
+	deletedBefore := time.Now().Add(-uc.gracePeriod)
+
+	deletedUsers, err := uc.repo.GetDeletedUsers(deletedBefore, USER_PURGE_BATCH)
+	if err != nil {
+		return 0, fmt.Errorf("failed when get deleted users")
+	}
+
+	purged, failed := 0, 0
+	for _, deleted := range deletedUsers {
+		err := uc.repo.PurgeUser(deleted.Ksuid, deletedBefore)
+		// purged meanwhile by user-app
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			failed++
+			continue
+		}
+
+		purged++
+	}
+
+	if failed > 0 {
+		return purged, fmt.Errorf("failed when purge %d deleted users", failed)
+	}
+
+	return purged, nil
+}
+
+// previousPasswordHashes return the current password hash and the hashes in
+// the password history, as many as the policy forbids to reuse
+func (uc *user) previousPasswordHashes(user *entity.User) ([]string, error) {
+	if uc.policy.HistorySize <= 0 {
+		return nil, nil
+	}
+
+	hashes := []string{user.Password}
+	if uc.policy.HistorySize == 1 {
+		return hashes, nil
+	}
+
+	history, err := uc.historyRepo.GetPasswordHistory(user.Ksuid, uc.policy.HistorySize-1)
+	if err != nil {
+		return nil, fmt.Errorf("failed when get password history of user with ksuid %s", user.Ksuid)
+	}
+
+	for _, previous := range history {
+		hashes = append(hashes, previous.PasswordHash)
+	}
+
+	return hashes, nil
+}
+
+// recordPasswordHistory keep the current password hash before it is changed,
+// and drop the ones the policy no longer needs
+func (uc *user) recordPasswordHistory(user *entity.User) error {
+	if uc.policy.HistorySize <= 1 {
+		return nil
+	}
+
+	err := uc.historyRepo.CreatePasswordHistory(entity.PasswordHistory{
+		Ksuid:        ksuid.New().String(),
+		UserKsuid:    user.Ksuid,
+		PasswordHash: user.Password,
+	})
+	if err != nil {
+		return fmt.Errorf("failed when create password history of user with ksuid %s", user.Ksuid)
+	}
+
+	if err := uc.historyRepo.PrunePasswordHistory(user.Ksuid, uc.policy.HistorySize-1); err != nil {
+		return fmt.Errorf("failed when prune password history of user with ksuid %s", user.Ksuid)
+	}
+
+	return nil
+}
@@ -0,0 +1,179 @@
+package usecase
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+)
+
+const (
+	USER_LIST_DEFAULT_LIMIT = 20
+	USER_LIST_SORT          = "user_ksuid"
+	// USER_LIST_MAX_READS is the most reads of user_profiles for one page filtered by role
+	USER_LIST_MAX_READS = 10
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// userListCursor is the keyset of the last user of a page, only valid with the sort it was made for
+type userListCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	Ksuid string `json:"k"`
+}
+
+// GetUserProfiles list the profiles with the username and role from auth service. The role is
+// only known to auth service, so the profiles are read page by page until enough users have the role,
+// up to USER_LIST_MAX_READS reads. A page may then be short, its cursor goes on after the last profile read
+func (uc *userProfile) GetUserProfiles(req entity.UserListRequest) (*entity.UserList, error) {
+	sort := req.Sort
+	if sort == "" {
+		sort = USER_LIST_SORT
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = USER_LIST_DEFAULT_LIMIT
+	}
+
+	// one more than the page, to know whether there is a next one
+	filter := entity.UserProfileFilter{
+		Name:            req.Name,
+		Address:         req.Address,
+		DateOfBirthFrom: req.DateOfBirthFrom,
+		DateOfBirthTo:   req.DateOfBirthTo,
+		SortBy:          strings.TrimPrefix(sort, "-"),
+		Desc:            strings.HasPrefix(sort, "-"),
+		Limit:           limit + 1,
+	}
+
+	if req.Cursor != "" {
+		cursor, err := decodeUserListCursor(req.Cursor)
+		if err != nil || cursor.Sort != sort {
+			return nil, ErrInvalidCursor
+		}
+		filter.AfterValue = cursor.Value
+		filter.AfterKsuid = cursor.Ksuid
+	}
+
+	items := []entity.UserListItem{}
+	for reads := 1; len(items) <= limit; reads++ {
+		profiles, err := uc.repo.GetUserProfiles(filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed when get user_profiles")
+		}
+
+		if len(profiles) == 0 {
+			break
+		}
+
+		users, err := uc.lookupUsers(profiles)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, profile := range profiles {
+			// a profile without user in auth service has no role
+			user := users[profile.UserKsuid]
+			if req.Role != "" && user.Role != req.Role {
+				continue
+			}
+
+			profile.DateOfBirth = convertDatetime(profile.DateOfBirth)
+			items = append(items, entity.UserListItem{
+				UserProfile: profile,
+				Username:    user.Username,
+				Role:        user.Role,
+				Email:       user.Email,
+			})
+
+			if len(items) > limit {
+				break
+			}
+		}
+
+		if len(profiles) < filter.Limit {
+			break
+		}
+
+		last := profiles[len(profiles)-1]
+		filter.AfterValue = userListSortValue(last, filter.SortBy)
+		filter.AfterKsuid = last.UserKsuid
+
+		// few users have the role, the client reads on with the cursor instead of one request reading every profile
+		if reads == USER_LIST_MAX_READS && len(items) <= limit {
+			return &entity.UserList{Users: items, NextCursor: encodeUserListCursor(sort, last)}, nil
+		}
+	}
+
+	list := &entity.UserList{Users: items}
+	if len(items) > limit {
+		list.Users = items[:limit]
+		list.NextCursor = encodeUserListCursor(sort, items[limit-1].UserProfile)
+	}
+
+	return list, nil
+}
+
+// lookupUsers get the users of the profiles from auth service by ksuid
+func (uc *userProfile) lookupUsers(profiles []entity.UserProfile) (map[string]entity.User, error) {
+	ksuids := []string{}
+	for _, profile := range profiles {
+		ksuids = append(ksuids, profile.UserKsuid)
+	}
+
+	users, err := uc.auth.GetUsers(ksuids)
+	if err != nil {
+		return nil, fmt.Errorf("error when get users from auth service %w", err)
+	}
+
+	result := map[string]entity.User{}
+	for _, user := range users {
+		result[user.Ksuid] = user
+	}
+
+	return result, nil
+}
+
+func userListSortValue(profile entity.UserProfile, sortBy string) string {
+	switch sortBy {
+	case "name":
+		return profile.Name
+	case "date_of_birth":
+		return convertDatetime(profile.DateOfBirth)
+	default:
+		return profile.UserKsuid
+	}
+}
+
+func encodeUserListCursor(sort string, profile entity.UserProfile) string {
+	data, _ := json.Marshal(userListCursor{
+		Sort:  sort,
+		Value: userListSortValue(profile, strings.TrimPrefix(sort, "-")),
+		Ksuid: profile.UserKsuid,
+	})
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeUserListCursor(cursor string) (*userListCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &userListCursor{}
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, err
+	}
+
+	if result.Ksuid == "" {
+		return nil, ErrInvalidCursor
+	}
+
+	return result, nil
+}
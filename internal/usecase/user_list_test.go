@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	repoMocks "github.com/adesupraptolaia/user_login/internal/repo/mocks"
+)
+
+func Test_userProfile_GetUserProfiles(t *testing.T) {
+	profileRepo := repoMocks.NewUserProfilesRepo(t)
+	authRepo := repoMocks.NewAuthRepo(t)
+
+	alice := entity.UserProfile{UserKsuid: "k1", Name: "Alice", DateOfBirth: "2000-01-01T00:00:00Z", Address: "Malang"}
+	bob := entity.UserProfile{UserKsuid: "k2", Name: "Bob", DateOfBirth: "2001-01-01", Address: "Malang"}
+	carol := entity.UserProfile{UserKsuid: "k3", Name: "Carol", DateOfBirth: "2002-01-01", Address: "Malang"}
+	dave := entity.UserProfile{UserKsuid: "k4", Name: "Dave", DateOfBirth: "2003-01-01", Address: "Malang"}
+
+	users := map[string]entity.User{
+		"k1": {Ksuid: "k1", Username: "alice", Role: entity.ADMIN},
+		"k2": {Ksuid: "k2", Username: "bob", Role: entity.USER},
+		"k3": {Ksuid: "k3", Username: "carol", Role: entity.USER},
+		"k4": {Ksuid: "k4", Username: "dave", Role: entity.ADMIN},
+	}
+	item := func(profile entity.UserProfile) entity.UserListItem {
+		user := users[profile.UserKsuid]
+		profile.DateOfBirth = convertDatetime(profile.DateOfBirth)
+		return entity.UserListItem{UserProfile: profile, Username: user.Username, Role: user.Role}
+	}
+
+	// first page of 2 by name
+	profileRepo.On("GetUserProfiles", entity.UserProfileFilter{Address: "Malang", SortBy: "name", Limit: 3}).
+		Return([]entity.UserProfile{alice, bob, carol}, nil).
+		Once()
+	authRepo.On("GetUsers", []string{"k1", "k2", "k3"}).
+		Return([]entity.User{users["k3"], users["k1"], users["k2"]}, nil).
+		Once()
+
+	// admins, found across two reads of user_profiles
+	profileRepo.On("GetUserProfiles", entity.UserProfileFilter{SortBy: "user_ksuid", Desc: true, Limit: 2}).
+		Return([]entity.UserProfile{dave, carol}, nil).
+		Once()
+	authRepo.On("GetUsers", []string{"k4", "k3"}).
+		Return([]entity.User{users["k4"], users["k3"]}, nil).
+		Once()
+	profileRepo.On("GetUserProfiles", entity.UserProfileFilter{SortBy: "user_ksuid", Desc: true, AfterValue: "k3", AfterKsuid: "k3", Limit: 2}).
+		Return([]entity.UserProfile{bob, alice}, nil).
+		Once()
+	authRepo.On("GetUsers", []string{"k2", "k1"}).
+		Return([]entity.User{users["k2"], users["k1"]}, nil).
+		Once()
+
+	// last page
+	profileRepo.On("GetUserProfiles", entity.UserProfileFilter{SortBy: "name", AfterValue: "Bob", AfterKsuid: "k2", Limit: 3}).
+		Return([]entity.UserProfile{carol}, nil).
+		Once()
+	authRepo.On("GetUsers", []string{"k3"}).
+		Return([]entity.User{users["k3"]}, nil).
+		Once()
+
+	// no service user among the profiles, the reads stop at USER_LIST_MAX_READS
+	var lastRead entity.UserProfile
+	afterKsuid := ""
+	for read := 0; read < USER_LIST_MAX_READS; read++ {
+		first := entity.UserProfile{UserKsuid: fmt.Sprintf("r%02d", 2*read), DateOfBirth: "2000-01-01"}
+		second := entity.UserProfile{UserKsuid: fmt.Sprintf("r%02d", 2*read+1), DateOfBirth: "2000-01-01"}
+		profileRepo.On("GetUserProfiles", entity.UserProfileFilter{SortBy: "user_ksuid", AfterValue: afterKsuid, AfterKsuid: afterKsuid, Limit: 2}).
+			Return([]entity.UserProfile{first, second}, nil).
+			Once()
+		authRepo.On("GetUsers", []string{first.UserKsuid, second.UserKsuid}).
+			Return([]entity.User{{Ksuid: first.UserKsuid, Role: entity.USER}, {Ksuid: second.UserKsuid, Role: entity.USER}}, nil).
+			Once()
+		lastRead, afterKsuid = second, second.UserKsuid
+	}
+
+	tests := []struct {
+		name    string
+		req     entity.UserListRequest
+		want    *entity.UserList
+		wantErr error
+	}{
+		{
+			name: "Success Get Users, first page",
+			req:  entity.UserListRequest{Address: "Malang", Sort: "name", Limit: 2},
+			want: &entity.UserList{
+				Users:      []entity.UserListItem{item(alice), item(bob)},
+				NextCursor: encodeUserListCursor("name", bob),
+			},
+		},
+		{
+			name: "Success Get Users, last page",
+			req:  entity.UserListRequest{Sort: "name", Limit: 2, Cursor: encodeUserListCursor("name", bob)},
+			want: &entity.UserList{Users: []entity.UserListItem{item(carol)}},
+		},
+		{
+			name: "Success Get Users, by role",
+			req:  entity.UserListRequest{Role: entity.ADMIN, Sort: "-user_ksuid", Limit: 1},
+			want: &entity.UserList{
+				Users:      []entity.UserListItem{item(dave)},
+				NextCursor: encodeUserListCursor("-user_ksuid", dave),
+			},
+		},
+		{
+			name: "Success Get Users, by role without enough users in the reads",
+			req:  entity.UserListRequest{Role: entity.SERVICE, Limit: 1},
+			want: &entity.UserList{
+				Users:      []entity.UserListItem{},
+				NextCursor: encodeUserListCursor("user_ksuid", lastRead),
+			},
+		},
+		{
+			name:    "Failed Get Users, cursor of another sort",
+			req:     entity.UserListRequest{Sort: "-name", Cursor: encodeUserListCursor("name", bob)},
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:    "Failed Get Users, malformed cursor",
+			req:     entity.UserListRequest{Cursor: "not a cursor"},
+			wantErr: ErrInvalidCursor,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &userProfile{
+				repo: profileRepo,
+				auth: authRepo,
+			}
+			got, err := uc.GetUserProfiles(tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("userProfile.GetUserProfiles() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("userProfile.GetUserProfiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,29 +1,51 @@
 package usecase
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/adesupraptolaia/user_login/internal/entity"
 	"github.com/adesupraptolaia/user_login/internal/repo"
+	"gorm.io/gorm"
 )
 
+// ErrVersionMismatch is returned when the profile was updated since the version the update is based on
+var ErrVersionMismatch = errors.New("user_profiles was updated meanwhile, get it again")
+
 type UserProfileUC interface {
 	GetUserProfile(string) (*entity.UserProfile, error)
+	GetUserProfiles(entity.UserListRequest) (*entity.UserList, error)
 	CreateUserProfile(entity.CreateUserRequest) (*entity.UserProfile, error)
+	InviteUserProfile(entity.InviteUserProfileRequest) (*entity.Invitation, error)
+	GetInvitations() ([]entity.Invitation, error)
+	RevokeInvitation(string) (*entity.UserProfile, error)
+	// the updates are refused with ErrVersionMismatch when the profile isn't at the given version anymore
 	UpdateUserProfile(string, entity.UserProfile) (*entity.UserProfile, error)
+	PatchUserProfile(userKsuid string, version int, contentType string, patch []byte) (*entity.UserProfile, error)
+	UpdateOwnUserProfile(userKsuid, role string, userProfile entity.UserProfile) (*entity.UserProfile, error)
+	PatchOwnUserProfile(userKsuid string, version int, role, contentType string, patch []byte) (*entity.UserProfile, error)
 	DeleteUserProfile(string) (*entity.UserProfile, error)
+	RestoreUserProfile(string) (*entity.UserProfile, error)
+	PurgeDeletedUserProfiles() (int, error)
 }
 
 type userProfile struct {
 	repo repo.UserProfilesRepo
 	auth repo.AuthRepo
+	// fields users can change on their own profile, by role
+	selfEditableFields map[string][]string
+	// deleted users can be restored during it, then they are purged
+	gracePeriod time.Duration
 }
 
-func NewUserProfile(userProfileRepo repo.UserProfilesRepo, authRepo repo.AuthRepo) UserProfileUC {
+func NewUserProfile(userProfileRepo repo.UserProfilesRepo, authRepo repo.AuthRepo, selfEditableFields map[string][]string, gracePeriod time.Duration) UserProfileUC {
 	return &userProfile{
-		repo: userProfileRepo,
-		auth: authRepo,
+		repo:               userProfileRepo,
+		auth:               authRepo,
+		selfEditableFields: selfEditableFields,
+		gracePeriod:        gracePeriod,
 	}
 }
 
@@ -41,11 +63,16 @@ func (uc *userProfile) CreateUserProfile(userProfileReq entity.CreateUserRequest
 	userRequest := entity.User{
 		Username: userProfileReq.Username,
 		Password: userProfileReq.Password,
+
+		EmailVerificationRequired: userProfileReq.EmailVerificationRequired,
+	}
+	if userProfileReq.Email != "" {
+		userRequest.Email = &userProfileReq.Email
 	}
 
 	user, err := uc.auth.CreateUser(userRequest)
 	if err != nil {
-		return nil, fmt.Errorf("error when create user to auth service %s", err.Error())
+		return nil, fmt.Errorf("error when create user to auth service %w", err)
 	}
 
 	data := entity.UserProfile{
@@ -66,19 +93,95 @@ func (uc *userProfile) CreateUserProfile(userProfileReq entity.CreateUserRequest
 	return userProfile, nil
 }
 
+// InviteUserProfile create the profile of a user invited in auth service, who chooses the password later
+func (uc *userProfile) InviteUserProfile(req entity.InviteUserProfileRequest) (*entity.Invitation, error) {
+	invitation, err := uc.auth.InviteUser(entity.InviteUserRequest{
+		Username: req.Username,
+		Email:    req.Email,
+	})
+	authErr := &repo.AuthError{}
+	if errors.As(err, &authErr) && authErr.StatusCode == http.StatusConflict {
+		return nil, fmt.Errorf("username or email %w", ErrUserAlreadyExists)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error when invite user to auth service %w", err)
+	}
+
+	data := req.UserProfile
+	data.UserKsuid = invitation.UserKsuid
+
+	userProfile, err := uc.repo.CreateUserProfile(data)
+	if err != nil {
+		deleteUserInAuthService(invitation.UserKsuid, uc.auth)
+
+		return nil, fmt.Errorf("failed when create user_profiles")
+	}
+
+	invitation.Profile = userProfile
+
+	return invitation, nil
+}
+
+func (uc *userProfile) GetInvitations() ([]entity.Invitation, error) {
+	invitations, err := uc.auth.GetInvitations()
+	if err != nil {
+		return nil, fmt.Errorf("error when get invitations from auth service %w", err)
+	}
+
+	for i := range invitations {
+		if userProfile, err := uc.repo.GetUserProfile(invitations[i].UserKsuid); err == nil {
+			userProfile.DateOfBirth = convertDatetime(userProfile.DateOfBirth)
+			invitations[i].Profile = userProfile
+		}
+	}
+
+	return invitations, nil
+}
+
+// RevokeInvitation delete the invited user and its profile, unless the invitation is accepted meanwhile
+func (uc *userProfile) RevokeInvitation(userKsuid string) (*entity.UserProfile, error) {
+	_, err := uc.auth.RevokeInvitation(userKsuid)
+	authErr := &repo.AuthError{}
+	if errors.As(err, &authErr) && authErr.StatusCode == http.StatusNotFound {
+		return nil, ErrInvitationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed when revoke invitation to auth_service with ksuid %s", userKsuid)
+	}
+
+	deletedUser, err := uc.repo.DeleteUserProfile(userKsuid)
+	if err != nil {
+		return nil, fmt.Errorf("failed when delete user_profiles with ksuid %s", userKsuid)
+	}
+
+	deletedUser.DateOfBirth = convertDatetime(deletedUser.DateOfBirth)
+
+	return deletedUser, nil
+}
+
+// UpdateUserProfile replace the profile when it is still at userProfile.Version
 func (uc *userProfile) UpdateUserProfile(userKsuid string, userProfile entity.UserProfile) (*entity.UserProfile, error) {
-	_, err := uc.repo.GetUserProfile(userKsuid)
+	current, err := uc.repo.GetUserProfile(userKsuid)
 	if err != nil {
 		return nil, fmt.Errorf("user_profiles with ksuid %s not found", userKsuid)
 	}
 
+	if current.Version != userProfile.Version {
+		return nil, ErrVersionMismatch
+	}
+
 	userProfile.UserKsuid = userKsuid
 
 	userProfileResp, err := uc.repo.UpdateUserProfile(userProfile)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrVersionMismatch
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed when update user_profiles with ksuid %s", userKsuid)
 	}
 
+	userProfileResp.DateOfBirth = convertDatetime(userProfileResp.DateOfBirth)
+
 	return userProfileResp, nil
 }
 
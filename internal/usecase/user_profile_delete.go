@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/adesupraptolaia/user_login/internal/repo"
+)
+
+// USER_PURGE_BATCH is the most users purged on one run
+const USER_PURGE_BATCH = 100
+
+var ErrRestorePeriodExpired = errors.New("the restore period of the deleted user is over")
+
+// RestoreUserProfile undo the deletion of the user in auth service then of its profile,
+// within the grace period after the deletion
+func (uc *userProfile) RestoreUserProfile(userKsuid string) (*entity.UserProfile, error) {
+	deleted, err := uc.repo.GetDeletedUserProfile(userKsuid)
+	if err != nil {
+		return nil, ErrUserNotDeleted
+	}
+
+	deletedSince := time.Now().Add(-uc.gracePeriod)
+	if deleted.DeletedAt.Before(deletedSince) {
+		return nil, ErrRestorePeriodExpired
+	}
+
+	_, err = uc.auth.RestoreUser(userKsuid)
+	authErr := &repo.AuthError{}
+	if errors.As(err, &authErr) && authErr.StatusCode == http.StatusNotFound {
+		// restored by a previous attempt whose profile was not, unless the purge was first
+		// or the grace period of auth service is over
+		user, err := uc.getUserInAuthService(userKsuid)
+		if err != nil {
+			return nil, fmt.Errorf("failed when get user from auth_service with ksuid %s", userKsuid)
+		}
+		if user == nil || user.IsDeleted() {
+			return nil, ErrRestorePeriodExpired
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed when restore user to auth_service with ksuid %s", userKsuid)
+	}
+
+	if err := uc.repo.RestoreUserProfile(userKsuid, deletedSince); err != nil {
+		// restored meanwhile by another request
+		if userProfile, err := uc.GetUserProfile(userKsuid); err == nil {
+			return userProfile, nil
+		}
+
+		deleteUserInAuthService(userKsuid, uc.auth)
+
+		return nil, fmt.Errorf("failed when restore user_profiles with ksuid %s", userKsuid)
+	}
+
+	return uc.GetUserProfile(userKsuid)
+}
+
+// PurgeDeletedUserProfiles permanently delete up to USER_PURGE_BATCH users deleted for longer than the grace period,
+// in auth service then their profile. A user failing is purged on a later run, the returned error counts them
+func (uc *userProfile) PurgeDeletedUserProfiles() (int, error) {
+	deletedBefore := time.Now().Add(-uc.gracePeriod)
+
+	deletedProfiles, err := uc.repo.GetDeletedUserProfiles(deletedBefore, USER_PURGE_BATCH)
+	if err != nil {
+		return 0, fmt.Errorf("failed when get deleted user_profiles")
+	}
+
+	purged, failed := 0, 0
+	for _, deleted := range deletedProfiles {
+		err := uc.auth.PurgeUser(deleted.UserKsuid)
+		authErr := &repo.AuthError{}
+		if errors.As(err, &authErr) && authErr.StatusCode == http.StatusNotFound {
+			// purged by a previous run whose profile was not, or a revoked invitation. The profile
+			// is kept while auth service has the user, restored but not the profile yet,
+			// or deleted but still within the grace period of auth service
+			if user, err := uc.getUserInAuthService(deleted.UserKsuid); err != nil || user != nil {
+				failed++
+				continue
+			}
+		} else if err != nil {
+			failed++
+			continue
+		}
+
+		if err := uc.repo.PurgeUserProfile(deleted.UserKsuid, deletedBefore); err != nil {
+			failed++
+			continue
+		}
+
+		purged++
+	}
+
+	if failed > 0 {
+		return purged, fmt.Errorf("failed when purge %d deleted users", failed)
+	}
+
+	return purged, nil
+}
+
+// getUserInAuthService get the user from auth service, deleted or not, nil when it is absent, i.e. purged
+func (uc *userProfile) getUserInAuthService(userKsuid string) (*entity.User, error) {
+	users, err := uc.auth.GetUsersWithDeleted([]string{userKsuid})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(users) == 0 {
+		return nil, nil
+	}
+
+	return &users[0], nil
+}
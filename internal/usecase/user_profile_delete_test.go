@@ -0,0 +1,191 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/adesupraptolaia/user_login/internal/repo"
+	repoMocks "github.com/adesupraptolaia/user_login/internal/repo/mocks"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+const testGracePeriod = 30 * 24 * time.Hour
+
+func Test_userProfile_RestoreUserProfile(t *testing.T) {
+	profileRepo := repoMocks.NewUserProfilesRepo(t)
+	authRepo := repoMocks.NewAuthRepo(t)
+
+	recently := time.Now().Add(-time.Hour)
+	longAgo := time.Now().Add(-testGracePeriod - time.Hour)
+
+	for ksuid, deletedAt := range map[string]time.Time{
+		"ksuid": recently, "restoredInAuth": recently, "purgedInAuth": recently, "expiredInAuth": recently, "failed": recently, "expired": longAgo,
+	} {
+		deletedAt := deletedAt
+		profileRepo.On("GetDeletedUserProfile", ksuid).
+			Return(&entity.UserProfile{UserKsuid: ksuid, Name: "user", DeletedAt: &deletedAt}, nil).
+			Once()
+	}
+	profileRepo.On("GetDeletedUserProfile", "notDeleted").
+		Return(nil, gorm.ErrRecordNotFound).
+		Once()
+
+	authRepo.On("RestoreUser", "ksuid").Return(&entity.User{Ksuid: "ksuid"}, nil).Once()
+	authRepo.On("RestoreUser", "failed").Return(&entity.User{Ksuid: "failed"}, nil).Once()
+	for _, ksuid := range []string{"restoredInAuth", "purgedInAuth", "expiredInAuth"} {
+		authRepo.On("RestoreUser", ksuid).
+			Return(nil, &repo.AuthError{StatusCode: http.StatusNotFound, Message: "user is not deleted"}).
+			Once()
+	}
+	authRepo.On("GetUsersWithDeleted", []string{"restoredInAuth"}).Return([]entity.User{{Ksuid: "restoredInAuth"}}, nil).Once()
+	authRepo.On("GetUsersWithDeleted", []string{"purgedInAuth"}).Return([]entity.User{}, nil).Once()
+	// still deleted in auth service but its grace period is over there, waiting for the purge
+	authRepo.On("GetUsersWithDeleted", []string{"expiredInAuth"}).Return([]entity.User{{Ksuid: "expiredInAuth", DeletedAt: &longAgo}}, nil).Once()
+
+	for _, ksuid := range []string{"ksuid", "restoredInAuth"} {
+		profileRepo.On("RestoreUserProfile", ksuid, mock.AnythingOfType("time.Time")).Return(nil).Once()
+		profileRepo.On("GetUserProfile", ksuid).
+			Return(&entity.UserProfile{UserKsuid: ksuid, Name: "user", DateOfBirth: "2019-01-01T00:00:00Z", Version: 2}, nil).
+			Once()
+	}
+
+	// the profile can't be restored, the user is deleted again in auth service
+	profileRepo.On("RestoreUserProfile", "failed", mock.AnythingOfType("time.Time")).Return(fmt.Errorf("connection refused")).Once()
+	profileRepo.On("GetUserProfile", "failed").Return(nil, gorm.ErrRecordNotFound).Once()
+	authRepo.On("DeleteUser", "failed").Return(&entity.User{Ksuid: "failed"}, nil).Once()
+
+	tests := []struct {
+		name      string
+		userKsuid string
+		want      *entity.UserProfile
+		wantErr   error
+	}{
+		{
+			name:      "Success Restore",
+			userKsuid: "ksuid",
+			want:      &entity.UserProfile{UserKsuid: "ksuid", Name: "user", DateOfBirth: "2019-01-01", Version: 2},
+		},
+		{
+			name:      "Success Restore, already restored in auth service",
+			userKsuid: "restoredInAuth",
+			want:      &entity.UserProfile{UserKsuid: "restoredInAuth", Name: "user", DateOfBirth: "2019-01-01", Version: 2},
+		},
+		{
+			name:      "Failed Restore, not deleted",
+			userKsuid: "notDeleted",
+			wantErr:   ErrUserNotDeleted,
+		},
+		{
+			name:      "Failed Restore, grace period over",
+			userKsuid: "expired",
+			wantErr:   ErrRestorePeriodExpired,
+		},
+		{
+			name:      "Failed Restore, purged in auth service",
+			userKsuid: "purgedInAuth",
+			wantErr:   ErrRestorePeriodExpired,
+		},
+		{
+			name:      "Failed Restore, grace period over in auth service",
+			userKsuid: "expiredInAuth",
+			wantErr:   ErrRestorePeriodExpired,
+		},
+		{
+			name:      "Failed Restore, profile not restored",
+			userKsuid: "failed",
+			wantErr:   errors.New("failed when restore user_profiles with ksuid failed"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &userProfile{
+				repo:        profileRepo,
+				auth:        authRepo,
+				gracePeriod: testGracePeriod,
+			}
+			got, err := uc.RestoreUserProfile(tt.userKsuid)
+			if !errors.Is(err, tt.wantErr) && !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("userProfile.RestoreUserProfile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("userProfile.RestoreUserProfile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_userProfile_PurgeDeletedUserProfiles(t *testing.T) {
+	profileRepo := repoMocks.NewUserProfilesRepo(t)
+	authRepo := repoMocks.NewAuthRepo(t)
+
+	deleted := []entity.UserProfile{
+		{UserKsuid: "ksuid"}, {UserKsuid: "purgedInAuth"}, {UserKsuid: "restoredInAuth"}, {UserKsuid: "deletedInAuth"}, {UserKsuid: "authDown"}, {UserKsuid: "failed"},
+	}
+
+	// deleted before the grace period
+	deletedBefore := mock.MatchedBy(func(before time.Time) bool {
+		return time.Since(before) >= testGracePeriod && time.Since(before) < testGracePeriod+time.Minute
+	})
+
+	profileRepo.On("GetDeletedUserProfiles", deletedBefore, USER_PURGE_BATCH).Return(deleted, nil).Once()
+	profileRepo.On("GetDeletedUserProfiles", deletedBefore, USER_PURGE_BATCH).Return(nil, fmt.Errorf("connection refused")).Once()
+
+	authRepo.On("PurgeUser", "ksuid").Return(nil).Once()
+	authRepo.On("PurgeUser", "failed").Return(nil).Once()
+	for _, ksuid := range []string{"purgedInAuth", "restoredInAuth", "deletedInAuth"} {
+		authRepo.On("PurgeUser", ksuid).
+			Return(&repo.AuthError{StatusCode: http.StatusNotFound, Message: "user is not deleted"}).
+			Once()
+	}
+	authRepo.On("PurgeUser", "authDown").Return(fmt.Errorf("connection refused")).Once()
+	authRepo.On("GetUsersWithDeleted", []string{"purgedInAuth"}).Return([]entity.User{}, nil).Once()
+	authRepo.On("GetUsersWithDeleted", []string{"restoredInAuth"}).Return([]entity.User{{Ksuid: "restoredInAuth"}}, nil).Once()
+	// still within the grace period of auth service, the profile is purged after the user
+	deletedAt := time.Now().Add(-testGracePeriod)
+	authRepo.On("GetUsersWithDeleted", []string{"deletedInAuth"}).Return([]entity.User{{Ksuid: "deletedInAuth", DeletedAt: &deletedAt}}, nil).Once()
+
+	profileRepo.On("PurgeUserProfile", "ksuid", deletedBefore).Return(nil).Once()
+	profileRepo.On("PurgeUserProfile", "purgedInAuth", deletedBefore).Return(nil).Once()
+	profileRepo.On("PurgeUserProfile", "failed", deletedBefore).Return(fmt.Errorf("connection refused")).Once()
+
+	tests := []struct {
+		name    string
+		want    int
+		wantErr error
+	}{
+		{
+			name:    "Purge, some users failed",
+			want:    2,
+			wantErr: errors.New("failed when purge 4 deleted users"),
+		},
+		{
+			name:    "Failed Purge, deleted users not found",
+			want:    0,
+			wantErr: errors.New("failed when get deleted user_profiles"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &userProfile{
+				repo:        profileRepo,
+				auth:        authRepo,
+				gracePeriod: testGracePeriod,
+			}
+			got, err := uc.PurgeDeletedUserProfiles()
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("userProfile.PurgeDeletedUserProfiles() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("userProfile.PurgeDeletedUserProfiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
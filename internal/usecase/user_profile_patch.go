@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/adesupraptolaia/user_login/pkg/jsonpatch"
+	"github.com/adesupraptolaia/user_login/pkg/validator"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidPatch     = errors.New("invalid patch")
+	ErrUnsupportedPatch = errors.New("unsupported patch content type")
+)
+
+// fields of the profile a patch can change, by json name, which is also the column name
+var patchableProfileFields = map[string]string{
+	"name":          "Name",
+	"date_of_birth": "DateOfBirth",
+	"address":       "Address",
+}
+
+// PatchUserProfile apply a JSON Merge Patch or a JSON Patch to the profile,
+// only the fields changed by the patch are validated and saved
+func (uc *userProfile) PatchUserProfile(userKsuid string, version int, contentType string, patch []byte) (*entity.UserProfile, error) {
+	return uc.patchUserProfile(userKsuid, version, contentType, patch, nil)
+}
+
+// patchUserProfile apply the patch, the changed fields are refused when checkChanges returns an error
+func (uc *userProfile) patchUserProfile(userKsuid string, version int, contentType string, patch []byte, checkChanges func(map[string]string) error) (*entity.UserProfile, error) {
+	current, err := uc.repo.GetUserProfile(userKsuid)
+	if err != nil {
+		return nil, fmt.Errorf("user_profiles with ksuid %s not found", userKsuid)
+	}
+
+	if current.Version != version {
+		return nil, ErrVersionMismatch
+	}
+
+	current.DateOfBirth = convertDatetime(current.DateOfBirth)
+
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return nil, fmt.Errorf("failed when marshal user_profiles with ksuid %s", userKsuid)
+	}
+
+	var patched []byte
+	switch contentType {
+	case entity.MERGE_PATCH:
+		patched, err = jsonpatch.MergePatch(doc, patch)
+	case entity.JSON_PATCH:
+		patched, err = jsonpatch.ApplyPatch(doc, patch)
+	default:
+		return nil, ErrUnsupportedPatch
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w, %s", ErrInvalidPatch, err.Error())
+	}
+
+	changes, err := profileChanges(doc, patched)
+	if err != nil {
+		return nil, err
+	}
+
+	if checkChanges != nil {
+		if err := checkChanges(changes); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(changes) == 0 {
+		return current, nil
+	}
+
+	updated := *current
+	fields := []string{}
+	columns := map[string]interface{}{}
+	for name, value := range changes {
+		field := patchableProfileFields[name]
+		reflect.ValueOf(&updated).Elem().FieldByName(field).SetString(value)
+		fields = append(fields, field)
+		columns[name] = value
+	}
+
+	// a field left out of the patch keeps its value, even one the validation would reject today
+	if err := validator.ValidateStructPartial(updated, fields...); err != nil {
+		return nil, fmt.Errorf("%w, %s", ErrInvalidPatch, err.Error())
+	}
+
+	userProfile, err := uc.repo.PatchUserProfile(userKsuid, version, columns)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrVersionMismatch
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed when update user_profiles with ksuid %s", userKsuid)
+	}
+
+	userProfile.DateOfBirth = convertDatetime(userProfile.DateOfBirth)
+
+	return userProfile, nil
+}
+
+// profileChanges compare the profile before and after the patch, and return the new value of every changed field.
+// A removed field is changed to empty
+func profileChanges(before, after []byte) (map[string]string, error) {
+	beforeFields := map[string]interface{}{}
+	if err := json.Unmarshal(before, &beforeFields); err != nil {
+		return nil, fmt.Errorf("failed when unmarshal user_profiles")
+	}
+
+	afterFields := map[string]interface{}{}
+	if err := json.Unmarshal(after, &afterFields); err != nil {
+		return nil, fmt.Errorf("%w, the profile must stay an object", ErrInvalidPatch)
+	}
+
+	names := []string{}
+	for name := range beforeFields {
+		names = append(names, name)
+	}
+	for name := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := map[string]string{}
+	for _, name := range names {
+		if reflect.DeepEqual(beforeFields[name], afterFields[name]) {
+			continue
+		}
+
+		if _, ok := patchableProfileFields[name]; !ok {
+			return nil, fmt.Errorf("%w, %s can't be changed", ErrInvalidPatch, name)
+		}
+
+		value, ok := afterFields[name].(string)
+		if !ok && afterFields[name] != nil {
+			return nil, fmt.Errorf("%w, %s must be a string", ErrInvalidPatch, name)
+		}
+
+		changes[name] = value
+	}
+
+	return changes, nil
+}
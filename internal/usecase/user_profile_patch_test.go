@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	repoMocks "github.com/adesupraptolaia/user_login/internal/repo/mocks"
+	"gorm.io/gorm"
+)
+
+func Test_userProfile_PatchUserProfile(t *testing.T) {
+	profileRepo := repoMocks.NewUserProfilesRepo(t)
+
+	data := entity.UserProfile{UserKsuid: "ksuid", Name: "user", DateOfBirth: "2019-01-01T00:00:00Z", Address: "Perawang", Version: 2}
+	// saved before the address was required
+	noAddress := entity.UserProfile{UserKsuid: "noAddress", Name: "user", DateOfBirth: "2019-01-01", Version: 1}
+
+	for _, profile := range []entity.UserProfile{data, noAddress} {
+		profile := profile
+		profileRepo.On("GetUserProfile", profile.UserKsuid).
+			Return(func(string) *entity.UserProfile {
+				userProfile := profile
+				return &userProfile
+			}, nil)
+	}
+
+	profileRepo.On("PatchUserProfile", "ksuid", 2, map[string]interface{}{"address": "Malang"}).
+		Return(&entity.UserProfile{UserKsuid: "ksuid", Name: "user", DateOfBirth: "2019-01-01", Address: "Malang", Version: 3}, nil).
+		Once()
+
+	// updated by another request after GetUserProfile
+	profileRepo.On("PatchUserProfile", "ksuid", 2, map[string]interface{}{"address": "Jakarta"}).
+		Return(nil, gorm.ErrRecordNotFound).
+		Once()
+
+	profileRepo.On("PatchUserProfile", "ksuid", 2, map[string]interface{}{"name": "toni", "date_of_birth": "2000-02-29"}).
+		Return(&entity.UserProfile{UserKsuid: "ksuid", Name: "toni", DateOfBirth: "2000-02-29", Address: "Perawang", Version: 3}, nil).
+		Once()
+
+	profileRepo.On("PatchUserProfile", "noAddress", 1, map[string]interface{}{"name": "toni"}).
+		Return(&entity.UserProfile{UserKsuid: "noAddress", Name: "toni", DateOfBirth: "2019-01-01", Version: 2}, nil).
+		Once()
+
+	type args struct {
+		userKsuid   string
+		version     int
+		contentType string
+		patch       string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *entity.UserProfile
+		wantErr error
+	}{
+		{
+			name: "Success Merge Patch",
+			args: args{"ksuid", 2, entity.MERGE_PATCH, `{"address": "Malang"}`},
+			want: &entity.UserProfile{UserKsuid: "ksuid", Name: "user", DateOfBirth: "2019-01-01", Address: "Malang", Version: 3},
+		},
+		{
+			name: "Success JSON Patch",
+			args: args{"ksuid", 2, entity.JSON_PATCH, `[
+				{"op": "test", "path": "/name", "value": "user"},
+				{"op": "replace", "path": "/name", "value": "toni"},
+				{"op": "replace", "path": "/date_of_birth", "value": "2000-02-29"}
+			]`},
+			want: &entity.UserProfile{UserKsuid: "ksuid", Name: "toni", DateOfBirth: "2000-02-29", Address: "Perawang", Version: 3},
+		},
+		{
+			name: "Success Patch, only changed fields validated",
+			args: args{"noAddress", 1, entity.MERGE_PATCH, `{"name": "toni"}`},
+			want: &entity.UserProfile{UserKsuid: "noAddress", Name: "toni", DateOfBirth: "2019-01-01", Version: 2},
+		},
+		{
+			name: "Success Patch, nothing changed",
+			args: args{"ksuid", 2, entity.MERGE_PATCH, `{"name": "user"}`},
+			want: &entity.UserProfile{UserKsuid: "ksuid", Name: "user", DateOfBirth: "2019-01-01", Address: "Perawang", Version: 2},
+		},
+		{
+			name:    "Failed Patch, invalid date",
+			args:    args{"ksuid", 2, entity.MERGE_PATCH, `{"date_of_birth": "29-02-2000"}`},
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "Failed Patch, required field removed",
+			args:    args{"ksuid", 2, entity.MERGE_PATCH, `{"name": null}`},
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "Failed Patch, not a string",
+			args:    args{"ksuid", 2, entity.JSON_PATCH, `[{"op": "replace", "path": "/address", "value": 1}]`},
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "Failed Patch, ksuid changed",
+			args:    args{"ksuid", 2, entity.MERGE_PATCH, `{"user_ksuid": "other"}`},
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "Failed Patch, unknown field",
+			args:    args{"ksuid", 2, entity.JSON_PATCH, `[{"op": "add", "path": "/role", "value": "admin"}]`},
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "Failed Patch, test operation failed",
+			args:    args{"ksuid", 2, entity.JSON_PATCH, `[{"op": "test", "path": "/name", "value": "admin"}, {"op": "replace", "path": "/name", "value": "toni"}]`},
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "Failed Patch, malformed patch",
+			args:    args{"ksuid", 2, entity.MERGE_PATCH, `{"name": `},
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "Failed Patch, version mismatch",
+			args:    args{"ksuid", 1, entity.MERGE_PATCH, `{"address": "Malang"}`},
+			wantErr: ErrVersionMismatch,
+		},
+		{
+			name:    "Failed Patch, updated meanwhile",
+			args:    args{"ksuid", 2, entity.MERGE_PATCH, `{"address": "Jakarta"}`},
+			wantErr: ErrVersionMismatch,
+		},
+		{
+			name:    "Failed Patch, version changed",
+			args:    args{"ksuid", 2, entity.JSON_PATCH, `[{"op": "replace", "path": "/version", "value": 1}]`},
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "Failed Patch, unsupported content type",
+			args:    args{"ksuid", 2, "text/plain", `name=toni`},
+			wantErr: ErrUnsupportedPatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &userProfile{
+				repo: profileRepo,
+			}
+			got, err := uc.PatchUserProfile(tt.args.userKsuid, tt.args.version, tt.args.contentType, []byte(tt.args.patch))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("userProfile.PatchUserProfile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("userProfile.PatchUserProfile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
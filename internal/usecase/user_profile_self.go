@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"gorm.io/gorm"
+)
+
+// FieldPermissionError is returned when users change fields of their own profile their role can't edit
+type FieldPermissionError struct {
+	Role   string
+	Fields []string
+}
+
+func (e *FieldPermissionError) Error() string {
+	return fmt.Sprintf("role %s can't change %s", e.Role, strings.Join(e.Fields, ", "))
+}
+
+// UpdateOwnUserProfile replace the profile of the user, every field different from the saved one must be editable by the role
+func (uc *userProfile) UpdateOwnUserProfile(userKsuid, role string, userProfile entity.UserProfile) (*entity.UserProfile, error) {
+	current, err := uc.repo.GetUserProfile(userKsuid)
+	if err != nil {
+		return nil, fmt.Errorf("user_profiles with ksuid %s not found", userKsuid)
+	}
+
+	if current.Version != userProfile.Version {
+		return nil, ErrVersionMismatch
+	}
+
+	current.DateOfBirth = convertDatetime(current.DateOfBirth)
+	userProfile.DateOfBirth = convertDatetime(userProfile.DateOfBirth)
+
+	names := []string{}
+	for name := range patchableProfileFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	changes := map[string]string{}
+	for _, name := range names {
+		field := patchableProfileFields[name]
+		value := reflect.ValueOf(userProfile).FieldByName(field).String()
+		if value != reflect.ValueOf(*current).FieldByName(field).String() {
+			changes[name] = value
+		}
+	}
+
+	if err := uc.checkEditableFields(role, changes); err != nil {
+		return nil, err
+	}
+
+	if len(changes) == 0 {
+		return current, nil
+	}
+
+	columns := map[string]interface{}{}
+	for name, value := range changes {
+		columns[name] = value
+	}
+
+	userProfileResp, err := uc.repo.PatchUserProfile(userKsuid, userProfile.Version, columns)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrVersionMismatch
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed when update user_profiles with ksuid %s", userKsuid)
+	}
+
+	userProfileResp.DateOfBirth = convertDatetime(userProfileResp.DateOfBirth)
+
+	return userProfileResp, nil
+}
+
+// PatchOwnUserProfile apply the patch to the profile of the user, every field changed by the patch must be editable by the role
+func (uc *userProfile) PatchOwnUserProfile(userKsuid string, version int, role, contentType string, patch []byte) (*entity.UserProfile, error) {
+	return uc.patchUserProfile(userKsuid, version, contentType, patch, func(changes map[string]string) error {
+		return uc.checkEditableFields(role, changes)
+	})
+}
+
+func (uc *userProfile) checkEditableFields(role string, changes map[string]string) error {
+	editable := map[string]bool{}
+	for _, name := range uc.selfEditableFields[role] {
+		editable[name] = true
+	}
+
+	refused := []string{}
+	for name := range changes {
+		if !editable[name] {
+			refused = append(refused, name)
+		}
+	}
+
+	if len(refused) > 0 {
+		sort.Strings(refused)
+		return &FieldPermissionError{Role: role, Fields: refused}
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	repoMocks "github.com/adesupraptolaia/user_login/internal/repo/mocks"
+)
+
+var selfEditableFields = map[string][]string{
+	entity.USER: {"name", "address"},
+}
+
+func Test_userProfile_UpdateOwnUserProfile(t *testing.T) {
+	profileRepo := repoMocks.NewUserProfilesRepo(t)
+
+	data := entity.UserProfile{UserKsuid: "ksuid", Name: "user", DateOfBirth: "2019-01-01T00:00:00Z", Address: "Perawang", Version: 1}
+
+	profileRepo.On("GetUserProfile", "ksuid").
+		Return(func(string) *entity.UserProfile {
+			userProfile := data
+			return &userProfile
+		}, nil)
+	profileRepo.On("GetUserProfile", "notFound").Return(nil, errors.New("record not found"))
+
+	profileRepo.On("PatchUserProfile", "ksuid", 1, map[string]interface{}{"address": "Malang"}).
+		Return(&entity.UserProfile{UserKsuid: "ksuid", Name: "user", DateOfBirth: "2019-01-01T00:00:00Z", Address: "Malang", Version: 2}, nil).
+		Once()
+
+	type args struct {
+		userKsuid   string
+		role        string
+		userProfile entity.UserProfile
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *entity.UserProfile
+		wantErr error
+	}{
+		{
+			name: "Success Update Own Profile",
+			args: args{"ksuid", entity.USER, entity.UserProfile{Name: "user", DateOfBirth: "2019-01-01", Address: "Malang", Version: 1}},
+			want: &entity.UserProfile{UserKsuid: "ksuid", Name: "user", DateOfBirth: "2019-01-01", Address: "Malang", Version: 2},
+		},
+		{
+			name: "Success Update Own Profile, nothing changed",
+			args: args{"ksuid", "guest", entity.UserProfile{Name: "user", DateOfBirth: "2019-01-01", Address: "Perawang", Version: 1}},
+			want: &entity.UserProfile{UserKsuid: "ksuid", Name: "user", DateOfBirth: "2019-01-01", Address: "Perawang", Version: 1},
+		},
+		{
+			name:    "Failed Update Own Profile, field not editable",
+			args:    args{"ksuid", entity.USER, entity.UserProfile{Name: "toni", DateOfBirth: "2000-02-29", Address: "Malang", Version: 1}},
+			wantErr: &FieldPermissionError{Role: entity.USER, Fields: []string{"date_of_birth"}},
+		},
+		{
+			name:    "Failed Update Own Profile, role can't edit any field",
+			args:    args{"ksuid", "guest", entity.UserProfile{Name: "toni", DateOfBirth: "2019-01-01", Address: "Malang", Version: 1}},
+			wantErr: &FieldPermissionError{Role: "guest", Fields: []string{"address", "name"}},
+		},
+		{
+			name:    "Failed Update Own Profile, version mismatch",
+			args:    args{"ksuid", entity.USER, entity.UserProfile{Name: "user", DateOfBirth: "2019-01-01", Address: "Malang", Version: 2}},
+			wantErr: ErrVersionMismatch,
+		},
+		{
+			name:    "Failed Update Own Profile, not found",
+			args:    args{"notFound", entity.USER, entity.UserProfile{Name: "toni", DateOfBirth: "2019-01-01", Address: "Malang", Version: 1}},
+			wantErr: errors.New("user_profiles with ksuid notFound not found"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &userProfile{
+				repo:               profileRepo,
+				selfEditableFields: selfEditableFields,
+			}
+			got, err := uc.UpdateOwnUserProfile(tt.args.userKsuid, tt.args.role, tt.args.userProfile)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("userProfile.UpdateOwnUserProfile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("userProfile.UpdateOwnUserProfile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_userProfile_PatchOwnUserProfile(t *testing.T) {
+	profileRepo := repoMocks.NewUserProfilesRepo(t)
+
+	data := entity.UserProfile{UserKsuid: "ksuid", Name: "user", DateOfBirth: "2019-01-01", Address: "Perawang", Version: 1}
+
+	profileRepo.On("GetUserProfile", "ksuid").
+		Return(func(string) *entity.UserProfile {
+			userProfile := data
+			return &userProfile
+		}, nil)
+
+	profileRepo.On("PatchUserProfile", "ksuid", 1, map[string]interface{}{"name": "toni", "address": "Malang"}).
+		Return(&entity.UserProfile{UserKsuid: "ksuid", Name: "toni", DateOfBirth: "2019-01-01", Address: "Malang", Version: 2}, nil).
+		Once()
+
+	type args struct {
+		role        string
+		contentType string
+		patch       string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *entity.UserProfile
+		wantErr error
+	}{
+		{
+			name: "Success Patch Own Profile",
+			args: args{entity.USER, entity.MERGE_PATCH, `{"name": "toni", "address": "Malang"}`},
+			want: &entity.UserProfile{UserKsuid: "ksuid", Name: "toni", DateOfBirth: "2019-01-01", Address: "Malang", Version: 2},
+		},
+		{
+			name:    "Failed Patch Own Profile, field not editable",
+			args:    args{entity.USER, entity.JSON_PATCH, `[{"op": "replace", "path": "/date_of_birth", "value": "2000-02-29"}, {"op": "remove", "path": "/address"}]`},
+			wantErr: &FieldPermissionError{Role: entity.USER, Fields: []string{"date_of_birth"}},
+		},
+		{
+			name:    "Failed Patch Own Profile, role can't edit any field",
+			args:    args{"guest", entity.MERGE_PATCH, `{"address": "Malang"}`},
+			wantErr: &FieldPermissionError{Role: "guest", Fields: []string{"address"}},
+		},
+		{
+			name:    "Failed Patch Own Profile, ksuid changed",
+			args:    args{entity.USER, entity.MERGE_PATCH, `{"user_ksuid": "other"}`},
+			wantErr: ErrInvalidPatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &userProfile{
+				repo:               profileRepo,
+				selfEditableFields: selfEditableFields,
+			}
+			got, err := uc.PatchOwnUserProfile("ksuid", 1, tt.args.role, tt.args.contentType, []byte(tt.args.patch))
+			if !errors.Is(err, tt.wantErr) && !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("userProfile.PatchOwnUserProfile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("userProfile.PatchOwnUserProfile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,13 +1,17 @@
 package usecase
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
 	"reflect"
 	"testing"
 
 	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/adesupraptolaia/user_login/internal/repo"
 	repoMocks "github.com/adesupraptolaia/user_login/internal/repo/mocks"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 func Test_userProfile_GetUser(t *testing.T) {
@@ -24,8 +28,8 @@ func Test_userProfile_GetUser(t *testing.T) {
 		Once()
 
 	type fields struct {
-		repo repoMocks.UserProfilesRepo
-		auth repoMocks.AuthRepo
+		repo *repoMocks.UserProfilesRepo
+		auth *repoMocks.AuthRepo
 	}
 	type args struct {
 		userksuid string
@@ -39,14 +43,14 @@ func Test_userProfile_GetUser(t *testing.T) {
 	}{
 		{
 			name:    "Success Get User",
-			fields:  fields{repo: *repo},
+			fields:  fields{repo: repo},
 			args:    args{"ksuid"},
 			want:    &data,
 			wantErr: false,
 		},
 		{
 			name:    "Failed Get User",
-			fields:  fields{repo: *repo},
+			fields:  fields{repo: repo},
 			args:    args{"wrongKsuid"},
 			want:    nil,
 			wantErr: true,
@@ -55,8 +59,8 @@ func Test_userProfile_GetUser(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			uc := &userProfile{
-				repo: &tt.fields.repo,
-				auth: &tt.fields.auth,
+				repo: tt.fields.repo,
+				auth: tt.fields.auth,
 			}
 			got, err := uc.GetUserProfile(tt.args.userksuid)
 			if (err != nil) != tt.wantErr {
@@ -97,8 +101,8 @@ func Test_userProfile_CreateUser(t *testing.T) {
 		Return(nil, fmt.Errorf("user not found"))
 
 	type fields struct {
-		repo repoMocks.UserProfilesRepo
-		auth repoMocks.AuthRepo
+		repo *repoMocks.UserProfilesRepo
+		auth *repoMocks.AuthRepo
 	}
 	type args struct {
 		userProfileReq entity.CreateUserRequest
@@ -112,14 +116,14 @@ func Test_userProfile_CreateUser(t *testing.T) {
 	}{
 		{
 			name:    "Success Create User",
-			fields:  fields{repo: *repo, auth: *authRepo},
+			fields:  fields{repo: repo, auth: authRepo},
 			args:    args{reqSuccess},
 			want:    &data,
 			wantErr: false,
 		},
 		{
 			name:    "Success Create User",
-			fields:  fields{repo: *repo, auth: *authRepo},
+			fields:  fields{repo: repo, auth: authRepo},
 			args:    args{reqFailed},
 			want:    nil,
 			wantErr: true,
@@ -128,8 +132,8 @@ func Test_userProfile_CreateUser(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			uc := &userProfile{
-				repo: &tt.fields.repo,
-				auth: &tt.fields.auth,
+				repo: tt.fields.repo,
+				auth: tt.fields.auth,
 			}
 			got, err := uc.CreateUserProfile(tt.args.userProfileReq)
 			if (err != nil) != tt.wantErr {
@@ -164,9 +168,15 @@ func Test_userProfile_UpdateUser(t *testing.T) {
 		Return(nil, fmt.Errorf("user not found")).
 		Once()
 
+	// updated by another request after GetUserProfile
+	staleReq := entity.UserProfile{UserKsuid: "staleKsuid"}
+	repo.On("UpdateUserProfile", staleReq).
+		Return(nil, gorm.ErrRecordNotFound).
+		Once()
+
 	type fields struct {
-		repo repoMocks.UserProfilesRepo
-		auth repoMocks.AuthRepo
+		repo *repoMocks.UserProfilesRepo
+		auth *repoMocks.AuthRepo
 	}
 	type args struct {
 		userKsuid   string
@@ -181,24 +191,38 @@ func Test_userProfile_UpdateUser(t *testing.T) {
 	}{
 		{
 			name:    "success update user",
-			fields:  fields{repo: *repo},
+			fields:  fields{repo: repo},
 			args:    args{"ksuid", successReq},
 			want:    &data,
 			wantErr: false,
 		},
 		{
 			name:    "failed update user",
-			fields:  fields{repo: *repo},
+			fields:  fields{repo: repo},
 			args:    args{"wrongKsuid", failedReq},
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name:    "failed update user, version mismatch",
+			fields:  fields{repo: repo},
+			args:    args{"ksuid", entity.UserProfile{Version: 1}},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "failed update user, updated meanwhile",
+			fields:  fields{repo: repo},
+			args:    args{"staleKsuid", staleReq},
+			want:    nil,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			uc := &userProfile{
-				repo: &tt.fields.repo,
-				auth: &tt.fields.auth,
+				repo: tt.fields.repo,
+				auth: tt.fields.auth,
 			}
 			got, err := uc.UpdateUserProfile(tt.args.userKsuid, tt.args.userProfile)
 			if (err != nil) != tt.wantErr {
@@ -233,8 +257,8 @@ func Test_userProfile_DeleteUser(t *testing.T) {
 		Return(&entity.User{Ksuid: "ksuid", Username: "user", Password: "user"}, nil)
 
 	type fields struct {
-		repo repoMocks.UserProfilesRepo
-		auth repoMocks.AuthRepo
+		repo *repoMocks.UserProfilesRepo
+		auth *repoMocks.AuthRepo
 	}
 	type args struct {
 		userKsuid string
@@ -248,14 +272,14 @@ func Test_userProfile_DeleteUser(t *testing.T) {
 	}{
 		{
 			name:    "Success Delete User",
-			fields:  fields{repo: *repo, auth: *authRepo},
+			fields:  fields{repo: repo, auth: authRepo},
 			args:    args{"ksuid"},
 			want:    &data,
 			wantErr: false,
 		},
 		{
 			name:    "Failed Delete User",
-			fields:  fields{repo: *repo, auth: *authRepo},
+			fields:  fields{repo: repo, auth: authRepo},
 			args:    args{"wrongKsuid"},
 			want:    nil,
 			wantErr: true,
@@ -264,8 +288,8 @@ func Test_userProfile_DeleteUser(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			uc := &userProfile{
-				repo: &tt.fields.repo,
-				auth: &tt.fields.auth,
+				repo: tt.fields.repo,
+				auth: tt.fields.auth,
 			}
 			got, err := uc.DeleteUserProfile(tt.args.userKsuid)
 			if (err != nil) != tt.wantErr {
@@ -278,3 +302,136 @@ func Test_userProfile_DeleteUser(t *testing.T) {
 		})
 	}
 }
+
+func Test_userProfile_InviteUserProfile(t *testing.T) {
+	profileRepo := repoMocks.NewUserProfilesRepo(t)
+	authRepo := repoMocks.NewAuthRepo(t)
+
+	profile := entity.UserProfile{Name: "user", DateOfBirth: "2019-01-01", Address: "Perawang"}
+	created := entity.UserProfile{UserKsuid: "ksuid", Name: "user", DateOfBirth: "2019-01-01", Address: "Perawang"}
+	failed := entity.UserProfile{UserKsuid: "failedKsuid", Name: "user", DateOfBirth: "2019-01-01", Address: "Perawang"}
+
+	authRepo.On("InviteUser", entity.InviteUserRequest{Username: "user"}).
+		Return(&entity.Invitation{UserKsuid: "ksuid", Username: "user", InviteToken: "token"}, nil).
+		Once()
+
+	authRepo.On("InviteUser", entity.InviteUserRequest{Username: "failed"}).
+		Return(&entity.Invitation{UserKsuid: "failedKsuid", Username: "failed", InviteToken: "token"}, nil).
+		Once()
+
+	authRepo.On("InviteUser", entity.InviteUserRequest{Username: "taken"}).
+		Return(nil, &repo.AuthError{StatusCode: http.StatusConflict, Message: "user with username taken already exist"}).
+		Once()
+
+	profileRepo.On("CreateUserProfile", created).
+		Return(&created, nil).
+		Once()
+
+	profileRepo.On("CreateUserProfile", failed).
+		Return(nil, fmt.Errorf("duplicate entry")).
+		Once()
+
+	// the invited user is removed from auth service when the profile can't be saved
+	authRepo.On("DeleteUser", "failedKsuid").
+		Return(&entity.User{Ksuid: "failedKsuid"}, nil).
+		Once()
+
+	tests := []struct {
+		name    string
+		req     entity.InviteUserProfileRequest
+		want    *entity.Invitation
+		wantErr error
+	}{
+		{
+			name: "Success Invite User",
+			req:  entity.InviteUserProfileRequest{UserProfile: profile, Username: "user"},
+			want: &entity.Invitation{UserKsuid: "ksuid", Username: "user", InviteToken: "token", Profile: &created},
+		},
+		{
+			name:    "Failed Invite User, username taken",
+			req:     entity.InviteUserProfileRequest{UserProfile: profile, Username: "taken"},
+			wantErr: ErrUserAlreadyExists,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &userProfile{
+				repo: profileRepo,
+				auth: authRepo,
+			}
+			got, err := uc.InviteUserProfile(tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("userProfile.InviteUserProfile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("userProfile.InviteUserProfile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("Failed Invite User, profile not saved", func(t *testing.T) {
+		uc := &userProfile{
+			repo: profileRepo,
+			auth: authRepo,
+		}
+		if _, err := uc.InviteUserProfile(entity.InviteUserProfileRequest{UserProfile: profile, Username: "failed"}); err == nil {
+			t.Errorf("userProfile.InviteUserProfile() error = nil, want an error")
+		}
+	})
+}
+
+func Test_userProfile_RevokeInvitation(t *testing.T) {
+	profileRepo := repoMocks.NewUserProfilesRepo(t)
+	authRepo := repoMocks.NewAuthRepo(t)
+
+	data := entity.UserProfile{
+		UserKsuid: "ksuid", Name: "user", DateOfBirth: "2019-01-01", Address: "Perawang",
+	}
+
+	authRepo.On("RevokeInvitation", "ksuid").
+		Return(&entity.User{Ksuid: "ksuid"}, nil).
+		Once()
+
+	authRepo.On("RevokeInvitation", "acceptedKsuid").
+		Return(nil, &repo.AuthError{StatusCode: http.StatusNotFound, Message: "invitation not found"}).
+		Once()
+
+	profileRepo.On("DeleteUserProfile", "ksuid").
+		Return(&data, nil).
+		Once()
+
+	tests := []struct {
+		name      string
+		userKsuid string
+		want      *entity.UserProfile
+		wantErr   error
+	}{
+		{
+			name:      "Success Revoke Invitation",
+			userKsuid: "ksuid",
+			want:      &data,
+		},
+		{
+			name:      "Failed Revoke Invitation, already accepted",
+			userKsuid: "acceptedKsuid",
+			wantErr:   ErrInvitationNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &userProfile{
+				repo: profileRepo,
+				auth: authRepo,
+			}
+			got, err := uc.RevokeInvitation(tt.userKsuid)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("userProfile.RevokeInvitation() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("userProfile.RevokeInvitation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
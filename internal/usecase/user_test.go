@@ -1,17 +1,163 @@
 package usecase
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/adesupraptolaia/user_login/internal/entity"
 	repoMocks "github.com/adesupraptolaia/user_login/internal/repo/mocks"
-	"github.com/adesupraptolaia/user_login/internal/utils"
+	"github.com/adesupraptolaia/user_login/pkg/hasher"
 	"github.com/segmentio/ksuid"
 	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
+// bcrypt at its minimum cost keeps the tests fast
+var testHasher, _ = hasher.New(hasher.Config{Algorithm: hasher.BCRYPT, Bcrypt: hasher.BcryptParams{Cost: bcrypt.MinCost}})
+
+func hashPassword(password string) string {
+	hash, _ := testHasher.Hash(password)
+	return hash
+}
+
+func isPasswordMatch(hash, password string) bool {
+	match, _ := testHasher.Verify(hash, password)
+	return match
+}
+
+func Test_user_Login(t *testing.T) {
+	repo := repoMocks.NewUsersRepo(t)
+
+	argon2idHasher, _ := hasher.New(hasher.Config{Algorithm: hasher.ARGON2ID, Argon2id: hasher.Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1}})
+	outdatedHash, _ := bcrypt.GenerateFromPassword([]byte("user"), bcrypt.MinCost+1)
+
+	current := &entity.User{Ksuid: "current", Username: "current", Password: hashPassword("user"), Role: entity.USER}
+	outdated := &entity.User{Ksuid: "outdated", Username: "outdated", Password: string(outdatedHash), Role: entity.USER}
+	migrated := &entity.User{Ksuid: "migrated", Username: "migrated", Password: hashPassword("user"), Role: entity.USER}
+	changed := &entity.User{Ksuid: "changed", Username: "changed", Password: string(outdatedHash), Role: entity.USER}
+	invitedAt := time.Now()
+	invited := &entity.User{Ksuid: "invited", Username: "invited", Role: entity.USER, InvitedAt: &invitedAt}
+	deletedAt := time.Now()
+	deleted := &entity.User{Ksuid: "deleted", Username: "deleted", Password: hashPassword("user"), Role: entity.USER, DeletedAt: &deletedAt}
+
+	for _, data := range []*entity.User{current, outdated, migrated, changed, invited, deleted} {
+		data := data
+		repo.On("GetUserByUsername", data.Username).
+			Return(func(string) *entity.User {
+				user := *data
+				return &user
+			}, nil)
+	}
+
+	repo.On("GetUserByUsername", "toni").
+		Return(nil, fmt.Errorf("not found")).
+		Once()
+
+	repo.On("RehashPassword", "outdated", string(outdatedHash), mock.MatchedBy(func(hash string) bool {
+		return !testHasher.NeedsRehash(hash) && isPasswordMatch(hash, "user")
+	})).
+		Return(nil).
+		Once()
+
+	repo.On("RehashPassword", "migrated", migrated.Password, mock.MatchedBy(func(hash string) bool {
+		return !argon2idHasher.NeedsRehash(hash)
+	})).
+		Return(nil).
+		Once()
+
+	// the password was changed since it was read, the login still succeeds
+	repo.On("RehashPassword", "changed", string(outdatedHash), mock.AnythingOfType("string")).
+		Return(fmt.Errorf("record not found")).
+		Once()
+
+	type fields struct {
+		hasher hasher.Hasher
+	}
+	type args struct {
+		username string
+		password string
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		args       args
+		wantRehash bool
+		wantErr    error
+	}{
+		{
+			name:   "Success Login",
+			fields: fields{testHasher},
+			args:   args{"current", "user"},
+		},
+		{
+			name:       "Success Login, rehash outdated cost",
+			fields:     fields{testHasher},
+			args:       args{"outdated", "user"},
+			wantRehash: true,
+		},
+		{
+			name:       "Success Login, rehash bcrypt to argon2id",
+			fields:     fields{argon2idHasher},
+			args:       args{"migrated", "user"},
+			wantRehash: true,
+		},
+		{
+			name:   "Success Login, failed rehash",
+			fields: fields{testHasher},
+			args:   args{"changed", "user"},
+		},
+		{
+			name:    "Failed Login, wrong password",
+			fields:  fields{testHasher},
+			args:    args{"current", "wrong"},
+			wantErr: ErrWrongUsernameOrPassword,
+		},
+		{
+			name:    "Failed Login, user not found",
+			fields:  fields{testHasher},
+			args:    args{"toni", "user"},
+			wantErr: ErrWrongUsernameOrPassword,
+		},
+		{
+			name:    "Failed Login, invitation pending",
+			fields:  fields{testHasher},
+			args:    args{"invited", ""},
+			wantErr: ErrWrongUsernameOrPassword,
+		},
+		{
+			name:    "Failed Login, user deleted",
+			fields:  fields{testHasher},
+			args:    args{"deleted", "user"},
+			wantErr: ErrWrongUsernameOrPassword,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &user{
+				repo:   repo,
+				hasher: tt.fields.hasher,
+			}
+			got, err := uc.Login(tt.args.username, tt.args.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("user.Login() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+
+			stored, _ := repo.GetUserByUsername(tt.args.username)
+			if rehashed := got.Password != stored.Password; rehashed != tt.wantRehash {
+				t.Errorf("user.Login() rehashed = %v, want %v", rehashed, tt.wantRehash)
+			}
+		})
+	}
+}
+
 func Test_user_GetUserByUsername(t *testing.T) {
 	repo := repoMocks.NewUsersRepo(t)
 
@@ -26,7 +172,7 @@ func Test_user_GetUserByUsername(t *testing.T) {
 		Once()
 
 	type fields struct {
-		repo repoMocks.UsersRepo
+		repo *repoMocks.UsersRepo
 	}
 	type args struct {
 		username string
@@ -40,14 +186,14 @@ func Test_user_GetUserByUsername(t *testing.T) {
 	}{
 		{
 			name:    "Success Get user",
-			fields:  fields{repo: *repo},
+			fields:  fields{repo: repo},
 			args:    args{"user"},
 			want:    data,
 			wantErr: false,
 		},
 		{
 			name:    "Faield Get user",
-			fields:  fields{repo: *repo},
+			fields:  fields{repo: repo},
 			args:    args{"toni"},
 			want:    nil,
 			wantErr: true,
@@ -56,7 +202,7 @@ func Test_user_GetUserByUsername(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			uc := &user{
-				repo: &tt.fields.repo,
+				repo: tt.fields.repo,
 			}
 			got, err := uc.GetUserByUsername(tt.args.username)
 			if (err != nil) != tt.wantErr {
@@ -83,44 +229,60 @@ func Test_user_GetUserByKsuid(t *testing.T) {
 		Return(nil, fmt.Errorf("not found")).
 		Once()
 
+	repo.On("GetUserByKsuid", "unknownKsuid").
+		Return(nil, gorm.ErrRecordNotFound).
+		Once()
+
 	type fields struct {
-		repo repoMocks.UsersRepo
+		repo *repoMocks.UsersRepo
 	}
 	type args struct {
 		ksuid string
 	}
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		want    *entity.User
-		wantErr bool
+		name         string
+		fields       fields
+		args         args
+		want         *entity.User
+		wantErr      bool
+		wantNotFound bool
 	}{
 		{
 			name:    "Success Get User",
-			fields:  fields{repo: *repo},
+			fields:  fields{repo: repo},
 			args:    args{"ksuid"},
 			want:    data,
 			wantErr: false,
 		},
 		{
 			name:    "Failed Get User",
-			fields:  fields{repo: *repo},
+			fields:  fields{repo: repo},
 			args:    args{"wrongKsuid"},
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name:         "Failed Get Unknown User",
+			fields:       fields{repo: repo},
+			args:         args{"unknownKsuid"},
+			want:         nil,
+			wantErr:      true,
+			wantNotFound: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			uc := &user{
-				repo: &tt.fields.repo,
+				repo: tt.fields.repo,
 			}
 			got, err := uc.GetUserByKsuid(tt.args.ksuid)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("user.GetUserByKsuid() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if errors.Is(err, ErrUserNotFound) != tt.wantNotFound {
+				t.Errorf("user.GetUserByKsuid() error = %v, wantNotFound %v", err, tt.wantNotFound)
+			}
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("user.GetUserByKsuid() = %v, want %v", got, tt.want)
 			}
@@ -130,8 +292,9 @@ func Test_user_GetUserByKsuid(t *testing.T) {
 
 func Test_user_CreateUser(t *testing.T) {
 	repo := repoMocks.NewUsersRepo(t)
+	breachedRepo := repoMocks.NewBreachedPasswordsRepo(t)
 
-	data := &entity.User{Username: "user", Password: utils.HashPassword("user"), Role: entity.USER}
+	data := &entity.User{Username: "user", Password: hashPassword("user"), Role: entity.USER}
 
 	repo.On("CreateUser", mock.AnythingOfType("entity.User")).
 		Return(data, nil).
@@ -139,10 +302,20 @@ func Test_user_CreateUser(t *testing.T) {
 
 	repo.On("GetUserByUsername", "user").
 		Return(nil, fmt.Errorf("user not found")).
+		Times(3)
+
+	repo.On("GetUserByEmail", "used@example.com").
+		Return(&entity.User{Ksuid: "other", Username: "other"}, nil).
 		Once()
 
+	breachedRepo.On("GetHashSuffixes", mock.AnythingOfType("string")).
+		Return(nil, nil).
+		Twice()
+
 	type fields struct {
-		repo repoMocks.UsersRepo
+		repo         *repoMocks.UsersRepo
+		breachedRepo *repoMocks.BreachedPasswordsRepo
+		policy       PasswordPolicy
 	}
 	type args struct {
 		userReq entity.UserRequest
@@ -156,16 +329,33 @@ func Test_user_CreateUser(t *testing.T) {
 	}{
 		{
 			name:    "Success Get User",
-			fields:  fields{repo: *repo},
+			fields:  fields{repo: repo, breachedRepo: breachedRepo},
 			args:    args{entity.UserRequest{Username: "user", Password: "user"}},
 			want:    data,
 			wantErr: false,
 		},
+		{
+			name:    "Failed Create User, email already used",
+			fields:  fields{repo: repo, breachedRepo: breachedRepo},
+			args:    args{entity.UserRequest{Username: "user", Password: "user", Email: " Used@Example.com"}},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Failed Create User, weak password",
+			fields:  fields{repo: repo, breachedRepo: breachedRepo, policy: PasswordPolicy{MinLength: 8}},
+			args:    args{entity.UserRequest{Username: "user", Password: "user"}},
+			want:    nil,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			uc := &user{
-				repo: &tt.fields.repo,
+				repo:         tt.fields.repo,
+				breachedRepo: tt.fields.breachedRepo,
+				policy:       tt.fields.policy,
+				hasher:       testHasher,
 			}
 			got, err := uc.CreateUser(tt.args.userReq)
 			if (err != nil) != tt.wantErr {
@@ -182,7 +372,7 @@ func Test_user_CreateUser(t *testing.T) {
 func Test_user_UpdateUser(t *testing.T) {
 	repo := repoMocks.NewUsersRepo(t)
 
-	data := &entity.User{Ksuid: "ksuid", Username: "user", Password: utils.HashPassword("user"), Role: entity.USER}
+	data := &entity.User{Ksuid: "ksuid", Username: "user", Password: hashPassword("user"), Role: entity.USER}
 
 	repo.On("UpdateUser", "ksuid", *data).
 		Return(data, nil).
@@ -197,7 +387,7 @@ func Test_user_UpdateUser(t *testing.T) {
 		Once()
 
 	type fields struct {
-		repo repoMocks.UsersRepo
+		repo *repoMocks.UsersRepo
 	}
 	type args struct {
 		ksuid string
@@ -212,14 +402,14 @@ func Test_user_UpdateUser(t *testing.T) {
 	}{
 		{
 			name:    "Success Get User",
-			fields:  fields{repo: *repo},
+			fields:  fields{repo: repo},
 			args:    args{"ksuid", *data},
 			want:    data,
 			wantErr: false,
 		},
 		{
 			name:    "Success Get User",
-			fields:  fields{repo: *repo},
+			fields:  fields{repo: repo},
 			args:    args{"wrongKsuid", *data},
 			want:    nil,
 			wantErr: true,
@@ -228,7 +418,7 @@ func Test_user_UpdateUser(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			uc := &user{
-				repo: &tt.fields.repo,
+				repo: tt.fields.repo,
 			}
 			got, err := uc.UpdateUser(tt.args.ksuid, tt.args.user)
 			if (err != nil) != tt.wantErr {
@@ -242,25 +432,156 @@ func Test_user_UpdateUser(t *testing.T) {
 	}
 }
 
+func Test_user_ChangePassword(t *testing.T) {
+	repo := repoMocks.NewUsersRepo(t)
+	historyRepo := repoMocks.NewPasswordHistoriesRepo(t)
+	breachedRepo := repoMocks.NewBreachedPasswordsRepo(t)
+
+	data := &entity.User{Ksuid: "ksuid", Username: "user", Password: hashPassword("user"), Role: entity.USER}
+
+	repo.On("GetUserByKsuid", "ksuid").
+		Return(func(string) *entity.User {
+			user := *data
+			return &user
+		}, nil).
+		Times(3)
+
+	repo.On("GetUserByKsuid", "wrongKsuid").
+		Return(nil, fmt.Errorf("user not found")).
+		Once()
+
+	historyRepo.On("GetPasswordHistory", "ksuid", 2).
+		Return([]entity.PasswordHistory{{UserKsuid: "ksuid", PasswordHash: hashPassword("oldPassword")}}, nil).
+		Twice()
+
+	breachedRepo.On("GetHashSuffixes", mock.AnythingOfType("string")).
+		Return(nil, nil).
+		Twice()
+
+	historyRepo.On("CreatePasswordHistory", mock.MatchedBy(func(history entity.PasswordHistory) bool {
+		return history.UserKsuid == "ksuid" && history.PasswordHash == data.Password
+	})).
+		Return(nil).
+		Once()
+
+	historyRepo.On("PrunePasswordHistory", "ksuid", 2).
+		Return(nil).
+		Once()
+
+	repo.On("UpdateUser", "ksuid", mock.MatchedBy(func(user entity.User) bool {
+		return isPasswordMatch(user.Password, "newPassword") && user.PasswordChangedAt != nil
+	})).
+		Return(data, nil).
+		Once()
+
+	type fields struct {
+		repo *repoMocks.UsersRepo
+	}
+	type args struct {
+		ksuid string
+		req   entity.ChangePasswordRequest
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    *entity.User
+		wantErr bool
+	}{
+		{
+			name:    "Success Change Password",
+			fields:  fields{repo: repo},
+			args:    args{"ksuid", entity.ChangePasswordRequest{OldPassword: "user", NewPassword: "newPassword"}},
+			want:    data,
+			wantErr: false,
+		},
+		{
+			name:    "Failed Change Password, wrong old password",
+			fields:  fields{repo: repo},
+			args:    args{"ksuid", entity.ChangePasswordRequest{OldPassword: "wrong", NewPassword: "newPassword"}},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Failed Change Password, reused password",
+			fields:  fields{repo: repo},
+			args:    args{"ksuid", entity.ChangePasswordRequest{OldPassword: "user", NewPassword: "oldPassword"}},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Failed Change Password, user not found",
+			fields:  fields{repo: repo},
+			args:    args{"wrongKsuid", entity.ChangePasswordRequest{OldPassword: "user", NewPassword: "newPassword"}},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &user{
+				repo:         tt.fields.repo,
+				historyRepo:  historyRepo,
+				breachedRepo: breachedRepo,
+				policy:       PasswordPolicy{HistorySize: 3},
+				hasher:       testHasher,
+			}
+			got, err := uc.ChangePassword(tt.args.ksuid, tt.args.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("user.ChangePassword() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("user.ChangePassword() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_user_DeleteUser(t *testing.T) {
 	repo := repoMocks.NewUsersRepo(t)
+	sessionRepo := repoMocks.NewSessionsRepo(t)
+	refreshTokenRepo := repoMocks.NewRefreshTokensRepo(t)
 
-	data := &entity.User{Ksuid: "ksuid", Username: "user", Password: utils.HashPassword("user"), Role: entity.USER}
+	data := &entity.User{Ksuid: "ksuid", Username: "user", Password: hashPassword("user"), Role: entity.USER}
+	admin := &entity.User{Ksuid: "adminKsuid", Username: "admin", Password: hashPassword("admin"), Role: entity.ADMIN}
+	deletedMeanwhile := &entity.User{Ksuid: "deletedKsuid", Username: "deleted", Password: hashPassword("deleted"), Role: entity.USER}
 
 	repo.On("DeleteUser", "ksuid").
 		Return(data, nil).
 		Once()
 
+	repo.On("DeleteUser", "deletedKsuid").
+		Return(nil, gorm.ErrRecordNotFound).
+		Once()
+
 	repo.On("GetUserByKsuid", "ksuid").
 		Return(data, nil).
 		Once()
 
+	repo.On("GetUserByKsuid", "adminKsuid").
+		Return(admin, nil).
+		Once()
+
+	repo.On("GetUserByKsuid", "deletedKsuid").
+		Return(deletedMeanwhile, nil).
+		Once()
+
 	repo.On("GetUserByKsuid", "wrongKsuid").
-		Return(nil, fmt.Errorf("user not found")).
+		Return(nil, gorm.ErrRecordNotFound).
+		Once()
+
+	// the sessions of the deleted user are ended
+	sessionRepo.On("RevokeUserSessions", "ksuid").
+		Return(nil).
+		Once()
+
+	refreshTokenRepo.On("RevokeUserRefreshTokens", "ksuid").
+		Return(nil).
 		Once()
 
 	type fields struct {
-		repo repoMocks.UsersRepo
+		repo *repoMocks.UsersRepo
 	}
 	type args struct {
 		ksuid string
@@ -270,30 +591,41 @@ func Test_user_DeleteUser(t *testing.T) {
 		fields  fields
 		args    args
 		want    *entity.User
-		wantErr bool
+		wantErr error
 	}{
 		{
-			name:    "Success Get User",
-			fields:  fields{repo: *repo},
-			args:    args{"ksuid"},
-			want:    data,
-			wantErr: false,
+			name:   "Success Delete User",
+			fields: fields{repo: repo},
+			args:   args{"ksuid"},
+			want:   data,
 		},
 		{
-			name:    "Success Get User",
-			fields:  fields{repo: *repo},
+			name:    "Failed Delete User, user not found",
+			fields:  fields{repo: repo},
 			args:    args{"wrongKsuid"},
-			want:    nil,
-			wantErr: true,
+			wantErr: ErrUserNotFound,
+		},
+		{
+			name:    "Failed Delete User, admin",
+			fields:  fields{repo: repo},
+			args:    args{"adminKsuid"},
+			wantErr: ErrUserNotDeletable,
+		},
+		{
+			name:    "Failed Delete User, deleted meanwhile",
+			fields:  fields{repo: repo},
+			args:    args{"deletedKsuid"},
+			wantErr: ErrUserNotFound,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			uc := &user{
-				repo: &tt.fields.repo,
+				repo:    tt.fields.repo,
+				tokenUC: &token{repo: refreshTokenRepo, sessionRepo: sessionRepo},
 			}
 			got, err := uc.DeleteUser(tt.args.ksuid)
-			if (err != nil) != tt.wantErr {
+			if !errors.Is(err, tt.wantErr) {
 				t.Errorf("user.DeleteUser() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
@@ -303,3 +635,278 @@ func Test_user_DeleteUser(t *testing.T) {
 		})
 	}
 }
+
+func Test_user_RestoreUser(t *testing.T) {
+	repo := repoMocks.NewUsersRepo(t)
+
+	data := &entity.User{Ksuid: "ksuid", Username: "user", Password: hashPassword("user"), Role: entity.USER}
+
+	// restored only when deleted during the grace period
+	deletedSince := mock.MatchedBy(func(deletedSince time.Time) bool {
+		return time.Until(deletedSince).Round(time.Minute) == -time.Hour
+	})
+
+	repo.On("RestoreUser", "ksuid", deletedSince).
+		Return(nil).
+		Once()
+
+	repo.On("GetUserByKsuid", "ksuid").
+		Return(data, nil).
+		Once()
+
+	repo.On("RestoreUser", "notDeleted", deletedSince).
+		Return(gorm.ErrRecordNotFound).
+		Once()
+
+	repo.On("RestoreUser", "failed", deletedSince).
+		Return(fmt.Errorf("connection refused")).
+		Once()
+
+	tests := []struct {
+		name    string
+		ksuid   string
+		want    *entity.User
+		wantErr error
+	}{
+		{
+			name:  "Success Restore User",
+			ksuid: "ksuid",
+			want:  data,
+		},
+		{
+			name:    "Failed Restore User, not deleted or after the grace period",
+			ksuid:   "notDeleted",
+			wantErr: ErrUserNotDeleted,
+		},
+		{
+			name:    "Failed Restore User, database error",
+			ksuid:   "failed",
+			wantErr: errors.New("failed when restore user with ksuid failed"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &user{
+				repo:        repo,
+				gracePeriod: time.Hour,
+			}
+			got, err := uc.RestoreUser(tt.ksuid)
+			if !errors.Is(err, tt.wantErr) && !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("user.RestoreUser() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("user.RestoreUser() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_user_PurgeUser(t *testing.T) {
+	repo := repoMocks.NewUsersRepo(t)
+
+	// purged only when deleted before the grace period
+	deletedBefore := mock.MatchedBy(func(deletedBefore time.Time) bool {
+		return time.Until(deletedBefore).Round(time.Minute) == -time.Hour
+	})
+
+	repo.On("PurgeUser", "ksuid", deletedBefore).
+		Return(nil).
+		Once()
+
+	repo.On("PurgeUser", "notDeleted", deletedBefore).
+		Return(gorm.ErrRecordNotFound).
+		Once()
+
+	tests := []struct {
+		name    string
+		ksuid   string
+		wantErr error
+	}{
+		{
+			name:  "Success Purge User",
+			ksuid: "ksuid",
+		},
+		{
+			name:    "Failed Purge User, not deleted or during the grace period",
+			ksuid:   "notDeleted",
+			wantErr: ErrUserNotDeleted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &user{
+				repo:        repo,
+				gracePeriod: time.Hour,
+			}
+			if err := uc.PurgeUser(tt.ksuid); !errors.Is(err, tt.wantErr) {
+				t.Errorf("user.PurgeUser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_user_PurgeDeletedUsers(t *testing.T) {
+	repo := repoMocks.NewUsersRepo(t)
+
+	deleted := []entity.User{{Ksuid: "ksuid"}, {Ksuid: "purgedMeanwhile"}, {Ksuid: "failed"}}
+
+	// deleted before the grace period
+	deletedBefore := mock.MatchedBy(func(deletedBefore time.Time) bool {
+		return time.Until(deletedBefore).Round(time.Minute) == -time.Hour
+	})
+
+	repo.On("GetDeletedUsers", deletedBefore, USER_PURGE_BATCH).Return(deleted, nil).Once()
+	repo.On("GetDeletedUsers", deletedBefore, USER_PURGE_BATCH).Return(nil, fmt.Errorf("connection refused")).Once()
+
+	repo.On("PurgeUser", "ksuid", deletedBefore).Return(nil).Once()
+	repo.On("PurgeUser", "purgedMeanwhile", deletedBefore).Return(gorm.ErrRecordNotFound).Once()
+	repo.On("PurgeUser", "failed", deletedBefore).Return(fmt.Errorf("connection refused")).Once()
+
+	tests := []struct {
+		name    string
+		want    int
+		wantErr error
+	}{
+		{
+			name:    "Purge, some users failed",
+			want:    1,
+			wantErr: errors.New("failed when purge 1 deleted users"),
+		},
+		{
+			name:    "Failed Purge, deleted users not found",
+			want:    0,
+			wantErr: errors.New("failed when get deleted users"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &user{
+				repo:        repo,
+				gracePeriod: time.Hour,
+			}
+			got, err := uc.PurgeDeletedUsers()
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("user.PurgeDeletedUsers() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("user.PurgeDeletedUsers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// the password changed at a fraction of second MySQL would round up must not reject the tokens issued after it
+func Test_user_ChangePasswordThenRefreshToken(t *testing.T) {
+	repo := repoMocks.NewUsersRepo(t)
+	historyRepo := repoMocks.NewPasswordHistoriesRepo(t)
+	breachedRepo := repoMocks.NewBreachedPasswordsRepo(t)
+	refreshRepo := repoMocks.NewRefreshTokensRepo(t)
+	sessionRepo := repoMocks.NewSessionsRepo(t)
+	roleRepo := repoMocks.NewRolesRepo(t)
+
+	data := &entity.User{Ksuid: "ksuid", Username: "user", Password: hashPassword("user"), Role: entity.USER}
+	session := &entity.Session{Ksuid: "family", UserKsuid: "ksuid"}
+
+	var stored entity.User
+	var refreshTokens []entity.RefreshToken
+
+	repo.On("GetUserByKsuid", "ksuid").
+		Return(func(string) *entity.User {
+			user := *data
+			return &user
+		}, nil).
+		Once()
+
+	historyRepo.On("GetPasswordHistory", "ksuid", 2).
+		Return(nil, nil).
+		Once()
+
+	breachedRepo.On("GetHashSuffixes", mock.AnythingOfType("string")).
+		Return(nil, nil).
+		Once()
+
+	historyRepo.On("CreatePasswordHistory", mock.AnythingOfType("entity.PasswordHistory")).
+		Return(nil).
+		Once()
+
+	historyRepo.On("PrunePasswordHistory", "ksuid", 2).
+		Return(nil).
+		Once()
+
+	// the DATETIME column rounds the fraction of second
+	repo.On("UpdateUser", "ksuid", mock.AnythingOfType("entity.User")).
+		Run(func(args mock.Arguments) {
+			stored = args.Get(1).(entity.User)
+			changedAt := stored.PasswordChangedAt.Round(time.Second)
+			stored.PasswordChangedAt = &changedAt
+		}).
+		Return(func(string, entity.User) *entity.User { return &stored }, nil).
+		Once()
+
+	repo.On("GetUserByKsuid", "ksuid").
+		Return(func(string) *entity.User { return &stored }, nil).
+		Once()
+
+	sessionRepo.On("CreateSession", mock.AnythingOfType("entity.Session")).
+		Return(session, nil).
+		Once()
+
+	sessionRepo.On("GetSession", "family").
+		Return(session, nil).
+		Once()
+
+	roleRepo.On("GetRolePermissions", entity.USER).
+		Return([]string{entity.PERM_PROFILE_READ_SELF}, nil).
+		Twice()
+
+	refreshRepo.On("CreateRefreshToken", mock.AnythingOfType("entity.RefreshToken")).
+		Run(func(args mock.Arguments) {
+			refreshTokens = append(refreshTokens, args.Get(0).(entity.RefreshToken))
+		}).
+		Return(&entity.RefreshToken{}, nil).
+		Twice()
+
+	refreshRepo.On("GetRefreshToken", mock.AnythingOfType("string")).
+		Return(func(jti string) *entity.RefreshToken { return &refreshTokens[0] }, nil).
+		Once()
+
+	refreshRepo.On("RevokeRefreshToken", mock.AnythingOfType("string")).
+		Return(nil).
+		Once()
+
+	userUC := &user{
+		repo:         repo,
+		historyRepo:  historyRepo,
+		breachedRepo: breachedRepo,
+		policy:       PasswordPolicy{HistorySize: 3},
+		hasher:       testHasher,
+	}
+	tokenUC := &token{
+		repo:        refreshRepo,
+		sessionRepo: sessionRepo,
+		userRepo:    repo,
+		roleRepo:    roleRepo,
+	}
+
+	// change the password in the second half of a second
+	now := time.Now()
+	if fraction := now.Sub(now.Truncate(time.Second)); fraction < 600*time.Millisecond {
+		time.Sleep(600*time.Millisecond - fraction)
+	}
+
+	user, err := userUC.ChangePassword("ksuid", entity.ChangePasswordRequest{OldPassword: "user", NewPassword: "newPassword"})
+	if err != nil {
+		t.Fatalf("user.ChangePassword() error = %v", err)
+	}
+
+	pair, err := tokenUC.CreateToken(user)
+	if err != nil {
+		t.Fatalf("token.CreateToken() error = %v", err)
+	}
+
+	if _, err := tokenUC.RefreshToken(pair.RefreshToken, ""); err != nil {
+		t.Errorf("token.RefreshToken() of the pair issued after the password change error = %v, want nil", err)
+	}
+}
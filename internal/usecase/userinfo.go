@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/adesupraptolaia/user_login/internal/repo"
+)
+
+type UserInfoUC interface {
+	GetUserInfo(string, string) (*entity.UserInfo, error)
+}
+
+// ErrUserInfoNotFound is returned for a token without user, like a client token
+var ErrUserInfoNotFound = errors.New("token has no user")
+
+type userInfo struct {
+	userRepo    repo.UsersRepo
+	userAppRepo repo.UserAppRepo
+}
+
+func NewUserInfo(userRepo repo.UsersRepo, userAppRepo repo.UserAppRepo) UserInfoUC {
+	return &userInfo{
+		userRepo:    userRepo,
+		userAppRepo: userAppRepo,
+	}
+}
+
+// GetUserInfo map the profile in user app to the standard claims,
+// the profile is read with the access token of the user
+func (uc *userInfo) GetUserInfo(userKsuid, accessToken string) (*entity.UserInfo, error) {
+	user, err := uc.userRepo.GetUserByKsuid(userKsuid)
+	if err != nil {
+		return nil, ErrUserInfoNotFound
+	}
+
+	profile, err := uc.userAppRepo.GetUserProfile(userKsuid, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed when get profile of user with ksuid %s, err: %s", userKsuid, err.Error())
+	}
+
+	info := &entity.UserInfo{
+		Sub:               user.Ksuid,
+		PreferredUsername: user.Username,
+		Name:              profile.Name,
+		Birthdate:         convertDatetime(profile.DateOfBirth),
+	}
+
+	if profile.Address != "" {
+		info.Address = &entity.UserInfoAddress{Formatted: profile.Address}
+	}
+
+	return info, nil
+}
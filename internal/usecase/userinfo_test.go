@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	repoMocks "github.com/adesupraptolaia/user_login/internal/repo/mocks"
+)
+
+func Test_userInfo_GetUserInfo(t *testing.T) {
+	userRepo := repoMocks.NewUsersRepo(t)
+	userAppRepo := repoMocks.NewUserAppRepo(t)
+
+	userRepo.On("GetUserByKsuid", "ksuid").
+		Return(&entity.User{Ksuid: "ksuid", Username: "user", Role: entity.USER}, nil).
+		Twice()
+
+	userRepo.On("GetUserByKsuid", "client").
+		Return(nil, fmt.Errorf("not found")).
+		Once()
+
+	userAppRepo.On("GetUserProfile", "ksuid", "token").
+		Return(&entity.UserProfile{UserKsuid: "ksuid", Name: "User", DateOfBirth: "2019-01-01T00:00:00Z", Address: "Malang"}, nil).
+		Once()
+
+	userAppRepo.On("GetUserProfile", "ksuid", "token").
+		Return(nil, fmt.Errorf("user service is down")).
+		Once()
+
+	type fields struct {
+		userRepo    *repoMocks.UsersRepo
+		userAppRepo *repoMocks.UserAppRepo
+	}
+	type args struct {
+		userKsuid   string
+		accessToken string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    *entity.UserInfo
+		wantErr bool
+	}{
+		{
+			name:   "Success Get User Info",
+			fields: fields{userRepo: userRepo, userAppRepo: userAppRepo},
+			args:   args{"ksuid", "token"},
+			want: &entity.UserInfo{
+				Sub:               "ksuid",
+				PreferredUsername: "user",
+				Name:              "User",
+				Birthdate:         "2019-01-01",
+				Address:           &entity.UserInfoAddress{Formatted: "Malang"},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Failed Get Profile",
+			fields:  fields{userRepo: userRepo, userAppRepo: userAppRepo},
+			args:    args{"ksuid", "token"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Failed Token Without User",
+			fields:  fields{userRepo: userRepo, userAppRepo: userAppRepo},
+			args:    args{"client", "token"},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &userInfo{
+				userRepo:    tt.fields.userRepo,
+				userAppRepo: tt.fields.userAppRepo,
+			}
+			got, err := uc.GetUserInfo(tt.args.userKsuid, tt.args.accessToken)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("userInfo.GetUserInfo() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.args.userKsuid == "client" && !errors.Is(err, ErrUserInfoNotFound) {
+				t.Errorf("userInfo.GetUserInfo() error = %v, want %v", err, ErrUserInfoNotFound)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("userInfo.GetUserInfo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package hasher
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BcryptParams only use the first 72 bytes of the password
+type BcryptParams struct {
+	Cost int
+}
+
+type bcryptHasher struct {
+	params BcryptParams
+}
+
+func newBcrypt(params BcryptParams) *bcryptHasher {
+	if params.Cost == 0 {
+		params.Cost = bcrypt.DefaultCost
+	}
+
+	return &bcryptHasher{
+		params: params,
+	}
+}
+
+// hash is encoded as $2a$<cost>$<salt and key>
+func (h *bcryptHasher) hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.params.Cost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+func (h *bcryptHasher) verify(hash, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (h *bcryptHasher) outdated(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+
+	return err != nil || cost != h.params.Cost
+}
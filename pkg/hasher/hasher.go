@@ -0,0 +1,95 @@
+// Package hasher hash passwords with bcrypt or Argon2id. The hashes are encoded
+// with their algorithm and parameters, so a hash made by an older configuration
+// still verifies, and can be told apart to be rehashed.
+package hasher
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	BCRYPT   = "bcrypt"
+	ARGON2ID = "argon2id"
+)
+
+var ErrUnknownHash = errors.New("unknown password hash format")
+
+type Hasher interface {
+	// Hash encode the password with the configured algorithm and parameters
+	Hash(password string) (string, error)
+	// Verify compare the password with a hash of any supported algorithm
+	Verify(hash, password string) (bool, error)
+	// NeedsRehash report whether the hash uses another algorithm or other parameters than Hash
+	NeedsRehash(hash string) bool
+}
+
+// Config select the algorithm of new hashes, the parameters of both algorithms
+// are kept to tell whether an existing hash is outdated. A zero parameter takes its default.
+type Config struct {
+	Algorithm string
+	Bcrypt    BcryptParams
+	Argon2id  Argon2idParams
+}
+
+type algorithm interface {
+	hash(password string) (string, error)
+	verify(hash, password string) (bool, error)
+	// outdated report whether the hash was made with other parameters
+	outdated(hash string) bool
+}
+
+type hasher struct {
+	current    string
+	algorithms map[string]algorithm
+}
+
+func New(cfg Config) (Hasher, error) {
+	h := &hasher{
+		current: cfg.Algorithm,
+		algorithms: map[string]algorithm{
+			BCRYPT:   newBcrypt(cfg.Bcrypt),
+			ARGON2ID: newArgon2id(cfg.Argon2id),
+		},
+	}
+
+	if _, ok := h.algorithms[h.current]; !ok {
+		return nil, fmt.Errorf("unsupported password hashing algorithm %q", cfg.Algorithm)
+	}
+
+	return h, nil
+}
+
+func (h *hasher) Hash(password string) (string, error) {
+	return h.algorithms[h.current].hash(password)
+}
+
+func (h *hasher) Verify(hash, password string) (bool, error) {
+	algorithm, ok := h.algorithms[identify(hash)]
+	if !ok {
+		return false, ErrUnknownHash
+	}
+
+	return algorithm.verify(hash, password)
+}
+
+func (h *hasher) NeedsRehash(hash string) bool {
+	if identify(hash) != h.current {
+		return true
+	}
+
+	return h.algorithms[h.current].outdated(hash)
+}
+
+// identify return the algorithm of the hash from its prefix
+func identify(hash string) string {
+	switch {
+	case strings.HasPrefix(hash, "$argon2id$"):
+		return ARGON2ID
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		return BCRYPT
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,265 @@
+package hasher
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// small parameters keep the tests fast
+var (
+	testBcrypt   = BcryptParams{Cost: bcrypt.MinCost}
+	testArgon2id = Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 8, KeyLength: 16}
+)
+
+func newTestHasher(t *testing.T, algorithm string, bcryptParams BcryptParams, argon2idParams Argon2idParams) Hasher {
+	h, err := New(Config{Algorithm: algorithm, Bcrypt: bcryptParams, Argon2id: argon2idParams})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return h
+}
+
+func mustHash(t *testing.T, h Hasher, password string) string {
+	hash, err := h.Hash(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return hash
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm string
+		wantErr   bool
+	}{
+		{name: "Success Bcrypt", algorithm: BCRYPT},
+		{name: "Success Argon2id", algorithm: ARGON2ID},
+		{name: "Failed Unsupported Algorithm", algorithm: "md5", wantErr: true},
+		{name: "Failed Without Algorithm", algorithm: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(Config{Algorithm: tt.algorithm}); (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestArgon2idEncoding(t *testing.T) {
+	h := newArgon2id(testArgon2id)
+	hash, err := h.hash("Password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Errorf("argon2idHasher.hash() = %s, want the PHC string of the parameters", hash)
+	}
+
+	params, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		t.Fatalf("decodeArgon2id() error = %v", err)
+	}
+	if params != testArgon2id {
+		t.Errorf("decodeArgon2id() params = %+v, want %+v", params, testArgon2id)
+	}
+	if len(salt) != 8 || len(key) != 16 {
+		t.Errorf("decodeArgon2id() salt length = %d, key length = %d, want 8 and 16", len(salt), len(key))
+	}
+
+	tests := []struct {
+		name string
+		hash string
+	}{
+		{name: "Failed Missing Part", hash: "$argon2id$v=19$m=1024,t=1,p=1$c2FsdHNhbHQ"},
+		{name: "Failed Other Algorithm", hash: "$argon2i$v=19$m=1024,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5a2V5a2V5a2V5aw"},
+		{name: "Failed Other Version", hash: "$argon2id$v=16$m=1024,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5a2V5a2V5a2V5aw"},
+		{name: "Failed Invalid Parameters", hash: "$argon2id$v=19$m=a,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5a2V5a2V5a2V5aw"},
+		{name: "Failed Zero Iterations", hash: "$argon2id$v=19$m=1024,t=0,p=1$c2FsdHNhbHQ$a2V5a2V5a2V5a2V5a2V5aw"},
+		{name: "Failed Invalid Salt", hash: "$argon2id$v=19$m=1024,t=1,p=1$not*base64$a2V5a2V5a2V5a2V5a2V5aw"},
+		{name: "Failed Invalid Key", hash: "$argon2id$v=19$m=1024,t=1,p=1$c2FsdHNhbHQ$not*base64"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, _, err := decodeArgon2id(tt.hash); err == nil {
+				t.Errorf("decodeArgon2id(%s) should fail", tt.hash)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	bcryptHasher := newTestHasher(t, BCRYPT, testBcrypt, testArgon2id)
+	argon2idHasher := newTestHasher(t, ARGON2ID, testBcrypt, testArgon2id)
+
+	bcryptHash := mustHash(t, bcryptHasher, "Password123")
+	argon2idHash := mustHash(t, argon2idHasher, "Password123")
+	// made with other parameters than the verifying hasher
+	otherArgon2idHash := mustHash(t, newTestHasher(t, ARGON2ID, testBcrypt, Argon2idParams{Memory: 2048, Iterations: 2, Parallelism: 1}), "Password123")
+
+	tests := []struct {
+		name     string
+		hasher   Hasher
+		hash     string
+		password string
+		want     bool
+		wantErr  error
+	}{
+		{
+			name:     "Success Bcrypt Hash",
+			hasher:   bcryptHasher,
+			hash:     bcryptHash,
+			password: "Password123",
+			want:     true,
+		},
+		{
+			name:     "Success Argon2id Hash",
+			hasher:   argon2idHasher,
+			hash:     argon2idHash,
+			password: "Password123",
+			want:     true,
+		},
+		{
+			name:     "Success Bcrypt Hash By Argon2id Hasher",
+			hasher:   argon2idHasher,
+			hash:     bcryptHash,
+			password: "Password123",
+			want:     true,
+		},
+		{
+			name:     "Success Argon2id Hash By Bcrypt Hasher",
+			hasher:   bcryptHasher,
+			hash:     argon2idHash,
+			password: "Password123",
+			want:     true,
+		},
+		{
+			name:     "Success Argon2id Hash With Other Parameters",
+			hasher:   argon2idHasher,
+			hash:     otherArgon2idHash,
+			password: "Password123",
+			want:     true,
+		},
+		{
+			name:     "Failed Wrong Password Bcrypt",
+			hasher:   argon2idHasher,
+			hash:     bcryptHash,
+			password: "Password124",
+			want:     false,
+		},
+		{
+			name:     "Failed Wrong Password Argon2id",
+			hasher:   bcryptHasher,
+			hash:     argon2idHash,
+			password: "Password124",
+			want:     false,
+		},
+		{
+			name:     "Failed Unknown Hash",
+			hasher:   argon2idHasher,
+			hash:     "5f4dcc3b5aa765d61d8327deb882cf99",
+			password: "Password123",
+			wantErr:  ErrUnknownHash,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.hasher.Verify(tt.hash, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("hasher.Verify() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("hasher.Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	bcryptHasher := newTestHasher(t, BCRYPT, testBcrypt, testArgon2id)
+	argon2idHasher := newTestHasher(t, ARGON2ID, testBcrypt, testArgon2id)
+
+	bcryptHash := mustHash(t, bcryptHasher, "Password123")
+	argon2idHash := mustHash(t, argon2idHasher, "Password123")
+
+	// the default parameters, written out or left zero, are the same
+	defaultArgon2id := Argon2idParams{Memory: 64 * 1024, Iterations: 3, Parallelism: 2, SaltLength: 16, KeyLength: 32}
+	defaultHash := mustHash(t, newTestHasher(t, ARGON2ID, testBcrypt, Argon2idParams{}), "Password123")
+
+	tests := []struct {
+		name   string
+		hasher Hasher
+		hash   string
+		want   bool
+	}{
+		{
+			name:   "Same Argon2id Parameters",
+			hasher: argon2idHasher,
+			hash:   argon2idHash,
+			want:   false,
+		},
+		{
+			name:   "Same Bcrypt Cost",
+			hasher: bcryptHasher,
+			hash:   bcryptHash,
+			want:   false,
+		},
+		{
+			name:   "Default Argon2id Parameters",
+			hasher: newTestHasher(t, ARGON2ID, testBcrypt, defaultArgon2id),
+			hash:   defaultHash,
+			want:   false,
+		},
+		{
+			name:   "Other Argon2id Memory",
+			hasher: newTestHasher(t, ARGON2ID, testBcrypt, Argon2idParams{Memory: 2048, Iterations: 1, Parallelism: 1, SaltLength: 8, KeyLength: 16}),
+			hash:   argon2idHash,
+			want:   true,
+		},
+		{
+			name:   "Other Argon2id Key Length",
+			hasher: newTestHasher(t, ARGON2ID, testBcrypt, Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 8, KeyLength: 32}),
+			hash:   argon2idHash,
+			want:   true,
+		},
+		{
+			name:   "Other Bcrypt Cost",
+			hasher: newTestHasher(t, BCRYPT, BcryptParams{Cost: bcrypt.MinCost + 1}, testArgon2id),
+			hash:   bcryptHash,
+			want:   true,
+		},
+		{
+			name:   "Bcrypt Hash With Argon2id Configured",
+			hasher: argon2idHasher,
+			hash:   bcryptHash,
+			want:   true,
+		},
+		{
+			name:   "Argon2id Hash With Bcrypt Configured",
+			hasher: bcryptHasher,
+			hash:   argon2idHash,
+			want:   true,
+		},
+		{
+			name:   "Unknown Hash",
+			hasher: argon2idHasher,
+			hash:   "5f4dcc3b5aa765d61d8327deb882cf99",
+			want:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.hasher.NeedsRehash(tt.hash); got != tt.want {
+				t.Errorf("hasher.NeedsRehash() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,403 @@
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// NUMBER_PRECISION is the bits of mantissa numbers are compared with, beyond float64 and int64
+const NUMBER_PRECISION = 256
+
+// ErrTestFailed is returned when the value of a test operation differs from the document
+var ErrTestFailed = errors.New("test operation failed")
+
+// ApplyPatch apply a JSON Patch (RFC 6902) to the document, the operations
+// are applied in order and the document is left unchanged when one of them fails
+func ApplyPatch(doc, patch []byte) ([]byte, error) {
+	target, err := decode(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid document, %s", err.Error())
+	}
+
+	rawOperations := []json.RawMessage{}
+	if err := json.Unmarshal(patch, &rawOperations); err != nil {
+		return nil, fmt.Errorf("invalid json patch, %s", err.Error())
+	}
+
+	for i, rawOperation := range rawOperations {
+		operation, err := decodeOperation(rawOperation)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d, invalid json patch, %s", i, err.Error())
+		}
+
+		target, err = apply(target, operation)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d, %w", i, err)
+		}
+	}
+
+	return encode(target)
+}
+
+func apply(doc interface{}, operation map[string]json.RawMessage) (interface{}, error) {
+	var op string
+	if err := json.Unmarshal(operation["op"], &op); err != nil {
+		return nil, errors.New("op is missing")
+	}
+
+	path, err := pointerMember(operation, "path")
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "add", "replace", "test":
+		raw, ok := operation["value"]
+		if !ok {
+			return nil, errors.New("value is missing")
+		}
+
+		value, err := decode(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value, %s", err.Error())
+		}
+
+		switch op {
+		case "add":
+			return add(doc, path, value)
+		case "replace":
+			return replace(doc, path, value)
+		}
+
+		current, err := get(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !equal(current, value) {
+			return nil, ErrTestFailed
+		}
+
+		return doc, nil
+	case "remove":
+		doc, _, err := remove(doc, path)
+		return doc, err
+	case "move", "copy":
+		from, err := pointerMember(operation, "from")
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+
+		if op == "copy" {
+			// the copy must not share maps and slices with the original
+			data, _ := encode(value)
+			value, _ = decode(data)
+			return add(doc, path, value)
+		}
+
+		if len(path) > len(from) && reflect.DeepEqual(path[:len(from)], from) {
+			return nil, errors.New("a value can't be moved into one of its children")
+		}
+
+		doc, _, err = remove(doc, from)
+		if err != nil {
+			return nil, err
+		}
+
+		return add(doc, path, value)
+	default:
+		return nil, fmt.Errorf("unknown op %s", op)
+	}
+}
+
+// decodeOperation keep the members of the operation raw, a null value must be told apart from
+// a missing one. A member given twice is refused, it would be ambiguous (RFC 6902 A.13)
+func decodeOperation(data []byte) (map[string]json.RawMessage, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, errors.New("an operation must be an object")
+	}
+
+	operation := map[string]json.RawMessage{}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		member := token.(string)
+		if _, ok := operation[member]; ok {
+			return nil, fmt.Errorf("member %s given twice", member)
+		}
+
+		value := json.RawMessage{}
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		operation[member] = value
+	}
+
+	return operation, nil
+}
+
+// equal compare decoded values like JSON does, numbers by their value whatever their
+// notation, e.g. 1, 1.0 and 1e0 are equal
+func equal(a, b interface{}) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+
+		// a float keeps a huge exponent cheap, unlike an exact rational
+		x, _, errA := big.ParseFloat(string(a), 10, NUMBER_PRECISION, big.ToNearestEven)
+		y, _, errB := big.ParseFloat(string(b), 10, NUMBER_PRECISION, big.ToNearestEven)
+
+		return errA == nil && errB == nil && x.Cmp(y) == 0
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+
+		for name, value := range a {
+			other, ok := b[name]
+			if !ok || !equal(value, other) {
+				return false
+			}
+		}
+
+		return true
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+
+		for i := range a {
+			if !equal(a[i], b[i]) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return a == b
+	}
+}
+
+// add set the value at the path, a member of an object is replaced, an element
+// of an array is inserted before the index, or appended with the index -
+func add(node interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	token := path[0]
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if len(path) == 1 {
+			n[token] = value
+			return n, nil
+		}
+
+		child, ok := n[token]
+		if !ok {
+			return nil, fmt.Errorf("member %s not found", token)
+		}
+
+		child, err := add(child, path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		n[token] = child
+
+		return n, nil
+	case []interface{}:
+		if len(path) == 1 {
+			index := len(n)
+			if token != "-" {
+				var err error
+				if index, err = arrayIndex(token, len(n)); err != nil {
+					return nil, err
+				}
+			}
+
+			n = append(n, nil)
+			copy(n[index+1:], n[index:])
+			n[index] = value
+
+			return n, nil
+		}
+
+		index, err := arrayIndex(token, len(n)-1)
+		if err != nil {
+			return nil, err
+		}
+
+		child, err := add(n[index], path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		n[index] = child
+
+		return n, nil
+	default:
+		return nil, fmt.Errorf("%s is not a member of an object or an array", token)
+	}
+}
+
+// remove delete the value at the path, which must exist, and return it
+func remove(node interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, errors.New("the whole document can't be removed")
+	}
+
+	token := path[0]
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, ok := n[token]
+		if !ok {
+			return nil, nil, fmt.Errorf("member %s not found", token)
+		}
+
+		if len(path) == 1 {
+			delete(n, token)
+			return n, child, nil
+		}
+
+		child, removed, err := remove(child, path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		n[token] = child
+
+		return n, removed, nil
+	case []interface{}:
+		index, err := arrayIndex(token, len(n)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(path) == 1 {
+			removed := n[index]
+			return append(n[:index], n[index+1:]...), removed, nil
+		}
+
+		child, removed, err := remove(n[index], path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		n[index] = child
+
+		return n, removed, nil
+	default:
+		return nil, nil, fmt.Errorf("%s is not a member of an object or an array", token)
+	}
+}
+
+// replace set the value at the path, which must exist
+func replace(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	doc, _, err := remove(doc, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return add(doc, path, value)
+}
+
+func get(node interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			child, ok := n[token]
+			if !ok {
+				return nil, fmt.Errorf("member %s not found", token)
+			}
+			node = child
+		case []interface{}:
+			index, err := arrayIndex(token, len(n)-1)
+			if err != nil {
+				return nil, err
+			}
+			node = n[index]
+		default:
+			return nil, fmt.Errorf("%s is not a member of an object or an array", token)
+		}
+	}
+
+	return node, nil
+}
+
+// pointerMember parse the JSON Pointer (RFC 6901) of the operation member into its reference tokens
+func pointerMember(operation map[string]json.RawMessage, member string) ([]string, error) {
+	var pointer string
+	if err := json.Unmarshal(operation[member], &pointer); err != nil {
+		return nil, fmt.Errorf("%s is missing", member)
+	}
+
+	if pointer == "" {
+		return []string{}, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%s %s must start with /", member, pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+// arrayIndex parse an index of an array, from 0 to max
+func arrayIndex(token string, max int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (len(token) > 1 && token[0] == '0') || strings.HasPrefix(token, "+") {
+		return 0, fmt.Errorf("invalid array index %s", token)
+	}
+
+	if index > max {
+		return 0, fmt.Errorf("array index %s out of bounds", token)
+	}
+
+	return index, nil
+}
+
+// decode keep the numbers as they are written, so that they are not rounded through float64
+func decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	if decoder.More() {
+		return nil, errors.New("unexpected data after the value")
+	}
+
+	return value, nil
+}
+
+func encode(value interface{}) ([]byte, error) {
+	return json.Marshal(value)
+}
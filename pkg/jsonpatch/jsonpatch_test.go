@@ -0,0 +1,198 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestApplyPatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		patch   string
+		want    string
+		wantErr error
+	}{
+		// examples of RFC 6902 appendix A
+		{
+			name:  "A.1 Adding an Object Member",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/baz", "value": "qux"}]`,
+			want:  `{"baz": "qux", "foo": "bar"}`,
+		},
+		{
+			name:  "A.2 Adding an Array Element",
+			doc:   `{"foo": ["bar", "baz"]}`,
+			patch: `[{"op": "add", "path": "/foo/1", "value": "qux"}]`,
+			want:  `{"foo": ["bar", "qux", "baz"]}`,
+		},
+		{
+			name:  "A.3 Removing an Object Member",
+			doc:   `{"baz": "qux", "foo": "bar"}`,
+			patch: `[{"op": "remove", "path": "/baz"}]`,
+			want:  `{"foo": "bar"}`,
+		},
+		{
+			name:  "A.4 Removing an Array Element",
+			doc:   `{"foo": ["bar", "qux", "baz"]}`,
+			patch: `[{"op": "remove", "path": "/foo/1"}]`,
+			want:  `{"foo": ["bar", "baz"]}`,
+		},
+		{
+			name:  "A.5 Replacing a Value",
+			doc:   `{"baz": "qux", "foo": "bar"}`,
+			patch: `[{"op": "replace", "path": "/baz", "value": "boo"}]`,
+			want:  `{"baz": "boo", "foo": "bar"}`,
+		},
+		{
+			name:  "A.6 Moving a Value",
+			doc:   `{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+			patch: `[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+			want:  `{"foo": {"bar": "baz"}, "qux": {"corge": "grault", "thud": "fred"}}`,
+		},
+		{
+			name:  "A.7 Moving an Array Element",
+			doc:   `{"foo": ["all", "grass", "cows", "eat"]}`,
+			patch: `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`,
+			want:  `{"foo": ["all", "cows", "eat", "grass"]}`,
+		},
+		{
+			name:  "A.8 Testing a Value: Success",
+			doc:   `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+			patch: `[{"op": "test", "path": "/baz", "value": "qux"}, {"op": "test", "path": "/foo/1", "value": 2}]`,
+			want:  `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+		},
+		{
+			name:    "A.9 Testing a Value: Error",
+			doc:     `{"baz": "qux"}`,
+			patch:   `[{"op": "test", "path": "/baz", "value": "bar"}]`,
+			wantErr: ErrTestFailed,
+		},
+		{
+			name:  "A.10 Adding a Nested Member Object",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/child", "value": {"grandchild": {}}}]`,
+			want:  `{"foo": "bar", "child": {"grandchild": {}}}`,
+		},
+		{
+			name:  "A.11 Ignoring Unrecognized Elements",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/baz", "value": "qux", "xyz": 123}]`,
+			want:  `{"foo": "bar", "baz": "qux"}`,
+		},
+		{
+			name:    "A.12 Adding to a Nonexistent Target",
+			doc:     `{"foo": "bar"}`,
+			patch:   `[{"op": "add", "path": "/baz/bat", "value": "qux"}]`,
+			wantErr: errAny,
+		},
+		{
+			name:    "A.13 Invalid JSON Patch Document",
+			doc:     `{"baz": "qux"}`,
+			patch:   `[{"op": "add", "path": "/baz", "value": "qux", "op": "remove"}]`,
+			wantErr: errAny,
+		},
+		{
+			name:  "A.14 ~ Escape Ordering",
+			doc:   `{"/": 9, "~1": 10}`,
+			patch: `[{"op": "test", "path": "/~01", "value": 10}]`,
+			want:  `{"/": 9, "~1": 10}`,
+		},
+		{
+			name:    "A.15 Comparing Strings and Numbers",
+			doc:     `{"/": 9, "~1": 10}`,
+			patch:   `[{"op": "test", "path": "/~01", "value": "10"}]`,
+			wantErr: ErrTestFailed,
+		},
+		{
+			name:  "A.16 Adding an Array Value",
+			doc:   `{"foo": ["bar"]}`,
+			patch: `[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`,
+			want:  `{"foo": ["bar", ["abc", "def"]]}`,
+		},
+		// numbers are equal by value, not by notation
+		{
+			name:  "Success Test Number In Another Notation",
+			doc:   `{"a": 1, "b": [100, {"c": 0.5}]}`,
+			patch: `[{"op": "test", "path": "/a", "value": 1.0}, {"op": "test", "path": "/b", "value": [1e2, {"c": 5E-1}]}]`,
+			want:  `{"a": 1, "b": [100, {"c": 0.5}]}`,
+		},
+		{
+			name:  "Success Test Number Beyond Float64 Precision",
+			doc:   `{"a": 9007199254740993}`,
+			patch: `[{"op": "test", "path": "/a", "value": 9007199254740993.0}]`,
+			want:  `{"a": 9007199254740993}`,
+		},
+		{
+			name:    "Failed Test Different Number",
+			doc:     `{"a": 9007199254740993}`,
+			patch:   `[{"op": "test", "path": "/a", "value": 9007199254740992}]`,
+			wantErr: ErrTestFailed,
+		},
+		{
+			name:    "Failed Test Number With Huge Exponent",
+			doc:     `{"a": 1}`,
+			patch:   `[{"op": "test", "path": "/a", "value": 1e1000000000}]`,
+			wantErr: ErrTestFailed,
+		},
+		{
+			name:    "Failed Test Object With Another Member",
+			doc:     `{"a": {"b": 1}}`,
+			patch:   `[{"op": "test", "path": "/a", "value": {"b": 1, "c": 2}}]`,
+			wantErr: ErrTestFailed,
+		},
+		{
+			name:    "Failed Remove Whole Document",
+			doc:     `{"a": 1}`,
+			patch:   `[{"op": "remove", "path": ""}]`,
+			wantErr: errAny,
+		},
+		{
+			name:    "Failed Move Into A Child",
+			doc:     `{"a": {"b": 1}}`,
+			patch:   `[{"op": "move", "from": "/a", "path": "/a/c"}]`,
+			wantErr: errAny,
+		},
+		{
+			name:    "Failed Array Index With Leading Zero",
+			doc:     `{"a": [1, 2]}`,
+			patch:   `[{"op": "remove", "path": "/a/01"}]`,
+			wantErr: errAny,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyPatch([]byte(tt.doc), []byte(tt.patch))
+			if tt.wantErr != nil {
+				if err == nil || (tt.wantErr != errAny && !errors.Is(err, tt.wantErr)) {
+					t.Errorf("ApplyPatch() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("ApplyPatch() error = %v", err)
+				return
+			}
+			if !jsonEqual(t, got, []byte(tt.want)) {
+				t.Errorf("ApplyPatch() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// errAny is the wantErr of a failure whatever its error
+var errAny = errors.New("any error")
+
+func jsonEqual(t *testing.T, a, b []byte) bool {
+	var x, y interface{}
+	if err := json.Unmarshal(a, &x); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, &y); err != nil {
+		t.Fatal(err)
+	}
+
+	return reflect.DeepEqual(x, y)
+}
@@ -0,0 +1,44 @@
+package jsonpatch
+
+import (
+	"fmt"
+)
+
+// MergePatch apply a JSON Merge Patch (RFC 7396) to the document. A null member of
+// the patch removes the member of the document, a patch other than an object replaces it
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	target, err := decode(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid document, %s", err.Error())
+	}
+
+	mergePatch, err := decode(patch)
+	if err != nil {
+		return nil, fmt.Errorf("invalid merge patch, %s", err.Error())
+	}
+
+	return encode(merge(target, mergePatch))
+}
+
+func merge(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+			continue
+		}
+
+		targetObject[name] = merge(targetObject[name], value)
+	}
+
+	return targetObject
+}
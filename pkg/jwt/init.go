@@ -1,23 +1,123 @@
 package jwt
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/adesupraptolaia/user_login/config"
 	"github.com/golang-jwt/jwt"
 )
 
+// the HMAC secret of refresh tokens is at least as long as the SHA-256 output
+const MIN_SECRET_LENGTH = 32
+
 var (
-	ACCESS_TOKEN_SECRET  []byte
-	REFRESH_TOKEN_SECRET []byte
+	ISSUER = "auth-app"
+
+	// audience of each service accepting tokens
+	AUTH_PUBLIC_AUDIENCE  = "auth-public"
+	AUTH_PRIVATE_AUDIENCE = "auth-private"
+	USER_APP_AUDIENCE     = "user-app"
+
+	// access token is signed with the asymmetric keys loaded by LoadSigningKeys,
+	// refresh token with the HMAC secret, both only in auth app
+	accessTokenKeys  = &keyRing{ttl: ACCESS_TOKEN_TTL}
+	refreshTokenKeys = &keyRing{ttl: REFRESH_TOKEN_TTL}
 )
 
 type Claims struct {
 	UserKsuid string `json:"user_ksuid"`
 	Role      string `json:"role"`
+	TokenUse  string `json:"token_use,omitempty"`
+	// session of the login, the token is inactive once the session is ended
+	SessionKsuid string `json:"sid,omitempty"`
+	// address to verify, only in email verification token
+	Email string `json:"email,omitempty"`
+	// permissions granted to the role when the token was issued
+	Permissions []string `json:"permissions,omitempty"`
+	// shadows StandardClaims.Audience, a token can be issued for several services
+	Audience []string `json:"aud,omitempty"`
 	jwt.StandardClaims
 }
 
+// HasPermission report whether the role of the token was granted the permission
+func (c *Claims) HasPermission(permission string) bool {
+	for _, p := range c.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasAudience report whether the token was issued for the audience
+func (c *Claims) HasAudience(audience string) bool {
+	for _, aud := range c.Audience {
+		if aud == audience {
+			return true
+		}
+	}
+
+	return false
+}
+
+// KeyFile is a PEM private key signing access tokens, e.g. mounted from a secret
+type KeyFile struct {
+	Algorithm      string
+	PrivateKeyFile string
+}
+
+// LoadSigningKeys load the keys signing access tokens, the first one signs new tokens,
+// and the HMAC secret of refresh tokens. It fails when any of them can't be used,
+// a service only verifying access tokens calls UseJWKS instead.
+func LoadSigningKeys(keyFiles []KeyFile, refreshTokenSecret []byte) error {
+	if len(keyFiles) == 0 {
+		return errors.New("no access token signing key")
+	}
+
+	if len(refreshTokenSecret) < MIN_SECRET_LENGTH {
+		return fmt.Errorf("refresh token secret must be at least %d bytes", MIN_SECRET_LENGTH)
+	}
+
+	keys := []signingKey{}
+	for _, keyFile := range keyFiles {
+		key, err := loadSigningKey(keyFile.Algorithm, keyFile.PrivateKeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load jwt signing key %s: %w", keyFile.PrivateKeyFile, err)
+		}
+		keys = append(keys, key)
+	}
+
+	accessTokenKeys.mu.Lock()
+	accessTokenKeys.configKeys = keys
+	accessTokenKeys.mu.Unlock()
+
+	refreshTokenKeys.mu.Lock()
+	refreshTokenKeys.configKeys = []signingKey{newHMACKey(refreshTokenSecret)}
+	refreshTokenKeys.mu.Unlock()
+
+	return nil
+}
+
 func init() {
 	cfg := config.Config
-	ACCESS_TOKEN_SECRET = []byte(cfg.Secret.AccessToken)
-	REFRESH_TOKEN_SECRET = []byte(cfg.Secret.RefreshToken)
+
+	if cfg.Jwt.Issuer != "" {
+		ISSUER = cfg.Jwt.Issuer
+	}
+	if cfg.Jwt.Audience.AuthPublic != "" {
+		AUTH_PUBLIC_AUDIENCE = cfg.Jwt.Audience.AuthPublic
+	}
+	if cfg.Jwt.Audience.AuthPrivate != "" {
+		AUTH_PRIVATE_AUDIENCE = cfg.Jwt.Audience.AuthPrivate
+	}
+	if cfg.Jwt.Audience.UserApp != "" {
+		USER_APP_AUDIENCE = cfg.Jwt.Audience.UserApp
+	}
+
+	if cfg.Jwt.JwksCacheTTL > 0 {
+		jwksCacheTTL = time.Duration(cfg.Jwt.JwksCacheTTL) * time.Second
+	}
 }
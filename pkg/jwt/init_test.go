@@ -0,0 +1,70 @@
+package jwt
+
+import (
+	"os"
+	"testing"
+)
+
+func TestLoadSigningKeys(t *testing.T) {
+	dir := t.TempDir()
+
+	keyFile, err := WriteEd25519Key(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	invalidKeyFile := dir + "/invalid.pem"
+	if err := os.WriteFile(invalidKeyFile, []byte("not a key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	secret := []byte("refresh_token_secret_of_the_tests")
+
+	type args struct {
+		keyFiles           []KeyFile
+		refreshTokenSecret []byte
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name:    "Failed No Signing Key",
+			args:    args{nil, secret},
+			wantErr: true,
+		},
+		{
+			name:    "Failed Missing Key File",
+			args:    args{[]KeyFile{{Algorithm: "EdDSA", PrivateKeyFile: dir + "/missing.pem"}}, secret},
+			wantErr: true,
+		},
+		{
+			name:    "Failed Invalid Key File",
+			args:    args{[]KeyFile{{Algorithm: "EdDSA", PrivateKeyFile: keyFile}, {Algorithm: "EdDSA", PrivateKeyFile: invalidKeyFile}}, secret},
+			wantErr: true,
+		},
+		{
+			name:    "Failed Algorithm Of Another Key Type",
+			args:    args{[]KeyFile{{Algorithm: "RS256", PrivateKeyFile: keyFile}}, secret},
+			wantErr: true,
+		},
+		{
+			name:    "Failed Short Refresh Token Secret",
+			args:    args{[]KeyFile{{Algorithm: "EdDSA", PrivateKeyFile: keyFile}}, []byte("secret")},
+			wantErr: true,
+		},
+		{
+			name:    "Success Load Signing Keys",
+			args:    args{[]KeyFile{{Algorithm: "EdDSA", PrivateKeyFile: keyFile}}, secret},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := LoadSigningKeys(tt.args.keyFiles, tt.args.refreshTokenSecret); (err != nil) != tt.wantErr {
+				t.Errorf("LoadSigningKeys() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
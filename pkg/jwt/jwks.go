@@ -0,0 +1,233 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// JWK is a public key in JSON Web Key format (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+var (
+	jwksCacheTTL = 5 * time.Minute
+
+	// when set, access tokens are verified using keys fetched from auth app
+	remoteKeys *jwksCache
+)
+
+// PublicJWKS return public keys that verify access tokens
+func PublicJWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+
+	for _, key := range accessTokenKeys.verificationKeys() {
+		// HMAC secret is never published
+		jwk, err := toJWK(key.kid, key.method.Alg(), key.publicKey)
+		if err != nil {
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	return jwks
+}
+
+// UseJWKS verify access tokens only with public keys published at jwksURL,
+// so the service doesn't need to hold the signing key
+func UseJWKS(jwksURL string) {
+	remoteKeys = &jwksCache{
+		url:    jwksURL,
+		client: &http.Client{Timeout: 5 * time.Second},
+		keys:   []signingKey{},
+	}
+}
+
+func toJWK(kid, alg string, publicKey crypto.PublicKey) (JWK, error) {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			Alg: alg,
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Kid: kid,
+			Use: "sig",
+			Alg: alg,
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(key),
+		}, nil
+	}
+
+	return JWK{}, fmt.Errorf("unsupported public key type %T", publicKey)
+}
+
+func fromJWK(jwk JWK) (signingKey, error) {
+	method := jwt.GetSigningMethod(jwk.Alg)
+	if method == nil {
+		return signingKey{}, fmt.Errorf("unsupported algorithm %s", jwk.Alg)
+	}
+
+	switch jwk.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return signingKey{}, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return signingKey{}, err
+		}
+		return signingKey{
+			kid:       jwk.Kid,
+			method:    method,
+			publicKey: &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())},
+		}, nil
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return signingKey{}, err
+		}
+		if jwk.Crv != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return signingKey{}, fmt.Errorf("invalid Ed25519 key %s", jwk.Kid)
+		}
+		return signingKey{
+			kid:       jwk.Kid,
+			method:    method,
+			publicKey: ed25519.PublicKey(x),
+		}, nil
+	}
+
+	return signingKey{}, fmt.Errorf("unsupported key type %s", jwk.Kty)
+}
+
+type jwksCache struct {
+	url    string
+	client *http.Client
+	// one fetch at a time, tokens of known keys are verified meanwhile
+	fetchMu     sync.Mutex
+	mu          sync.RWMutex
+	keys        []signingKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+}
+
+// keyFunc refetch the key set when the cache is expired or the kid is unknown,
+// the auth app is called at most once every 10 seconds
+func (c *jwksCache) keyFunc(token *jwt.Token) (interface{}, error) {
+	keys, fetchedAt := c.cached()
+
+	if time.Since(fetchedAt) > jwksCacheTTL {
+		keys, _ = c.refresh(fetchedAt)
+	}
+
+	key, err := keyFunc(keys, token)
+	if err != nil {
+		if keys, refreshed := c.refresh(fetchedAt); refreshed {
+			key, err = keyFunc(keys, token)
+		}
+	}
+
+	return key, err
+}
+
+func (c *jwksCache) cached() ([]signingKey, time.Time) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.keys, c.fetchedAt
+}
+
+// refresh fetch the key set unless it has been fetched after seen, by another caller
+// while this one was waiting, or the last attempt is less than 10 seconds ago.
+// It reports whether the returned keys are newer than seen.
+func (c *jwksCache) refresh(seen time.Time) ([]signingKey, bool) {
+	c.fetchMu.Lock()
+	defer c.fetchMu.Unlock()
+
+	c.mu.RLock()
+	keys, fetchedAt, attemptedAt := c.keys, c.fetchedAt, c.attemptedAt
+	c.mu.RUnlock()
+
+	if fetchedAt.After(seen) {
+		return keys, true
+	}
+
+	if time.Since(attemptedAt) < 10*time.Second {
+		return keys, false
+	}
+
+	c.mu.Lock()
+	c.attemptedAt = time.Now()
+	c.mu.Unlock()
+
+	fetched, err := c.fetch()
+	if err != nil {
+		log.Printf("failed to fetch jwks from %s: %v", c.url, err)
+		return keys, false
+	}
+
+	c.mu.Lock()
+	c.keys = fetched
+	c.fetchedAt = time.Now()
+	c.mu.Unlock()
+
+	return fetched, true
+}
+
+func (c *jwksCache) fetch() ([]signingKey, error) {
+	resp, err := c.client.Get(c.url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	jwks := JWKS{}
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return nil, err
+	}
+
+	keys := []signingKey{}
+	for _, jwk := range jwks.Keys {
+		key, err := fromJWK(jwk)
+		if err != nil {
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
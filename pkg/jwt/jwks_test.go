@@ -0,0 +1,167 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// jwksServer publish the public keys of the rings, the handler waits while blocked is set
+type jwksServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	keys    []signingKey
+	fetches int32
+	blocked chan struct{}
+}
+
+func newJWKSServer(t *testing.T, keys ...signingKey) *jwksServer {
+	s := &jwksServer{keys: keys}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&s.fetches, 1)
+
+		s.mu.Lock()
+		keys, blocked := s.keys, s.blocked
+		s.mu.Unlock()
+
+		if blocked != nil {
+			<-blocked
+		}
+
+		jwks := JWKS{Keys: []JWK{}}
+		for _, key := range keys {
+			jwk, _ := toJWK(key.kid, key.method.Alg(), key.publicKey)
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+		json.NewEncoder(w).Encode(jwks)
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *jwksServer) publish(keys ...signingKey) {
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+}
+
+func (s *jwksServer) cache() *jwksCache {
+	return &jwksCache{url: s.URL, client: s.Client(), keys: []signingKey{}}
+}
+
+func newEd25519Key(t *testing.T, kid string) signingKey {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signingKey{kid: kid, method: jwt.SigningMethodEdDSA, privateKey: privateKey, publicKey: publicKey}
+}
+
+func signedToken(t *testing.T, key signingKey) *jwt.Token {
+	token := jwt.New(key.method)
+	token.Header["kid"] = key.kid
+
+	return token
+}
+
+func TestJWKSCacheRefresh(t *testing.T) {
+	oldKey := newEd25519Key(t, "old")
+	newKey := newEd25519Key(t, "new")
+
+	server := newJWKSServer(t, oldKey)
+	cache := server.cache()
+
+	if _, err := cache.keyFunc(signedToken(t, oldKey)); err != nil {
+		t.Fatalf("keyFunc() of published key error = %v", err)
+	}
+	if _, err := cache.keyFunc(signedToken(t, oldKey)); err != nil {
+		t.Fatalf("keyFunc() of cached key error = %v", err)
+	}
+	if fetches := atomic.LoadInt32(&server.fetches); fetches != 1 {
+		t.Errorf("keyFunc() fetched %d times, want the cached key set", fetches)
+	}
+
+	// a rotated key is picked up on the first token it signs, at most once every 10 seconds
+	server.publish(newKey, oldKey)
+	if _, err := cache.keyFunc(signedToken(t, newKey)); err == nil {
+		t.Errorf("keyFunc() of a new key should wait 10 seconds after the last fetch")
+	}
+
+	cache.attemptedAt = time.Now().Add(-11 * time.Second)
+	if _, err := cache.keyFunc(signedToken(t, newKey)); err != nil {
+		t.Errorf("keyFunc() of a new key error = %v", err)
+	}
+	if fetches := atomic.LoadInt32(&server.fetches); fetches != 2 {
+		t.Errorf("keyFunc() fetched %d times, want 2", fetches)
+	}
+
+	// the cache is refreshed once expired
+	cache.fetchedAt = time.Now().Add(-jwksCacheTTL - time.Second)
+	cache.attemptedAt = cache.fetchedAt
+	if _, err := cache.keyFunc(signedToken(t, oldKey)); err != nil {
+		t.Errorf("keyFunc() of an expired cache error = %v", err)
+	}
+	if fetches := atomic.LoadInt32(&server.fetches); fetches != 3 {
+		t.Errorf("keyFunc() fetched %d times, want 3", fetches)
+	}
+}
+
+func TestJWKSCacheFetchDoesNotBlockKnownKeys(t *testing.T) {
+	knownKey := newEd25519Key(t, "known")
+	rotatedKey := newEd25519Key(t, "rotated")
+
+	server := newJWKSServer(t, knownKey)
+	cache := server.cache()
+
+	if _, err := cache.keyFunc(signedToken(t, knownKey)); err != nil {
+		t.Fatalf("keyFunc() error = %v", err)
+	}
+
+	blocked := make(chan struct{})
+	server.mu.Lock()
+	server.blocked = blocked
+	server.keys = []signingKey{rotatedKey, knownKey}
+	server.mu.Unlock()
+	cache.attemptedAt = time.Time{}
+
+	// fetch the rotated key, the auth app is slow to answer
+	fetched := make(chan error)
+	go func() {
+		_, err := cache.keyFunc(signedToken(t, rotatedKey))
+		fetched <- err
+	}()
+
+	for atomic.LoadInt32(&server.fetches) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	verified := make(chan error)
+	go func() {
+		_, err := cache.keyFunc(signedToken(t, knownKey))
+		verified <- err
+	}()
+
+	select {
+	case err := <-verified:
+		if err != nil {
+			t.Errorf("keyFunc() of a known key during a fetch error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("keyFunc() of a known key waited for the fetch")
+	}
+
+	close(blocked)
+	if err := <-fetched; err != nil {
+		t.Errorf("keyFunc() of the rotated key error = %v", err)
+	}
+}
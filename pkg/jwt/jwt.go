@@ -2,100 +2,307 @@ package jwt
 
 import (
 	"errors"
-	"fmt"
 	"time"
 
 	"github.com/adesupraptolaia/user_login/internal/entity"
 	"github.com/golang-jwt/jwt"
+	"github.com/segmentio/ksuid"
 )
 
-// create JWT Access Token, valid until 1 hour
-func CreateAccessToken(userKsuid, role string) (string, error) {
+const (
+	ACCESS_TOKEN_TTL  = 1 * time.Hour
+	REFRESH_TOKEN_TTL = 24 * time.Hour
+	MFA_TOKEN_TTL     = 5 * time.Minute
+	SERVICE_TOKEN_TTL = 15 * time.Minute
+	EMAIL_TOKEN_TTL   = 24 * time.Hour
+	// no longer than the refresh token, a rotated refresh token key is only kept that long
+	INVITATION_TOKEN_TTL = 24 * time.Hour
+
+	// token_use of the token given after the password, waiting for the second factor
+	MFA_PENDING = "mfa_pending"
+	// token_use of the token sent to verify the email address of the user
+	EMAIL_VERIFICATION = "email_verification"
+	// token_use of the token given to an invited user to choose a password
+	INVITATION = "invitation"
+)
+
+// create JWT Access Token of the session for the audience, valid until 1 hour
+func CreateAccessToken(userKsuid, role, sessionKsuid string, permissions, audience []string) (string, error) {
 	return createToken(
 		userKsuid,
 		role,
-		ACCESS_TOKEN_SECRET,
-		time.Now().Add(1*time.Hour).Unix(),
+		ksuid.New().String(),
+		entity.ACCESS_TOKEN,
+		sessionKsuid,
+		permissions,
+		audience,
+		accessTokenKeys,
+		time.Now().Add(ACCESS_TOKEN_TTL).Unix(),
 	)
 }
 
-// create JWT Refressh Token, valid until 24 hour
-func CreateRefreshToken(userKsuid, role string) (string, error) {
+// create JWT Refressh Token identified by jti, valid until 24 hour
+func CreateRefreshToken(userKsuid, role, jti string) (string, error) {
 	return createToken(
 		userKsuid,
 		role,
-		REFRESH_TOKEN_SECRET,
-		time.Now().Add(24*time.Hour).Unix(),
+		jti,
+		entity.REFRESH_TOKEN,
+		"",
+		nil,
+		[]string{AUTH_PUBLIC_AUDIENCE},
+		refreshTokenKeys,
+		time.Now().Add(REFRESH_TOKEN_TTL).Unix(),
 	)
 }
 
-// validate Admin
-func IsAdmin(tokenString string) error {
-	claims, err := validateToken(tokenString, ACCESS_TOKEN_SECRET)
-	if err != nil {
-		return err
+// create JWT MFA pending Token, only accepted by /login/mfa, valid until 5 minutes.
+// It is signed by the refresh token key, which never leaves auth app
+func CreateMfaToken(userKsuid, role string) (string, error) {
+	return createToken(
+		userKsuid,
+		role,
+		ksuid.New().String(),
+		MFA_PENDING,
+		"",
+		nil,
+		[]string{AUTH_PUBLIC_AUDIENCE},
+		refreshTokenKeys,
+		time.Now().Add(MFA_TOKEN_TTL).Unix(),
+	)
+}
+
+// create JWT Email Verification Token of the email address, only accepted by /email/verify,
+// valid until 24 hours. Like the MFA token, it is signed by the refresh token key
+func CreateEmailVerificationToken(userKsuid, email string) (string, error) {
+	now := time.Now().Unix()
+
+	claims := &Claims{
+		UserKsuid: userKsuid,
+		TokenUse:  EMAIL_VERIFICATION,
+		Email:     email,
+		Audience:  []string{AUTH_PUBLIC_AUDIENCE},
+		StandardClaims: jwt.StandardClaims{
+			Id:        ksuid.New().String(),
+			Issuer:    ISSUER,
+			Subject:   userKsuid,
+			ExpiresAt: time.Now().Add(EMAIL_TOKEN_TTL).Unix(),
+			IssuedAt:  now,
+			NotBefore: now,
+		},
 	}
 
-	if claims.Role != entity.ADMIN {
-		return fmt.Errorf("unauthorize")
+	return signToken(claims, refreshTokenKeys)
+}
+
+// create JWT Invitation Token of the invited user, only accepted by /invite/accept,
+// valid until 24 hours. Like the MFA token, it is signed by the refresh token key
+func CreateInvitationToken(userKsuid string, invitedAt time.Time) (string, error) {
+	return createToken(
+		userKsuid,
+		entity.USER,
+		ksuid.New().String(),
+		INVITATION,
+		"",
+		nil,
+		[]string{AUTH_PUBLIC_AUDIENCE},
+		refreshTokenKeys,
+		invitedAt.Add(INVITATION_TOKEN_TTL).Unix(),
+	)
+}
+
+// create JWT Access Token of a service client for the auth private server, valid until 15 minutes
+func CreateServiceToken(clientID string, permissions []string) (string, error) {
+	return CreateClientToken(clientID, permissions, []string{AUTH_PRIVATE_AUDIENCE})
+}
+
+// create JWT Access Token of a client on its own behalf, valid until 15 minutes.
+// The client id is the subject, there is no user behind the token
+func CreateClientToken(clientID string, permissions, audience []string) (string, error) {
+	return createToken(
+		clientID,
+		entity.SERVICE,
+		ksuid.New().String(),
+		entity.ACCESS_TOKEN,
+		"",
+		permissions,
+		audience,
+		accessTokenKeys,
+		time.Now().Add(SERVICE_TOKEN_TTL).Unix(),
+	)
+}
+
+// audience of the access token issued on login, only a role granted a permission
+// of the private server manages the auth app through it, any other stays on the public one
+func LoginAudience(permissions []string) []string {
+	audience := []string{AUTH_PUBLIC_AUDIENCE, USER_APP_AUDIENCE}
+	for _, permission := range permissions {
+		if isPrivatePermission(permission) {
+			return append(audience, AUTH_PRIVATE_AUDIENCE)
+		}
+	}
+
+	return audience
+}
+
+func isPrivatePermission(permission string) bool {
+	for _, p := range entity.PRIVATE_PERMISSIONS {
+		if p == permission {
+			return true
+		}
 	}
 
-	return nil
+	return false
 }
 
-// validate JWT Access Token
-func ValidateAccessToken(tokenString, userKsuid string) error {
-	claims, err := validateToken(tokenString, ACCESS_TOKEN_SECRET)
+// get claims of a valid JWT Access Token
+func GetAccessTokenClaims(tokenString, audience string) (*Claims, error) {
+	claims, err := validateToken(tokenString, audience, accessTokenKeyFunc)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if !(claims.Role == entity.ADMIN || claims.UserKsuid == userKsuid) {
-		return fmt.Errorf("unauthorize")
+	return accessTokenClaims(claims)
+}
+
+// get claims of a valid JWT Access Token issued for any audience,
+// only auth app introspects tokens on behalf of the other services
+func IntrospectAccessToken(tokenString string) (*Claims, error) {
+	claims, err := parseToken(tokenString, accessTokenKeyFunc)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return accessTokenClaims(claims)
 }
 
 // validate JWT Refresh Token
 func ValidateRefreshToken(tokenString string) (*Claims, error) {
-	claims, err := validateToken(tokenString, REFRESH_TOKEN_SECRET)
+	claims, err := validateToken(tokenString, AUTH_PUBLIC_AUDIENCE, refreshTokenKeys.keyFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.TokenUse != entity.REFRESH_TOKEN {
+		return nil, errors.New("invalid token use")
+	}
+
+	return claims, nil
+}
+
+// validate JWT Email Verification Token
+func ValidateEmailVerificationToken(tokenString string) (*Claims, error) {
+	claims, err := validateToken(tokenString, AUTH_PUBLIC_AUDIENCE, refreshTokenKeys.keyFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.TokenUse != EMAIL_VERIFICATION || claims.Email == "" {
+		return nil, errors.New("invalid token use")
+	}
+
+	return claims, nil
+}
+
+// validate JWT Invitation Token
+func ValidateInvitationToken(tokenString string) (*Claims, error) {
+	claims, err := validateToken(tokenString, AUTH_PUBLIC_AUDIENCE, refreshTokenKeys.keyFunc)
 	if err != nil {
 		return nil, err
 	}
 
+	if claims.TokenUse != INVITATION {
+		return nil, errors.New("invalid token use")
+	}
+
 	return claims, nil
 }
 
-func createToken(userKsuid, role string, secretKey []byte, expiresAt int64) (string, error) {
+// validate JWT MFA pending Token
+func ValidateMfaToken(tokenString string) (*Claims, error) {
+	claims, err := validateToken(tokenString, AUTH_PUBLIC_AUDIENCE, refreshTokenKeys.keyFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.TokenUse != MFA_PENDING {
+		return nil, errors.New("invalid token use")
+	}
+
+	return claims, nil
+}
+
+func createToken(userKsuid, role, jti, tokenUse, sessionKsuid string, permissions, audience []string, ring *keyRing, expiresAt int64) (string, error) {
+	now := time.Now().Unix()
+
 	// Create the claims for the JWT token
 	claims := &Claims{
-		UserKsuid: userKsuid,
-		Role:      role,
+		UserKsuid:    userKsuid,
+		Role:         role,
+		TokenUse:     tokenUse,
+		SessionKsuid: sessionKsuid,
+		Permissions:  permissions,
+		Audience:     audience,
 		StandardClaims: jwt.StandardClaims{
+			Id:        jti,
+			Issuer:    ISSUER,
+			Subject:   userKsuid,
 			ExpiresAt: expiresAt,
+			IssuedAt:  now,
+			NotBefore: now,
 		},
 	}
+	return signToken(claims, ring)
+}
+
+// signToken sign with the current key of the ring
+func signToken(claims jwt.Claims, ring *keyRing) (string, error) {
+	key, err := ring.signingKey()
+	if err != nil {
+		return "", err
+	}
+
 	// Create the JWT token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token := jwt.NewWithClaims(key.method, claims)
+	if key.kid != "" {
+		token.Header["kid"] = key.kid
+	}
 
-	// Sign the JWT token with the secret key
-	tokenString, err := token.SignedString(secretKey)
+	// Sign the JWT token with the key
+	tokenString, err := token.SignedString(key.privateKey)
 	if err != nil {
 		return "", err
 	}
 
 	return tokenString, nil
 }
-func validateToken(tokenString string, secretKey []byte) (*Claims, error) {
+
+func validateToken(tokenString, audience string, keyfunc jwt.Keyfunc) (*Claims, error) {
+	claims, err := parseToken(tokenString, keyfunc)
+	if err != nil {
+		return nil, err
+	}
+
+	if !claims.HasAudience(audience) {
+		return nil, errors.New("token is not issued for this service")
+	}
+
+	return claims, nil
+}
+
+// ID token is signed by the same key as access token
+func accessTokenClaims(claims *Claims) (*Claims, error) {
+	if claims.TokenUse != entity.ACCESS_TOKEN {
+		return nil, errors.New("invalid token use")
+	}
+
+	return claims, nil
+}
+
+// parseToken validate every claim but the audience
+func parseToken(tokenString string, keyfunc jwt.Keyfunc) (*Claims, error) {
 	// Parse the token
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Verify the signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return secretKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, keyfunc)
 	if err != nil {
 		return nil, err
 	}
@@ -111,6 +318,14 @@ func validateToken(tokenString string, secretKey []byte) (*Claims, error) {
 		return nil, errors.New("token has expired")
 	}
 
+	if claims.Issuer != ISSUER {
+		return nil, errors.New("invalid token issuer")
+	}
+
+	if claims.Id == "" || claims.Subject != claims.UserKsuid {
+		return nil, errors.New("invalid token")
+	}
+
 	// Return the custom claims
 	return claims, nil
 }
@@ -0,0 +1,176 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/golang-jwt/jwt"
+)
+
+func TestValidateRefreshToken(t *testing.T) {
+	refreshToken, _ := CreateRefreshToken("ksuid", entity.USER, "jti")
+	mfaToken, _ := CreateMfaToken("ksuid", entity.USER)
+	emailToken, _ := CreateEmailVerificationToken("ksuid", "user@example.com")
+	invitationToken, _ := CreateInvitationToken("ksuid", time.Now())
+	// signed by the refresh token key with a token use ValidateRefreshToken does not know
+	unknownUseToken, _ := createToken("ksuid", entity.USER, "jti", "unknown", "", nil, []string{AUTH_PUBLIC_AUDIENCE}, refreshTokenKeys, time.Now().Add(time.Hour).Unix())
+
+	tests := []struct {
+		name        string
+		tokenString string
+		wantErr     bool
+	}{
+		{
+			name:        "Success Refresh Token",
+			tokenString: refreshToken,
+			wantErr:     false,
+		},
+		{
+			name:        "Failed MFA Token",
+			tokenString: mfaToken,
+			wantErr:     true,
+		},
+		{
+			name:        "Failed Email Verification Token",
+			tokenString: emailToken,
+			wantErr:     true,
+		},
+		{
+			name:        "Failed Invitation Token",
+			tokenString: invitationToken,
+			wantErr:     true,
+		},
+		{
+			name:        "Failed Unknown Token Use",
+			tokenString: unknownUseToken,
+			wantErr:     true,
+		},
+		{
+			name:        "Failed Invalid Token",
+			tokenString: "invalidToken",
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateRefreshToken(tt.tokenString)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRefreshToken() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.Id != "jti" {
+				t.Errorf("ValidateRefreshToken() jti = %v, want jti", got.Id)
+			}
+		})
+	}
+}
+
+func TestGetAccessTokenClaims(t *testing.T) {
+	// signed by the access token key, with one claim changed from a valid access token
+	accessToken := func(change func(*Claims)) string {
+		now := time.Now().Unix()
+		claims := &Claims{
+			UserKsuid: "ksuid",
+			Role:      entity.USER,
+			TokenUse:  entity.ACCESS_TOKEN,
+			Audience:  []string{AUTH_PUBLIC_AUDIENCE},
+			StandardClaims: jwt.StandardClaims{
+				Id:        "jti",
+				Issuer:    ISSUER,
+				Subject:   "ksuid",
+				ExpiresAt: time.Now().Add(time.Hour).Unix(),
+				IssuedAt:  now,
+				NotBefore: now,
+			},
+		}
+		change(claims)
+		tokenString, _ := signToken(claims, accessTokenKeys)
+		return tokenString
+	}
+
+	tests := []struct {
+		name        string
+		tokenString string
+		wantErr     bool
+	}{
+		{
+			name:        "Success Access Token",
+			tokenString: accessToken(func(c *Claims) {}),
+			wantErr:     false,
+		},
+		{
+			name:        "Failed Wrong Issuer",
+			tokenString: accessToken(func(c *Claims) { c.Issuer = "another_issuer" }),
+			wantErr:     true,
+		},
+		{
+			name:        "Failed Not Valid Yet",
+			tokenString: accessToken(func(c *Claims) { c.NotBefore = time.Now().Add(time.Hour).Unix() }),
+			wantErr:     true,
+		},
+		{
+			name:        "Failed Empty Jti",
+			tokenString: accessToken(func(c *Claims) { c.Id = "" }),
+			wantErr:     true,
+		},
+		{
+			name:        "Failed Subject Not User Ksuid",
+			tokenString: accessToken(func(c *Claims) { c.Subject = "anotherKsuid" }),
+			wantErr:     true,
+		},
+		{
+			name:        "Failed Expired",
+			tokenString: accessToken(func(c *Claims) { c.ExpiresAt = time.Now().Add(-time.Minute).Unix() }),
+			wantErr:     true,
+		},
+		{
+			name:        "Failed Another Audience",
+			tokenString: accessToken(func(c *Claims) { c.Audience = []string{USER_APP_AUDIENCE} }),
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetAccessTokenClaims(tt.tokenString, AUTH_PUBLIC_AUDIENCE)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetAccessTokenClaims() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.UserKsuid != "ksuid" {
+				t.Errorf("GetAccessTokenClaims() user_ksuid = %v, want ksuid", got.UserKsuid)
+			}
+		})
+	}
+}
+
+func TestLoginAudience(t *testing.T) {
+	admin := []string{}
+	for permission := range entity.PERMISSIONS {
+		admin = append(admin, permission)
+	}
+
+	tests := []struct {
+		name        string
+		permissions []string
+		wantPrivate bool
+	}{
+		{name: "Admin", permissions: admin, wantPrivate: true},
+		{name: "User", permissions: entity.USER_PERMISSIONS, wantPrivate: false},
+		{name: "No Permission", permissions: nil, wantPrivate: false},
+		{name: "Role Created By Admin, support", permissions: []string{entity.PERM_PROFILE_READ_ANY, entity.PERM_USER_UNLOCK}, wantPrivate: true},
+		{name: "Role Created By Admin, custom permission", permissions: []string{entity.PERM_PROFILE_READ_SELF, "report:read"}, wantPrivate: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := Claims{}
+			claims.Audience = LoginAudience(tt.permissions)
+			if !claims.HasAudience(AUTH_PUBLIC_AUDIENCE) || !claims.HasAudience(USER_APP_AUDIENCE) {
+				t.Errorf("LoginAudience(%v) = %v, want the public audiences", tt.permissions, claims.Audience)
+			}
+			if got := claims.HasAudience(AUTH_PRIVATE_AUDIENCE); got != tt.wantPrivate {
+				t.Errorf("LoginAudience(%v) = %v, private audience %v, want %v", tt.permissions, claims.Audience, got, tt.wantPrivate)
+			}
+		})
+	}
+}
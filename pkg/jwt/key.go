@@ -0,0 +1,222 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/golang-jwt/jwt"
+	"github.com/segmentio/ksuid"
+)
+
+type signingKey struct {
+	kid        string
+	method     jwt.SigningMethod
+	privateKey crypto.PrivateKey
+	publicKey  crypto.PublicKey
+	createdAt  time.Time
+	// legacy key also verifies tokens issued before kid header was stamped
+	legacy bool
+}
+
+func loadSigningKey(algorithm, privateKeyFile string) (signingKey, error) {
+	pemBytes, err := os.ReadFile(privateKeyFile)
+	if err != nil {
+		return signingKey{}, err
+	}
+
+	return parsePrivateKey(algorithm, pemBytes)
+}
+
+func newHMACKey(secret []byte) signingKey {
+	sum := sha256.Sum256(secret)
+
+	return signingKey{
+		kid:        base64.RawURLEncoding.EncodeToString(sum[:8]),
+		method:     jwt.SigningMethodHS256,
+		privateKey: secret,
+		publicKey:  secret,
+		legacy:     true,
+	}
+}
+
+// parseSigningKey parse a key generated by GenerateKey
+func parseSigningKey(data entity.SigningKey) (signingKey, error) {
+	var key signingKey
+
+	if data.Algorithm == jwt.SigningMethodHS256.Alg() {
+		secret, err := base64.StdEncoding.DecodeString(data.PrivateKey)
+		if err != nil {
+			return signingKey{}, err
+		}
+		key = signingKey{method: jwt.SigningMethodHS256, privateKey: secret, publicKey: secret}
+	} else {
+		var err error
+		key, err = parsePrivateKey(data.Algorithm, []byte(data.PrivateKey))
+		if err != nil {
+			return signingKey{}, err
+		}
+	}
+
+	key.kid = data.Kid
+	key.createdAt = data.CreatedAt
+
+	return key, nil
+}
+
+func parsePrivateKey(algorithm string, pemBytes []byte) (signingKey, error) {
+	var key signingKey
+
+	switch algorithm {
+	case jwt.SigningMethodRS256.Alg():
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes)
+		if err != nil {
+			return signingKey{}, err
+		}
+		key = signingKey{method: jwt.SigningMethodRS256, privateKey: privateKey, publicKey: &privateKey.PublicKey}
+	case jwt.SigningMethodEdDSA.Alg():
+		privateKey, err := jwt.ParseEdPrivateKeyFromPEM(pemBytes)
+		if err != nil {
+			return signingKey{}, err
+		}
+		key = signingKey{method: jwt.SigningMethodEdDSA, privateKey: privateKey, publicKey: privateKey.(ed25519.PrivateKey).Public()}
+	default:
+		return signingKey{}, fmt.Errorf("unsupported algorithm %s", algorithm)
+	}
+
+	var err error
+	key.kid, err = thumbprint(key.publicKey)
+	if err != nil {
+		return signingKey{}, err
+	}
+
+	return key, nil
+}
+
+// GenerateKey create a new key for the token type,
+// using the same algorithm as the key currently signing that token type
+func GenerateKey(tokenType string) (*entity.SigningKey, error) {
+	ring, err := keyRingOf(tokenType)
+	if err != nil {
+		return nil, err
+	}
+
+	currentKey, err := ring.signingKey()
+	if err != nil {
+		return nil, err
+	}
+
+	algorithm := currentKey.method.Alg()
+	data := &entity.SigningKey{
+		TokenType: tokenType,
+		Algorithm: algorithm,
+	}
+
+	var privateKey crypto.PrivateKey
+	switch algorithm {
+	case jwt.SigningMethodHS256.Alg():
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+		data.Kid = ksuid.New().String()
+		data.PrivateKey = base64.StdEncoding.EncodeToString(secret)
+		return data, nil
+	case jwt.SigningMethodRS256.Alg():
+		privateKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	case jwt.SigningMethodEdDSA.Alg():
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		err = fmt.Errorf("unsupported algorithm %s", algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	key, err := parsePrivateKey(algorithm, pemBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	data.Kid = key.kid
+	data.PrivateKey = string(pemBytes)
+
+	return data, nil
+}
+
+// thumbprint of the public key as defined in RFC 7638, used as kid
+func thumbprint(publicKey crypto.PublicKey) (string, error) {
+	jwk, err := toJWK("", "", publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	// members must be in lexicographic order
+	var members interface{}
+	switch jwk.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// keyFunc find the verification key by the kid header of the token
+func keyFunc(keys []signingKey, token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	for _, key := range keys {
+		if key.kid != kid && !(kid == "" && key.legacy) {
+			continue
+		}
+
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, errors.New("unexpected signing method")
+		}
+
+		return key.publicKey, nil
+	}
+
+	return nil, fmt.Errorf("unknown signing key %s", kid)
+}
+
+func accessTokenKeyFunc(token *jwt.Token) (interface{}, error) {
+	if remoteKeys != nil {
+		return remoteKeys.keyFunc(token)
+	}
+
+	return accessTokenKeys.keyFunc(token)
+}
@@ -0,0 +1,89 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+)
+
+func TestKeyFunc(t *testing.T) {
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edKey := signingKey{kid: "ed", method: jwt.SigningMethodEdDSA, publicKey: publicKey}
+	hmacKey := newHMACKey([]byte("secret"))
+
+	type args struct {
+		keys   []signingKey
+		method jwt.SigningMethod
+		kid    string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name:    "Success Key Of The Kid",
+			args:    args{[]signingKey{hmacKey, edKey}, jwt.SigningMethodEdDSA, "ed"},
+			want:    publicKey,
+			wantErr: false,
+		},
+		{
+			name:    "Success Legacy Key Without Kid",
+			args:    args{[]signingKey{edKey, hmacKey}, jwt.SigningMethodHS256, ""},
+			want:    hmacKey.publicKey,
+			wantErr: false,
+		},
+		{
+			name:    "Failed Unknown Kid",
+			args:    args{[]signingKey{edKey, hmacKey}, jwt.SigningMethodEdDSA, "unknown"},
+			wantErr: true,
+		},
+		{
+			name:    "Failed Signing Method Of Another Key Type",
+			args:    args{[]signingKey{edKey}, jwt.SigningMethodHS256, "ed"},
+			wantErr: true,
+		},
+		{
+			name:    "Failed No Kid Without Legacy Key",
+			args:    args{[]signingKey{edKey}, jwt.SigningMethodEdDSA, ""},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := jwt.New(tt.args.method)
+			if tt.args.kid != "" {
+				token.Header["kid"] = tt.args.kid
+			}
+
+			got, err := keyFunc(tt.args.keys, token)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("keyFunc() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && !keyEqual(got, tt.want) {
+				t.Errorf("keyFunc() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func keyEqual(got, want interface{}) bool {
+	switch want := want.(type) {
+	case ed25519.PublicKey:
+		got, ok := got.(ed25519.PublicKey)
+		return ok && want.Equal(got)
+	case []byte:
+		got, ok := got.([]byte)
+		return ok && string(want) == string(got)
+	}
+
+	return false
+}
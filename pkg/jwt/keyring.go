@@ -0,0 +1,107 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/golang-jwt/jwt"
+)
+
+// a rotated key only signs after every replica had the chance to load it
+const KEY_PUBLISH_DELAY = 30 * time.Second
+
+// keyRing hold every key that can verify a token type, only the newest key signs.
+// Keys from config are used until the first rotation, and keep verifying
+// tokens they have signed until those tokens expire.
+type keyRing struct {
+	mu         sync.RWMutex
+	ttl        time.Duration
+	configKeys []signingKey
+	keys       []signingKey // rotated keys, newest first
+}
+
+// SetKeys replace the rotated keys of the token type, keys must be ordered newest first
+func SetKeys(tokenType string, data []entity.SigningKey) error {
+	ring, err := keyRingOf(tokenType)
+	if err != nil {
+		return err
+	}
+
+	keys := []signingKey{}
+	for _, d := range data {
+		key, err := parseSigningKey(d)
+		if err != nil {
+			log.Printf("failed to parse jwt signing key %s: %v", d.Kid, err)
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	ring.mu.Lock()
+	ring.keys = keys
+	ring.mu.Unlock()
+
+	return nil
+}
+
+// KeyVerificationWindow is how long a retired key of the token type must stay loaded
+func KeyVerificationWindow(tokenType string) time.Duration {
+	ring, err := keyRingOf(tokenType)
+	if err != nil {
+		return 0
+	}
+
+	return ring.ttl + KEY_PUBLISH_DELAY
+}
+
+func keyRingOf(tokenType string) (*keyRing, error) {
+	switch tokenType {
+	case entity.ACCESS_TOKEN:
+		return accessTokenKeys, nil
+	case entity.REFRESH_TOKEN:
+		return refreshTokenKeys, nil
+	}
+
+	return nil, fmt.Errorf("unknown token type %s", tokenType)
+}
+
+// signingKey return the newest rotated key once it is published, before the first
+// rotation the first key from config. A service that loaded no key can't sign.
+func (r *keyRing) signingKey() (signingKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, key := range r.keys {
+		if time.Since(key.createdAt) >= KEY_PUBLISH_DELAY {
+			return key, nil
+		}
+	}
+
+	if len(r.configKeys) == 0 {
+		return signingKey{}, errors.New("no signing key loaded")
+	}
+
+	return r.configKeys[0], nil
+}
+
+func (r *keyRing) verificationKeys() []signingKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := append([]signingKey{}, r.keys...)
+
+	// config keys signed until the oldest rotated key was published
+	if len(r.keys) == 0 || time.Since(r.keys[len(r.keys)-1].createdAt) < r.ttl+KEY_PUBLISH_DELAY {
+		keys = append(keys, r.configKeys...)
+	}
+
+	return keys
+}
+
+func (r *keyRing) keyFunc(token *jwt.Token) (interface{}, error) {
+	return keyFunc(r.verificationKeys(), token)
+}
@@ -0,0 +1,99 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adesupraptolaia/user_login/internal/entity"
+	"github.com/golang-jwt/jwt"
+)
+
+func TestKeyRotation(t *testing.T) {
+	t.Cleanup(func() {
+		SetKeys(entity.ACCESS_TOKEN, nil)
+	})
+
+	configKey, err := accessTokenKeys.signingKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oldToken, _ := CreateAccessToken("ksuid", entity.USER, "session", nil, []string{USER_APP_AUDIENCE})
+
+	rotatedKey, err := GenerateKey(entity.ACCESS_TOKEN)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		// age of the rotated key
+		age            time.Duration
+		wantKid        string
+		wantOldTokenOK bool
+	}{
+		{
+			name:           "Config Key Signs Until The Rotated Key Is Published",
+			age:            0,
+			wantKid:        configKey.kid,
+			wantOldTokenOK: true,
+		},
+		{
+			name:           "Rotated Key Signs Once Published",
+			age:            KEY_PUBLISH_DELAY,
+			wantKid:        rotatedKey.Kid,
+			wantOldTokenOK: true,
+		},
+		{
+			name:           "Config Key Dropped After Its Tokens Expired",
+			age:            KeyVerificationWindow(entity.ACCESS_TOKEN) + time.Minute,
+			wantKid:        rotatedKey.Kid,
+			wantOldTokenOK: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := *rotatedKey
+			key.CreatedAt = time.Now().Add(-tt.age)
+			if err := SetKeys(entity.ACCESS_TOKEN, []entity.SigningKey{key}); err != nil {
+				t.Fatal(err)
+			}
+
+			newToken, err := CreateAccessToken("ksuid", entity.USER, "session", nil, []string{USER_APP_AUDIENCE})
+			if err != nil {
+				t.Fatalf("CreateAccessToken() error = %v", err)
+			}
+
+			if kid := tokenKid(t, newToken); kid != tt.wantKid {
+				t.Errorf("CreateAccessToken() kid = %v, want %v", kid, tt.wantKid)
+			}
+
+			if _, err := GetAccessTokenClaims(newToken, USER_APP_AUDIENCE); err != nil {
+				t.Errorf("GetAccessTokenClaims() of new token error = %v", err)
+			}
+
+			if _, err := GetAccessTokenClaims(oldToken, USER_APP_AUDIENCE); (err == nil) != tt.wantOldTokenOK {
+				t.Errorf("GetAccessTokenClaims() of old token error = %v, want ok %v", err, tt.wantOldTokenOK)
+			}
+		})
+	}
+}
+
+func TestKeyRingWithoutKeys(t *testing.T) {
+	ring := &keyRing{ttl: ACCESS_TOKEN_TTL}
+
+	if _, err := signToken(&Claims{}, ring); err == nil {
+		t.Errorf("signToken() without key should fail")
+	}
+}
+
+func tokenKid(t *testing.T, tokenString string) string {
+	token, _, err := new(jwt.Parser).ParseUnverified(tokenString, &Claims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kid, _ := token.Header["kid"].(string)
+
+	return kid
+}
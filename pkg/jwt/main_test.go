@@ -0,0 +1,18 @@
+package jwt
+
+import (
+	"log"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	cleanup, err := LoadTestSigningKeys()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	code := m.Run()
+	cleanup()
+	os.Exit(code)
+}
@@ -0,0 +1,66 @@
+package jwt
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/segmentio/ksuid"
+)
+
+const ID_TOKEN_TTL = 1 * time.Hour
+
+// IDClaims are the claims of an OpenID Connect ID Token, it tells the client
+// who has logged in. It has no token_use, so it is never accepted as an access token
+type IDClaims struct {
+	Nonce             string `json:"nonce,omitempty"`
+	AuthTime          int64  `json:"auth_time"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	// shadows StandardClaims.Audience, like Claims
+	Audience []string `json:"aud"`
+	jwt.StandardClaims
+}
+
+// create OpenID Connect ID Token for the client, valid until 1 hour.
+// It is signed by the access token key, so clients verify it with the JWKS
+func CreateIDToken(userKsuid, username, audience, nonce string, authTime time.Time) (string, error) {
+	now := time.Now()
+
+	claims := &IDClaims{
+		Nonce:             nonce,
+		AuthTime:          authTime.Unix(),
+		PreferredUsername: username,
+		Audience:          []string{audience},
+		StandardClaims: jwt.StandardClaims{
+			Id:        ksuid.New().String(),
+			Issuer:    ISSUER,
+			Subject:   userKsuid,
+			ExpiresAt: now.Add(ID_TOKEN_TTL).Unix(),
+			IssuedAt:  now.Unix(),
+			NotBefore: now.Unix(),
+		},
+	}
+
+	return signToken(claims, accessTokenKeys)
+}
+
+// SigningAlgorithms of the keys that can verify access and ID tokens
+func SigningAlgorithms() []string {
+	algorithms := []string{}
+	for _, key := range accessTokenKeys.verificationKeys() {
+		if !contains(algorithms, key.method.Alg()) {
+			algorithms = append(algorithms, key.method.Alg())
+		}
+	}
+
+	return algorithms
+}
+
+func contains(list []string, item string) bool {
+	for _, i := range list {
+		if i == item {
+			return true
+		}
+	}
+
+	return false
+}
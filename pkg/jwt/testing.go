@@ -0,0 +1,52 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+)
+
+// LoadTestSigningKeys sign the tokens of the tests with a generated key, like auth app on start.
+// Only for tests, the returned func removes the generated key
+func LoadTestSigningKeys() (func(), error) {
+	dir, err := os.MkdirTemp("", "jwt")
+	if err != nil {
+		return nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	keyFile, err := WriteEd25519Key(dir)
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	err = LoadSigningKeys([]KeyFile{{Algorithm: "EdDSA", PrivateKeyFile: keyFile}}, []byte("refresh_token_secret_of_the_tests"))
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	return cleanup, nil
+}
+
+// WriteEd25519Key write a generated Ed25519 private key in dir, return the key file
+func WriteEd25519Key(dir string) (string, error) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	keyFile := filepath.Join(dir, "access_token_ed25519.pem")
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+
+	return keyFile, err
+}
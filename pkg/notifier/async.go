@@ -0,0 +1,27 @@
+package notifier
+
+import "log"
+
+// asyncNotifier deliver in the background, the caller neither waits for the mail server
+// nor learns whether the delivery failed
+type asyncNotifier struct {
+	next Notifier
+}
+
+// NewAsync deliver every message through next in the background, failures are logged
+func NewAsync(next Notifier) Notifier {
+	return &asyncNotifier{
+		next: next,
+	}
+}
+
+func (n *asyncNotifier) Notify(message Message) error {
+	go func() {
+		// the recipient is personal data, only the subject is logged
+		if err := n.next.Notify(message); err != nil {
+			log.Printf("failed to deliver message %q: %v", message.Subject, err)
+		}
+	}()
+
+	return nil
+}
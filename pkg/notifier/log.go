@@ -0,0 +1,39 @@
+package notifier
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// logNotifier write the messages instead of sending them, for development
+type logNotifier struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// NewLog append every message to the file at path, or print it to stdout when path is empty
+func NewLog(path string) (Notifier, error) {
+	if path == "" {
+		return &logNotifier{out: os.Stdout}, nil
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &logNotifier{out: file}, nil
+}
+
+func (n *logNotifier) Notify(message Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	_, err := fmt.Fprintf(n.out, "Date: %s\nTo: %s\nSubject: %s\n\n%s\n\n",
+		time.Now().Format(time.RFC1123Z), message.To, message.Subject, message.Body)
+
+	return err
+}
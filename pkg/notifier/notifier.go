@@ -0,0 +1,12 @@
+// Package notifier deliver messages to users, by email, or to a log file during development.
+package notifier
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Notifier interface {
+	Notify(Message) error
+}
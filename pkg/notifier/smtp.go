@@ -0,0 +1,67 @@
+package notifier
+
+import (
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPConfig without username sends without authentication, e.g. to a local fake SMTP server
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+type smtpNotifier struct {
+	cfg SMTPConfig
+}
+
+// NewSMTP send plain text emails, upgraded to TLS by STARTTLS when the server offers it
+func NewSMTP(cfg SMTPConfig) Notifier {
+	return &smtpNotifier{
+		cfg: cfg,
+	}
+}
+
+func (n *smtpNotifier) Notify(message Message) error {
+	to, err := mail.ParseAddress(message.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient address %q", message.To)
+	}
+
+	from, err := mail.ParseAddress(n.cfg.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender address %q", n.cfg.From)
+	}
+
+	// the subject is a header, a line break would start a new one
+	if strings.ContainsAny(message.Subject, "\r\n") {
+		return fmt.Errorf("invalid subject %q", message.Subject)
+	}
+
+	var auth smtp.Auth
+	if n.cfg.Username != "" {
+		auth = smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)
+	}
+
+	body := strings.ReplaceAll(strings.ReplaceAll(message.Body, "\r\n", "\n"), "\n", "\r\n")
+	msg := "From: " + from.String() + "\r\n" +
+		"To: " + to.String() + "\r\n" +
+		"Subject: " + mime.QEncoding.Encode("utf-8", message.Subject) + "\r\n" +
+		"Date: " + time.Now().Format(time.RFC1123Z) + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" + body + "\r\n"
+
+	addr := net.JoinHostPort(n.cfg.Host, strconv.Itoa(n.cfg.Port))
+
+	return smtp.SendMail(addr, auth, from.Address, []string{to.Address}, []byte(msg))
+}
@@ -0,0 +1,206 @@
+package notifier
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+)
+
+// fakeSMTPServer accept sessions without TLS nor authentication and record the mails it receives.
+// A recipient containing "rejected" is refused.
+type fakeSMTPServer struct {
+	listener net.Listener
+	received chan fakeMail
+}
+
+type fakeMail struct {
+	from string
+	to   []string
+	data string
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	s := &fakeSMTPServer{listener: listener, received: make(chan fakeMail, 1)}
+	go s.serve()
+
+	return s
+}
+
+func (s *fakeSMTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.session(conn)
+	}
+}
+
+func (s *fakeSMTPServer) session(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) {
+		conn.Write([]byte(line + "\r\n"))
+	}
+
+	mail := fakeMail{}
+	reply("220 localhost fake SMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(line)
+
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			mail.from = line[len("MAIL FROM:"):]
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			if strings.Contains(line, "rejected") {
+				reply("550 no such user")
+				continue
+			}
+			mail.to = append(mail.to, line[len("RCPT TO:"):])
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 end with .")
+			var data strings.Builder
+			for {
+				dataLine, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			mail.data = data.String()
+			s.received <- mail
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestSMTPNotify(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	n := NewSMTP(SMTPConfig{Host: "127.0.0.1", Port: server.port(), From: "user_login <no-reply@localhost>"})
+
+	tests := []struct {
+		name     string
+		message  Message
+		wantErr  bool
+		wantData []string
+	}{
+		{
+			name:    "Success Send Plain Text Email",
+			message: Message{To: "user@example.com", Subject: "Reset your password", Body: "Hi user,\nuse this token"},
+			wantErr: false,
+			wantData: []string{
+				"From: \"user_login\" <no-reply@localhost>\r\n",
+				"To: <user@example.com>\r\n",
+				"Subject: Reset your password\r\n",
+				"Content-Type: text/plain; charset=UTF-8\r\n",
+				"\r\nHi user,\r\nuse this token\r\n",
+			},
+		},
+		{
+			name:     "Success Encode Non ASCII Subject",
+			message:  Message{To: "user@example.com", Subject: "Vérifiez votre adresse", Body: "Bonjour"},
+			wantErr:  false,
+			wantData: []string{"Subject: =?utf-8?q?V=C3=A9rifiez_votre_adresse?=\r\n"},
+		},
+		{
+			name:    "Failed Invalid Recipient",
+			message: Message{To: "user", Subject: "Reset your password", Body: "Hi"},
+			wantErr: true,
+		},
+		{
+			name:    "Failed Subject With Line Break",
+			message: Message{To: "user@example.com", Subject: "Reset\r\nBcc: attacker@example.com", Body: "Hi"},
+			wantErr: true,
+		},
+		{
+			name:    "Failed Recipient Rejected By The Server",
+			message: Message{To: "rejected@example.com", Subject: "Reset your password", Body: "Hi"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := n.Notify(tt.message)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("smtpNotifier.Notify() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+
+			mail := <-server.received
+			if mail.from != "<no-reply@localhost>" {
+				t.Errorf("smtpNotifier.Notify() MAIL FROM = %s, want <no-reply@localhost>", mail.from)
+			}
+			if len(mail.to) != 1 || mail.to[0] != "<"+tt.message.To+">" {
+				t.Errorf("smtpNotifier.Notify() RCPT TO = %v, want <%s>", mail.to, tt.message.To)
+			}
+			for _, want := range tt.wantData {
+				if !strings.Contains(mail.data, want) {
+					t.Errorf("smtpNotifier.Notify() data = %q, want %q", mail.data, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSMTPNotifyUnreachableServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	n := NewSMTP(SMTPConfig{Host: "127.0.0.1", Port: port, From: "no-reply@localhost"})
+	if err := n.Notify(Message{To: "user@example.com", Subject: "Hi", Body: "Hi"}); err == nil {
+		t.Errorf("smtpNotifier.Notify() to an unreachable server should fail")
+	}
+}
+
+func TestAsyncNotify(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	n := NewAsync(NewSMTP(SMTPConfig{Host: "127.0.0.1", Port: server.port(), From: "no-reply@localhost"}))
+
+	if err := n.Notify(Message{To: "user@example.com", Subject: "Hi", Body: "Hi"}); err != nil {
+		t.Errorf("asyncNotifier.Notify() error = %v", err)
+	}
+	if mail := <-server.received; len(mail.to) != 1 {
+		t.Errorf("asyncNotifier.Notify() RCPT TO = %v, want one recipient", mail.to)
+	}
+
+	// a failed delivery is not told to the caller
+	if err := n.Notify(Message{To: "rejected@example.com", Subject: "Hi", Body: "Hi"}); err != nil {
+		t.Errorf("asyncNotifier.Notify() error = %v, want nil", err)
+	}
+}
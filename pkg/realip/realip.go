@@ -0,0 +1,35 @@
+// Package realip tell the ip address of the client of a request, behind the proxies the app trusts.
+package realip
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Extractor read the client ip out of X-Forwarded-For only when the request comes through one of
+// trustedProxies, given in CIDR notation. Without trusted proxies the header is ignored and the
+// client ip is the remote address of the connection, so a client can't choose its ip by sending the header.
+func Extractor(trustedProxies []string) (echo.IPExtractor, error) {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	// echo trusts loopback, link-local and private addresses by default, any container
+	// of a docker network would be a proxy
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, proxy := range trustedProxies {
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
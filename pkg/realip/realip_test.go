@@ -0,0 +1,76 @@
+package realip
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExtractor(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		forwardedFor   string
+		want           string
+		wantErr        bool
+	}{
+		{
+			name:         "Success Ignore Header Without Trusted Proxies",
+			remoteAddr:   "172.18.0.5:41234",
+			forwardedFor: "203.0.113.7",
+			want:         "172.18.0.5",
+		},
+		{
+			name:           "Success Client Behind Trusted Proxy",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.1.2.3:41234",
+			forwardedFor:   "203.0.113.7",
+			want:           "203.0.113.7",
+		},
+		{
+			name:           "Success Ignore Address Added By The Client",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.1.2.3:41234",
+			forwardedFor:   "198.51.100.1, 203.0.113.7",
+			want:           "203.0.113.7",
+		},
+		{
+			name:           "Success Ignore Header From Untrusted Private Network",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "172.18.0.5:41234",
+			forwardedFor:   "203.0.113.7",
+			want:           "172.18.0.5",
+		},
+		{
+			name:           "Success Ignore Header From Loopback",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "127.0.0.1:41234",
+			forwardedFor:   "203.0.113.7",
+			want:           "127.0.0.1",
+		},
+		{
+			name:           "Failed Invalid Trusted Proxy",
+			trustedProxies: []string{"10.0.0.1"},
+			wantErr:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extractor, err := Extractor(tt.trustedProxies)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Extractor() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			if got := extractor(req); got != tt.want {
+				t.Errorf("Extractor() ip = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package totp
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// secret of the SHA1 test vectors of RFC 6238 appendix B, "12345678901234567890" base32 encoded
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	// the 8 digit codes of the RFC truncated to their last 6 digits
+	tests := []struct {
+		name string
+		time int64
+		want string
+	}{
+		{name: "RFC 6238 59", time: 59, want: "287082"},
+		{name: "RFC 6238 1111111109", time: 1111111109, want: "081804"},
+		{name: "RFC 6238 1111111111", time: 1111111111, want: "050471"},
+		{name: "RFC 6238 1234567890", time: 1234567890, want: "005924"},
+		{name: "RFC 6238 2000000000", time: 2000000000, want: "279037"},
+		{name: "RFC 6238 20000000000", time: 20000000000, want: "353130"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Code(rfcSecret, time.Unix(tt.time, 0))
+			if err != nil {
+				t.Fatalf("Code() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Code() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Code("not*base32", time.Unix(59, 0)); err == nil {
+		t.Errorf("Code() of an invalid secret should fail")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := Step(now)
+
+	tests := []struct {
+		name     string
+		passcode string
+		want     int64
+		wantOk   bool
+	}{
+		{name: "Success Current Step", passcode: "050471", want: step, wantOk: true},
+		{name: "Success With Spaces", passcode: " 050471 ", want: step, wantOk: true},
+		{name: "Success Previous Step", passcode: mustCode(t, now.Add(-PERIOD)), want: step - 1, wantOk: true},
+		{name: "Success Next Step", passcode: mustCode(t, now.Add(PERIOD)), want: step + 1, wantOk: true},
+		{name: "Failed Beyond Skew", passcode: mustCode(t, now.Add(-2*PERIOD)), wantOk: false},
+		{name: "Failed Wrong Code", passcode: "123456", wantOk: false},
+		{name: "Failed 8 Digit Code", passcode: "14050471", wantOk: false},
+		{name: "Failed Empty Code", passcode: "", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Validate(rfcSecret, tt.passcode, now)
+			if ok != tt.wantOk {
+				t.Errorf("Validate() ok = %v, want %v", ok, tt.wantOk)
+				return
+			}
+			if ok && got != tt.want {
+				t.Errorf("Validate() step = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := encoding.DecodeString(secret)
+	if err != nil || len(key) != 20 {
+		t.Errorf("GenerateSecret() = %s, want 20 bytes base32 encoded", secret)
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("user login", "jane@example.com", rfcSecret)
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" || !strings.HasPrefix(u.Path, "/user login:jane@example.com") {
+		t.Errorf("URI() = %s, want the otpauth totp label issuer:account", uri)
+	}
+
+	query := u.Query()
+	for key, want := range map[string]string{"secret": rfcSecret, "issuer": "user login", "algorithm": "SHA1", "digits": "6", "period": "30"} {
+		if got := query.Get(key); got != want {
+			t.Errorf("URI() %s = %s, want %s", key, got, want)
+		}
+	}
+}
+
+func mustCode(t *testing.T, at time.Time) string {
+	code, err := Code(rfcSecret, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return code
+}
@@ -20,7 +20,15 @@ func init() {
 }
 
 func ValidateStruct(data interface{}) error {
-	err := validator.Struct(data)
+	return validationError(validator.Struct(data))
+}
+
+// ValidateStructPartial validate only the fields of data given by their struct field name
+func ValidateStructPartial(data interface{}, fields ...string) error {
+	return validationError(validator.StructPartial(data, fields...))
+}
+
+func validationError(err error) error {
 	if err != nil {
 		var errMsg string
 		if _, ok := err.(*validator_lib.InvalidValidationError); ok {